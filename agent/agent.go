@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 
+	"lineNews/agent/logutil"
 	"lineNews/agent/tool"
 	"lineNews/agent/workflow"
 	"lineNews/config"
@@ -15,11 +17,16 @@ import (
 
 // NewsTimelineAgent 新闻时间链 Agent
 type NewsTimelineAgent struct {
-	chatModel        *deepseek.ChatModel
-	apiKey           string
-	timelineWorkflow *workflow.TimelineWorkflow
-	graphWorkflow    *workflow.GraphWorkflow
-	modeWorkflow     *workflow.ModeWorkflow
+	chatModel         *deepseek.ChatModel
+	apiKey            string
+	baseURL           string
+	timelineWorkflow  *workflow.TimelineWorkflow
+	graphWorkflow     *workflow.GraphWorkflow
+	modeWorkflow      *workflow.ModeWorkflow
+	translateWorkflow *workflow.TranslateWorkflow
+
+	mu             sync.Mutex
+	graphWorkflows map[string]*workflow.GraphWorkflow // 按模型名称缓存的图谱工作流，用于实验变体
 }
 
 // NewNewsTimelineAgent 创建新闻时间链 Agent
@@ -52,11 +59,13 @@ func NewNewsTimelineAgent(ctx context.Context, cfg *config.Config) (*NewsTimelin
 	modeWorkflow := workflow.NewModeWorkflow(llmCaller, arkModelID)
 
 	return &NewsTimelineAgent{
-		chatModel:        chatModel,
-		apiKey:           cfg.DeepSeekAPIKey,
-		timelineWorkflow: timelineWorkflow,
-		graphWorkflow:    graphWorkflow,
-		modeWorkflow:     modeWorkflow,
+		chatModel:         chatModel,
+		apiKey:            cfg.DeepSeekAPIKey,
+		baseURL:           cfg.DeepSeekBaseURL,
+		timelineWorkflow:  timelineWorkflow,
+		graphWorkflow:     graphWorkflow,
+		modeWorkflow:      modeWorkflow,
+		translateWorkflow: workflow.NewTranslateWorkflow(llmCaller),
 	}, nil
 }
 
@@ -69,8 +78,37 @@ func (a *NewsTimelineAgent) GenerateTimeline(ctx context.Context, keyword string
 
 	// 将workflow包的类型转换为agent包的类型
 	return &TimelineResponse{
-		Keyword: result.Keyword,
-		Events:  convertEvents(result.Events),
+		Keyword:       result.Keyword,
+		Lang:          result.Lang,
+		PromptVersion: result.PromptVersion,
+		Events:        convertEvents(result.Events),
+	}, nil
+}
+
+// GenerateTimelineWithOptions 按约束生成新闻时间链
+// 约束写入生成和反思提示词，生成后再强制过滤时间窗口之外和属于排除子话题的事件，并并入置顶事件
+func (a *NewsTimelineAgent) GenerateTimelineWithOptions(ctx context.Context, keyword string, opts *GenerateOptions) (*TimelineResponse, error) {
+	if opts == nil {
+		return a.GenerateTimeline(ctx, keyword)
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	result, err := a.timelineWorkflow.GenerateWithOptions(ctx, keyword, opts.workflowOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	events, dropped := opts.Filter(convertEvents(result.Events))
+	if dropped > 0 {
+		logutil.LogInfo("按生成约束过滤 %d 个事件，保留 %d 个", dropped, len(events))
+	}
+	return &TimelineResponse{
+		Keyword:       result.Keyword,
+		Lang:          result.Lang,
+		PromptVersion: result.PromptVersion,
+		Events:        MergePinned(events, opts.Pinned),
 	}, nil
 }
 
@@ -167,27 +205,84 @@ func (a *NewsTimelineAgent) generateBalancedMode(ctx context.Context, keyword st
 	}, nil
 }
 
+// ErrDocumentsTooLong 文档切分后的片段数超过上限
+var ErrDocumentsTooLong = workflow.ErrTooManyDocumentChunks
+
+// GenerateTimelineFromDocuments 从用户提供的文档生成时间链，keyword 为空时由模型根据文档推断，lang 为输出语言
+// 多个片段抽取出的相同事件会合并，并合并其来源文档
+func (a *NewsTimelineAgent) GenerateTimelineFromDocuments(ctx context.Context, keyword, lang string, docs []*tool.Document) (*TimelineResponse, error) {
+	result, err := a.timelineWorkflow.GenerateFromDocuments(ctx, keyword, lang, docs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TimelineResponse{
+		Keyword:       result.Keyword,
+		Lang:          result.Lang,
+		PromptVersion: result.PromptVersion,
+		Events:        MergeEvents(convertEvents(result.Events)),
+	}, nil
+}
+
 // GenerateGraph 生成知识图谱
 func (a *NewsTimelineAgent) GenerateGraph(ctx context.Context, timeline *TimelineResponse) (*GraphResponse, error) {
+	return a.GenerateGraphWithModel(ctx, timeline, "")
+}
+
+// GenerateGraphWithModel 使用指定的 DeepSeek 模型生成知识图谱，modelName 为空时使用默认模型
+func (a *NewsTimelineAgent) GenerateGraphWithModel(ctx context.Context, timeline *TimelineResponse, modelName string) (*GraphResponse, error) {
+	graphWorkflow, err := a.graphWorkflowFor(ctx, modelName)
+	if err != nil {
+		return nil, err
+	}
+
 	// 将agent包的类型转换为workflow包的类型
 	workflowTimeline := &workflow.TimelineResponse{
 		Keyword: timeline.Keyword,
+		Lang:    timeline.Lang,
 		Events:  convertToWorkflowEvents(timeline.Events),
 	}
 
-	result, err := a.graphWorkflow.Generate(ctx, workflowTimeline)
+	result, err := graphWorkflow.Generate(ctx, workflowTimeline)
 	if err != nil {
 		return nil, err
 	}
 
 	// 将workflow包的类型转换为agent包的类型
 	return &GraphResponse{
-		Keyword: result.Keyword,
-		Nodes:   convertNodes(result.Nodes),
-		Links:   convertLinks(result.Links),
+		Keyword:       result.Keyword,
+		PromptVersion: result.PromptVersion,
+		Nodes:         convertNodes(result.Nodes),
+		Links:         convertLinks(result.Links),
 	}, nil
 }
 
+// graphWorkflowFor 返回使用指定模型的图谱工作流，模型对应的 ChatModel 创建后缓存复用
+func (a *NewsTimelineAgent) graphWorkflowFor(ctx context.Context, modelName string) (*workflow.GraphWorkflow, error) {
+	if modelName == "" {
+		return a.graphWorkflow, nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if w, ok := a.graphWorkflows[modelName]; ok {
+		return w, nil
+	}
+	chatModel, err := model.CreateDSChatModel(ctx, &model.DSModelConfig{
+		APIKey:  a.apiKey,
+		Model:   modelName,
+		BaseURL: a.baseURL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建模型 %s 失败: %w", modelName, err)
+	}
+	if a.graphWorkflows == nil {
+		a.graphWorkflows = make(map[string]*workflow.GraphWorkflow)
+	}
+	w := workflow.NewGraphWorkflow(tool.NewLLMCaller(chatModel))
+	a.graphWorkflows[modelName] = w
+	return w, nil
+}
+
 // convertEvents 将workflow.Event转换为agent.Event
 func convertEvents(events []workflow.Event) []Event {
 	result := make([]Event, len(events))
@@ -200,6 +295,9 @@ func convertEvents(events []workflow.Event) []Event {
 			People:   e.People,
 			Summary:  e.Summary,
 		}
+		for _, s := range e.Sources {
+			result[i].Sources = append(result[i].Sources, EventSource(s))
+		}
 	}
 	return result
 }
@@ -216,6 +314,9 @@ func convertToWorkflowEvents(events []Event) []workflow.Event {
 			People:   e.People,
 			Summary:  e.Summary,
 		}
+		for _, s := range e.Sources {
+			result[i].Sources = append(result[i].Sources, workflow.EventSource(s))
+		}
 	}
 	return result
 }
@@ -225,9 +326,15 @@ func convertNodes(nodes []workflow.GraphNode) []GraphNode {
 	result := make([]GraphNode, len(nodes))
 	for i, n := range nodes {
 		result[i] = GraphNode{
-			ID:       n.ID,
-			Name:     n.Name,
-			Category: n.Category,
+			ID:          n.ID,
+			Name:        n.Name,
+			Category:    n.Category,
+			Description: n.Description,
+			Aliases:     n.Aliases,
+			Attributes:  n.Attributes,
+			FirstSeen:   n.FirstSeen,
+			LastSeen:    n.LastSeen,
+			Importance:  n.Importance,
 		}
 	}
 	return result
@@ -241,6 +348,10 @@ func convertLinks(links []workflow.GraphLink) []GraphLink {
 			Source:   l.Source,
 			Target:   l.Target,
 			Relation: l.Relation,
+			Weight:   l.Weight,
+			Time:     l.Time,
+			Directed: l.Directed,
+			EventIDs: l.EventIDs,
 		}
 	}
 	return result
@@ -0,0 +1,373 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"lineNews/agent"
+)
+
+// maxLabelPropagationRounds 标签传播社区发现的最大迭代轮数
+const maxLabelPropagationRounds = 100
+
+// NodeScore 节点得分
+type NodeScore struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Category string  `json:"category"`
+	Score    float64 `json:"score"`
+}
+
+// Community 社区
+type Community struct {
+	ID    int      `json:"id"`
+	Size  int      `json:"size"`
+	Nodes []string `json:"nodes"` // 社区内节点ID
+	Names []string `json:"names"` // 社区内节点名称
+}
+
+// PathResult 最短路径查询结果
+type PathResult struct {
+	Source string            `json:"source"`
+	Target string            `json:"target"`
+	Found  bool              `json:"found"`
+	Length int               `json:"length"` // 路径经过的边数
+	Nodes  []agent.GraphNode `json:"nodes"`
+	Links  []agent.GraphLink `json:"links"`
+}
+
+// edge 邻接表中的边，link 为原始连接下标
+type edge struct {
+	to   int
+	link int
+}
+
+// Graph 基于 GraphResponse 构建的无向图，用于图分析
+type Graph struct {
+	nodes []agent.GraphNode
+	links []agent.GraphLink
+	index map[string]int
+	adj   [][]edge
+}
+
+// NewGraph 根据知识图谱构建分析用的图，指向不存在节点的连接会被忽略
+func NewGraph(graph *agent.GraphResponse) *Graph {
+	g := &Graph{index: make(map[string]int)}
+	if graph == nil {
+		return g
+	}
+
+	for _, node := range graph.Nodes {
+		if _, exists := g.index[node.ID]; exists {
+			continue
+		}
+		g.index[node.ID] = len(g.nodes)
+		g.nodes = append(g.nodes, node)
+	}
+	g.adj = make([][]edge, len(g.nodes))
+
+	for _, link := range graph.Links {
+		from, ok1 := g.index[link.Source]
+		to, ok2 := g.index[link.Target]
+		if !ok1 || !ok2 || from == to {
+			continue
+		}
+		linkIndex := len(g.links)
+		g.links = append(g.links, link)
+		g.adj[from] = append(g.adj[from], edge{to: to, link: linkIndex})
+		g.adj[to] = append(g.adj[to], edge{to: from, link: linkIndex})
+	}
+	return g
+}
+
+// NodeCount 节点数
+func (g *Graph) NodeCount() int {
+	return len(g.nodes)
+}
+
+// LinkCount 有效连接数
+func (g *Graph) LinkCount() int {
+	return len(g.links)
+}
+
+// DegreeCentrality 计算度中心性（按 n-1 归一化），按得分降序返回
+func (g *Graph) DegreeCentrality() []NodeScore {
+	n := len(g.nodes)
+	scores := make([]float64, n)
+	for i := range g.nodes {
+		scores[i] = float64(len(g.neighbors(i)))
+		if n > 1 {
+			scores[i] /= float64(n - 1)
+		}
+	}
+	return g.rank(scores)
+}
+
+// neighbors 返回节点 i 的不同邻居，多重边只保留一次
+func (g *Graph) neighbors(i int) []int {
+	seen := make(map[int]bool, len(g.adj[i]))
+	result := make([]int, 0, len(g.adj[i]))
+	for _, e := range g.adj[i] {
+		if !seen[e.to] {
+			seen[e.to] = true
+			result = append(result, e.to)
+		}
+	}
+	return result
+}
+
+// BetweennessCentrality 使用 Brandes 算法计算介数中心性（无权、无向、归一化），按得分降序返回
+func (g *Graph) BetweennessCentrality() []NodeScore {
+	n := len(g.nodes)
+	betweenness := make([]float64, n)
+	// 两个节点之间的多条关系只算一条边，避免重复的关系被计为多条最短路径
+	adj := make([][]int, n)
+	for i := range adj {
+		adj[i] = g.neighbors(i)
+	}
+
+	for s := 0; s < n; s++ {
+		stack := make([]int, 0, n)
+		predecessors := make([][]int, n)
+		sigma := make([]float64, n)
+		dist := make([]int, n)
+		for i := range dist {
+			dist[i] = -1
+		}
+		sigma[s] = 1
+		dist[s] = 0
+
+		queue := []int{s}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+			for _, w := range adj[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					predecessors[w] = append(predecessors[w], v)
+				}
+			}
+		}
+
+		delta := make([]float64, n)
+		for i := len(stack) - 1; i >= 0; i-- {
+			w := stack[i]
+			for _, v := range predecessors[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				betweenness[w] += delta[w]
+			}
+		}
+	}
+
+	// 无向图每条最短路径被统计两次，再按 (n-1)(n-2) 归一化
+	for i := range betweenness {
+		betweenness[i] /= 2
+		if n > 2 {
+			betweenness[i] /= float64(n-1) * float64(n-2) / 2
+		}
+	}
+	return g.rank(betweenness)
+}
+
+// Communities 使用标签传播算法进行社区发现，按社区规模降序返回
+func (g *Graph) Communities() []Community {
+	n := len(g.nodes)
+	labels := make([]int, n)
+	for i := range labels {
+		labels[i] = i
+	}
+
+	for round := 0; round < maxLabelPropagationRounds; round++ {
+		changed := false
+		for i := 0; i < n; i++ {
+			if len(g.adj[i]) == 0 {
+				continue
+			}
+			weights := make(map[int]float64)
+			for _, e := range g.adj[i] {
+				weights[labels[e.to]] += linkWeight(g.links[e.link])
+			}
+
+			// 选择权重最大的标签，权重相同时取较小的标签以保证结果稳定
+			best, bestWeight := labels[i], -1.0
+			for label, weight := range weights {
+				if weight > bestWeight || (weight == bestWeight && label < best) {
+					best, bestWeight = label, weight
+				}
+			}
+			if best != labels[i] {
+				labels[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i, label := range labels {
+		groups[label] = append(groups[label], i)
+	}
+	communities := make([]Community, 0, len(groups))
+	for _, members := range groups {
+		community := Community{Size: len(members)}
+		for _, i := range members {
+			community.Nodes = append(community.Nodes, g.nodes[i].ID)
+			community.Names = append(community.Names, g.nodes[i].Name)
+		}
+		communities = append(communities, community)
+	}
+	sort.SliceStable(communities, func(i, j int) bool {
+		if communities[i].Size != communities[j].Size {
+			return communities[i].Size > communities[j].Size
+		}
+		return communities[i].Nodes[0] < communities[j].Nodes[0]
+	})
+	for i := range communities {
+		communities[i].ID = i + 1
+	}
+	return communities
+}
+
+// Neighborhood 返回以指定节点为中心、k 跳范围内的子图
+func (g *Graph) Neighborhood(id string, hops int) (*agent.GraphResponse, error) {
+	center, ok := g.index[id]
+	if !ok {
+		return nil, fmt.Errorf("节点不存在: %s", id)
+	}
+	if hops < 1 {
+		hops = 1
+	}
+
+	dist := map[int]int{center: 0}
+	queue := []int{center}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		if dist[v] >= hops {
+			continue
+		}
+		for _, e := range g.adj[v] {
+			if _, seen := dist[e.to]; !seen {
+				dist[e.to] = dist[v] + 1
+				queue = append(queue, e.to)
+			}
+		}
+	}
+
+	subgraph := &agent.GraphResponse{}
+	for i, node := range g.nodes {
+		if _, ok := dist[i]; ok {
+			subgraph.Nodes = append(subgraph.Nodes, node)
+		}
+	}
+	for _, link := range g.links {
+		_, ok1 := dist[g.index[link.Source]]
+		_, ok2 := dist[g.index[link.Target]]
+		if ok1 && ok2 {
+			subgraph.Links = append(subgraph.Links, link)
+		}
+	}
+	return subgraph, nil
+}
+
+// ShortestPath 使用广度优先搜索查询两个节点之间的最短路径
+func (g *Graph) ShortestPath(source, target string) (*PathResult, error) {
+	from, ok := g.index[source]
+	if !ok {
+		return nil, fmt.Errorf("起点节点不存在: %s", source)
+	}
+	to, ok := g.index[target]
+	if !ok {
+		return nil, fmt.Errorf("终点节点不存在: %s", target)
+	}
+
+	result := &PathResult{Source: source, Target: target}
+	if from == to {
+		result.Found = true
+		result.Nodes = []agent.GraphNode{g.nodes[from]}
+		return result, nil
+	}
+
+	// prev 记录到达每个节点的前驱节点和所经过的边
+	prev := make(map[int]edge, len(g.nodes))
+	prev[from] = edge{to: -1, link: -1}
+	queue := []int{from}
+	for len(queue) > 0 && !result.Found {
+		v := queue[0]
+		queue = queue[1:]
+		for _, e := range g.adj[v] {
+			if _, seen := prev[e.to]; seen {
+				continue
+			}
+			prev[e.to] = edge{to: v, link: e.link}
+			if e.to == to {
+				result.Found = true
+				break
+			}
+			queue = append(queue, e.to)
+		}
+	}
+	if !result.Found {
+		return result, nil
+	}
+
+	// 从终点回溯路径
+	path := []int{to}
+	var links []int
+	for v := to; v != from; {
+		p := prev[v]
+		links = append(links, p.link)
+		path = append(path, p.to)
+		v = p.to
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		result.Nodes = append(result.Nodes, g.nodes[path[i]])
+	}
+	for i := len(links) - 1; i >= 0; i-- {
+		result.Links = append(result.Links, g.links[links[i]])
+	}
+	result.Length = len(result.Links)
+	return result, nil
+}
+
+// rank 将得分与节点对应并按得分降序排序
+func (g *Graph) rank(scores []float64) []NodeScore {
+	result := make([]NodeScore, len(g.nodes))
+	for i, node := range g.nodes {
+		result[i] = NodeScore{
+			ID:       node.ID,
+			Name:     node.Name,
+			Category: node.Category,
+			Score:    scores[i],
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+	return result
+}
+
+// linkWeight 返回连接权重，未设置时默认为 1
+func linkWeight(link agent.GraphLink) float64 {
+	if link.Weight > 0 {
+		return link.Weight
+	}
+	return 1
+}
+
+// TopScores 返回前 n 个得分，n<=0 时返回全部
+func TopScores(scores []NodeScore, n int) []NodeScore {
+	if n <= 0 || n >= len(scores) {
+		return scores
+	}
+	return scores[:n]
+}
@@ -0,0 +1,52 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"lineNews/agent"
+)
+
+// diamond 菱形图 s-x-t、s-y-t，links 中可追加重复的关系
+func diamond(extra ...agent.GraphLink) *agent.GraphResponse {
+	return &agent.GraphResponse{
+		Nodes: []agent.GraphNode{{ID: "s"}, {ID: "x"}, {ID: "y"}, {ID: "t"}},
+		Links: append([]agent.GraphLink{
+			{Source: "s", Target: "x", Relation: "合作"},
+			{Source: "s", Target: "y"},
+			{Source: "x", Target: "t"},
+			{Source: "y", Target: "t"},
+		}, extra...),
+	}
+}
+
+func TestCentrality(t *testing.T) {
+	duplicate := agent.GraphLink{Source: "x", Target: "s", Relation: "投资"}
+	tests := []struct {
+		name  string
+		graph *agent.GraphResponse
+		fn    func(g *Graph) []NodeScore
+		want  map[string]float64
+	}{
+		{"介数中心性", diamond(), (*Graph).BetweennessCentrality, map[string]float64{"s": 1.0 / 6, "x": 1.0 / 6, "y": 1.0 / 6, "t": 1.0 / 6}},
+		{"介数中心性不重复计算多重边", diamond(duplicate), (*Graph).BetweennessCentrality, map[string]float64{"s": 1.0 / 6, "x": 1.0 / 6, "y": 1.0 / 6, "t": 1.0 / 6}},
+		{"度中心性不重复计算多重边", diamond(duplicate), (*Graph).DegreeCentrality, map[string]float64{"s": 2.0 / 3, "x": 2.0 / 3, "y": 2.0 / 3, "t": 2.0 / 3}},
+		{"星形图的中心节点", &agent.GraphResponse{
+			Nodes: []agent.GraphNode{{ID: "c"}, {ID: "a"}, {ID: "b"}},
+			Links: []agent.GraphLink{{Source: "c", Target: "a"}, {Source: "c", Target: "b"}, {Source: "a", Target: "c"}},
+		}, (*Graph).BetweennessCentrality, map[string]float64{"c": 1, "a": 0, "b": 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scores := tt.fn(NewGraph(tt.graph))
+			if len(scores) != len(tt.want) {
+				t.Fatalf("got %d scores; want %d", len(scores), len(tt.want))
+			}
+			for _, score := range scores {
+				if want := tt.want[score.ID]; math.Abs(score.Score-want) > 1e-9 {
+					t.Errorf("%s = %v; want %v", score.ID, score.Score, want)
+				}
+			}
+		})
+	}
+}
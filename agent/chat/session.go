@@ -0,0 +1,204 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"lineNews/model"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// 对话服务提供方
+const (
+	ProviderArk      = "ark"
+	ProviderDeepSeek = "deepseek"
+)
+
+// 默认配置
+const (
+	DefaultSystemPrompt     = "你是一个有用的AI助手，请回答用户的问题。"
+	DefaultMaxContextTokens = 8000
+)
+
+// Message 对话消息
+type Message struct {
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	Tokens    int       `json:"tokens"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Session 对话会话，Messages 保存完整历史，发送给模型时按 token 预算截取最近的轮次
+type Session struct {
+	ID               string    `json:"id"`
+	Provider         string    `json:"provider"`
+	SystemPrompt     string    `json:"system_prompt"`
+	MaxContextTokens int       `json:"max_context_tokens"`
+	Messages         []Message `json:"messages"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Reply 模型回复
+type Reply struct {
+	Content          string `json:"content"`
+	Provider         string `json:"provider"`
+	ContextMessages  int    `json:"context_messages"`
+	TrimmedMessages  int    `json:"trimmed_messages"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	TotalTokens      int    `json:"total_tokens"`
+}
+
+// NewSession 创建对话会话，provider 为空时默认使用 Ark
+func NewSession(id, provider, systemPrompt string, maxContextTokens int) (*Session, error) {
+	if provider == "" {
+		provider = ProviderArk
+	}
+	if provider != ProviderArk && provider != ProviderDeepSeek {
+		return nil, fmt.Errorf("不支持的对话服务: %s", provider)
+	}
+	if systemPrompt == "" {
+		systemPrompt = DefaultSystemPrompt
+	}
+	if maxContextTokens <= 0 {
+		maxContextTokens = DefaultMaxContextTokens
+	}
+
+	now := time.Now()
+	return &Session{
+		ID:               id,
+		Provider:         provider,
+		SystemPrompt:     systemPrompt,
+		MaxContextTokens: maxContextTokens,
+		Messages:         []Message{},
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}, nil
+}
+
+// NewMessage 创建对话消息并估算 token 数
+func NewMessage(role, content string) Message {
+	return Message{
+		Role:      role,
+		Content:   content,
+		Tokens:    EstimateTokens(content),
+		CreatedAt: time.Now(),
+	}
+}
+
+// EstimateTokens 粗略估算 token 数：非 ASCII 字符按 1 个计算，ASCII 字符每 4 个计 1 个
+func EstimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return other + (ascii+3)/4
+}
+
+// ContextMessages 返回本轮发送给模型的历史消息（不含系统提示词和本轮用户消息）以及被截掉的历史条数
+// 从最近的消息向前累加，超出预算后丢弃更早的轮次；保留的第一条消息始终是用户消息
+func (s *Session) ContextMessages(userMessage string) ([]Message, int) {
+	budget := s.MaxContextTokens - EstimateTokens(s.SystemPrompt) - EstimateTokens(userMessage)
+
+	start := len(s.Messages)
+	for i := len(s.Messages) - 1; i >= 0; i-- {
+		tokens := s.Messages[i].Tokens
+		if tokens == 0 {
+			tokens = EstimateTokens(s.Messages[i].Content)
+		}
+		if budget-tokens < 0 {
+			break
+		}
+		budget -= tokens
+		start = i
+	}
+	for start < len(s.Messages) && s.Messages[start].Role != string(schema.User) {
+		start++
+	}
+	return s.Messages[start:], start
+}
+
+// Send 携带会话历史发送用户消息，不修改会话本身；Ark 会话可以联网搜索
+func Send(ctx context.Context, session *Session, userMessage string) (*Reply, error) {
+	return send(ctx, session, userMessage, model.WebSearchTools)
+}
+
+// send 携带会话历史发送用户消息，tools 为 Ark 模型可用的工具
+func send(ctx context.Context, session *Session, userMessage string, tools []model.Tool) (*Reply, error) {
+	history, trimmed := session.ContextMessages(userMessage)
+
+	var (
+		content                               string
+		promptTokens, completionTokens, total int
+	)
+	switch session.Provider {
+	case ProviderDeepSeek:
+		chatModel, err := model.SharedDSChatModel()
+		if err != nil {
+			return nil, err
+		}
+		messages := []*schema.Message{schema.SystemMessage(session.SystemPrompt)}
+		for _, msg := range history {
+			messages = append(messages, &schema.Message{Role: schema.RoleType(msg.Role), Content: msg.Content})
+		}
+		messages = append(messages, schema.UserMessage(userMessage))
+
+		response, err := model.SendMessageWithHistory(ctx, chatModel, messages)
+		if err != nil {
+			return nil, err
+		}
+		content, promptTokens, completionTokens, total = response.Content, response.PromptTokens, response.CompletionTokens, response.TotalTokens
+	default:
+		input := []model.ArkInput{arkInput(string(schema.System), session.SystemPrompt)}
+		for _, msg := range history {
+			input = append(input, arkInput(msg.Role, msg.Content))
+		}
+		input = append(input, arkInput(string(schema.User), userMessage))
+
+		response, err := model.SendArkMessageWithHistory(ctx, model.ArkFlashModel, input, tools)
+		if err != nil {
+			return nil, err
+		}
+		content, promptTokens, completionTokens, total = response.Content, response.PromptTokens, response.CompletionTokens, response.TotalTokens
+	}
+
+	return &Reply{
+		Content:          content,
+		Provider:         session.Provider,
+		ContextMessages:  len(history),
+		TrimmedMessages:  trimmed,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      total,
+	}, nil
+}
+
+// Complete 不带历史的单轮调用，供问答等场景复用会话的模型选择逻辑
+// 不开启联网搜索，模型只能根据提示词中给出的资料回答
+func Complete(ctx context.Context, provider, systemPrompt, userMessage string) (*Reply, error) {
+	session, err := NewSession("", provider, systemPrompt, 0)
+	if err != nil {
+		return nil, err
+	}
+	return send(ctx, session, userMessage, nil)
+}
+
+// arkInput 构建 Ark 输入项，助手消息使用 output_text 类型
+func arkInput(role, text string) model.ArkInput {
+	contentType := "input_text"
+	if role == string(schema.Assistant) {
+		contentType = "output_text"
+	}
+	return model.ArkInput{
+		Role:    role,
+		Content: []model.ArkInputContent{{Type: contentType, Text: text}},
+	}
+}
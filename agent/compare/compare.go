@@ -0,0 +1,301 @@
+package compare
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"lineNews/agent"
+	"lineNews/agent/entity"
+)
+
+// 时间轴粒度
+const (
+	GranularityAuto  = "auto"
+	GranularityYear  = "year"
+	GranularityMonth = "month"
+	GranularityDay   = "day"
+)
+
+// Topic 参与对比的专题
+type Topic struct {
+	RecordID string
+	Keyword  string
+	Timeline *agent.TimelineResponse
+	Graph    *agent.GraphResponse // 可选，用于按百科词条解析实体
+}
+
+// TopicInfo 对比结果中的专题说明
+type TopicInfo struct {
+	RecordID   string `json:"record_id,omitempty"`
+	Keyword    string `json:"keyword"`
+	EventCount int    `json:"event_count"`
+}
+
+// AlignedEvent 对齐到时间轴上的事件
+type AlignedEvent struct {
+	Topic          int         `json:"topic"` // 所属专题在 Topics 中的下标
+	Keyword        string      `json:"keyword"`
+	Event          agent.Event `json:"event"`
+	Start          string      `json:"start,omitempty"` // 归一化后的起始日期 YYYY-MM-DD
+	End            string      `json:"end,omitempty"`   // 归一化后的结束日期 YYYY-MM-DD
+	SharedEntities []string    `json:"shared_entities,omitempty"`
+}
+
+// Period 时间轴上的一个时段
+type Period struct {
+	Label  string         `json:"label"` // 如 2023、2023-05、2023-05-20
+	Start  string         `json:"start"`
+	End    string         `json:"end"`
+	Shared bool           `json:"shared"` // 是否有两个及以上专题在该时段有事件
+	Counts []int          `json:"counts"` // 各专题在该时段的事件数
+	Events []AlignedEvent `json:"events"` // 按时间交错排列的事件
+}
+
+// SharedEntity 在多个专题中出现的实体
+type SharedEntity struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Topics []int    `json:"topics"`
+	Counts []int    `json:"counts"` // 各专题中涉及该实体的事件数
+	Names  []string `json:"names,omitempty"`
+}
+
+// Comparison 多个专题在同一时间轴上的对比
+type Comparison struct {
+	Topics         []TopicInfo    `json:"topics"`
+	Granularity    string         `json:"granularity"`
+	Periods        []Period       `json:"periods"`
+	SharedEntities []SharedEntity `json:"shared_entities"`
+	Undated        []AlignedEvent `json:"undated"` // 无法解析时间的事件
+}
+
+// Compare 将多个专题的事件按归一化日期对齐到同一时间轴
+// 事件按起始日期归入 granularity 对应的时段（auto 时按总跨度选择），同一时段内不同专题的事件按时间交错排列；
+// 人物和地点在两个及以上专题中出现的实体为共同实体，涉及共同实体的事件在 SharedEntities 中标出
+func Compare(topics []Topic, granularity string) (*Comparison, error) {
+	if err := ValidateGranularity(granularity); err != nil {
+		return nil, err
+	}
+
+	graphs := make([]*agent.GraphResponse, 0, len(topics))
+	for _, topic := range topics {
+		graphs = append(graphs, topic.Graph)
+	}
+	resolver := entity.NewResolver(graphs...)
+
+	result := &Comparison{Topics: make([]TopicInfo, len(topics)), Periods: []Period{}, SharedEntities: []SharedEntity{}, Undated: []AlignedEvent{}}
+	var dated []AlignedEvent
+	var times []agent.EventTime
+	mentions := make([][]map[string]string, len(topics)) // 每个事件涉及的实体 ID → 名称
+	for i, topic := range topics {
+		result.Topics[i] = TopicInfo{RecordID: topic.RecordID, Keyword: topic.Keyword}
+		if topic.Timeline == nil {
+			continue
+		}
+		result.Topics[i].EventCount = len(topic.Timeline.Events)
+		mentions[i] = make([]map[string]string, len(topic.Timeline.Events))
+		for j, event := range topic.Timeline.Events {
+			mentions[i][j] = eventEntities(resolver, event)
+		}
+	}
+
+	shared := sharedEntities(topics, mentions)
+	result.SharedEntities = shared
+	sharedIDs := make(map[string]string, len(shared))
+	for _, e := range shared {
+		sharedIDs[e.ID] = e.Name
+	}
+
+	for i, topic := range topics {
+		if topic.Timeline == nil {
+			continue
+		}
+		for j, event := range topic.Timeline.Events {
+			aligned := AlignedEvent{Topic: i, Keyword: topic.Keyword, Event: event}
+			for id := range mentions[i][j] {
+				if name, ok := sharedIDs[id]; ok {
+					aligned.SharedEntities = append(aligned.SharedEntities, name)
+				}
+			}
+			sort.Strings(aligned.SharedEntities)
+			t := agent.ParseEventTime(event.Time)
+			if !t.Valid {
+				result.Undated = append(result.Undated, aligned)
+				continue
+			}
+			aligned.Start, aligned.End = formatDate(t.Start), formatDate(t.End)
+			dated = append(dated, aligned)
+			times = append(times, t)
+		}
+	}
+
+	if granularity == "" || granularity == GranularityAuto {
+		granularity = autoGranularity(times)
+	}
+	result.Granularity = granularity
+
+	order := make([]int, len(dated))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ta, tb := times[order[a]], times[order[b]]
+		if !ta.Start.Equal(tb.Start) {
+			return ta.Start.Before(tb.Start)
+		}
+		return dated[order[a]].Topic < dated[order[b]].Topic
+	})
+
+	index := make(map[string]int)
+	for _, i := range order {
+		start, end, label := periodOf(times[i].Start, granularity)
+		p, ok := index[label]
+		if !ok {
+			p = len(result.Periods)
+			index[label] = p
+			result.Periods = append(result.Periods, Period{Label: label, Start: formatDate(start), End: formatDate(end), Counts: make([]int, len(topics))})
+		}
+		period := &result.Periods[p]
+		period.Events = append(period.Events, dated[i])
+		period.Counts[dated[i].Topic]++
+	}
+	for i := range result.Periods {
+		active := 0
+		for _, count := range result.Periods[i].Counts {
+			if count > 0 {
+				active++
+			}
+		}
+		result.Periods[i].Shared = active >= 2
+	}
+	return result, nil
+}
+
+// ValidateGranularity 校验时段粒度，空字符串等同于 auto
+func ValidateGranularity(granularity string) error {
+	switch granularity {
+	case "", GranularityAuto, GranularityYear, GranularityMonth, GranularityDay:
+		return nil
+	default:
+		return fmt.Errorf("不支持的粒度: %s", granularity)
+	}
+}
+
+// eventEntities 解析事件人物和地点对应的实体
+func eventEntities(resolver *entity.Resolver, event agent.Event) map[string]string {
+	entities := make(map[string]string)
+	for _, name := range append(append([]string(nil), event.People...), event.Location) {
+		name = strings.TrimSpace(name)
+		if id := resolver.Name(name); id != "" {
+			if _, ok := entities[id]; !ok {
+				entities[id] = name
+			}
+		}
+	}
+	return entities
+}
+
+// sharedEntities 统计在两个及以上专题中出现的实体，按涉及的事件总数从多到少排序
+func sharedEntities(topics []Topic, mentions [][]map[string]string) []SharedEntity {
+	byID := make(map[string]*SharedEntity)
+	for i := range topics {
+		for _, entities := range mentions[i] {
+			for id, name := range entities {
+				e, ok := byID[id]
+				if !ok {
+					e = &SharedEntity{ID: id, Name: name, Counts: make([]int, len(topics))}
+					byID[id] = e
+				}
+				if name != e.Name && !contains(e.Names, name) {
+					e.Names = append(e.Names, name)
+				}
+				e.Counts[i]++
+			}
+		}
+	}
+
+	shared := []SharedEntity{}
+	for _, e := range byID {
+		for i, count := range e.Counts {
+			if count > 0 {
+				e.Topics = append(e.Topics, i)
+			}
+		}
+		if len(e.Topics) >= 2 {
+			shared = append(shared, *e)
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		ti, tj := total(shared[i].Counts), total(shared[j].Counts)
+		if ti != tj {
+			return ti > tj
+		}
+		return shared[i].Name < shared[j].Name
+	})
+	return shared
+}
+
+// autoGranularity 根据所有事件的时间跨度选择粒度：超过 5 年按年，超过 6 个月按月，否则按日
+func autoGranularity(times []agent.EventTime) string {
+	if len(times) == 0 {
+		return GranularityYear
+	}
+	first, last := times[0].Start, times[0].Start
+	for _, t := range times[1:] {
+		if t.Start.Before(first) {
+			first = t.Start
+		}
+		if t.Start.After(last) {
+			last = t.Start
+		}
+	}
+	switch {
+	case last.After(first.AddDate(5, 0, 0)):
+		return GranularityYear
+	case last.After(first.AddDate(0, 6, 0)):
+		return GranularityMonth
+	default:
+		return GranularityDay
+	}
+}
+
+// periodOf 返回日期所在时段的起止日期和标签
+func periodOf(t time.Time, granularity string) (start, end time.Time, label string) {
+	switch granularity {
+	case GranularityYear:
+		start = time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1), start.Format("2006")
+	case GranularityMonth:
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1), start.Format("2006-01")
+	default:
+		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return start, start, start.Format("2006-01-02")
+	}
+}
+
+// formatDate 格式化为 YYYY-MM-DD
+func formatDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// contains 判断字符串切片是否包含指定值
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// total 求和
+func total(counts []int) int {
+	sum := 0
+	for _, c := range counts {
+		sum += c
+	}
+	return sum
+}
@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"strconv"
+	"strings"
+
+	"lineNews/agent/workflow"
+)
+
+// GenerateOptions 重新生成时间链时的约束条件
+type GenerateOptions struct {
+	MustInclude []string `json:"must_include,omitempty"` // 必须包含的事件描述，只写入提示词
+	Pinned      []Event  `json:"-"`                      // 置顶事件，写入提示词并原样保留在结果中
+	Exclude     []string `json:"exclude,omitempty"`      // 排除的子话题，标题或摘要包含这些词的事件会被过滤
+	From        string   `json:"from,omitempty"`         // 时间窗口起点，如 2020、2020-03、2020-03-01
+	To          string   `json:"to,omitempty"`           // 时间窗口终点，按精度取该年、月或日的最后一天
+	Language    string   `json:"language,omitempty"`     // 输出语言，如 zh、en
+}
+
+// Validate 校验时间窗口
+func (o *GenerateOptions) Validate() error {
+	_, _, err := parseTimeWindow(o.From, o.To)
+	return err
+}
+
+// Filter 过滤时间窗口之外和属于排除子话题的事件，返回保留的事件和过滤的数量
+// 设置了时间窗口时，无法解析时间的事件也会被过滤
+func (o *GenerateOptions) Filter(events []Event) ([]Event, int) {
+	from, to, _ := parseTimeWindow(o.From, o.To)
+	kept := make([]Event, 0, len(events))
+	for _, event := range events {
+		if (!from.IsZero() || !to.IsZero()) && !inWindow(ParseEventTime(event.Time), from, to) {
+			continue
+		}
+		if o.excluded(event) {
+			continue
+		}
+		kept = append(kept, event)
+	}
+	return kept, len(events) - len(kept)
+}
+
+// excluded 判断事件标题或摘要是否包含排除的子话题，不区分大小写
+func (o *GenerateOptions) excluded(event Event) bool {
+	text := strings.ToLower(event.Title + " " + event.Summary)
+	for _, topic := range o.Exclude {
+		if topic = strings.ToLower(strings.TrimSpace(topic)); topic != "" && strings.Contains(text, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// workflowOptions 转换为工作流的生成约束，置顶事件以 "时间 标题" 的形式列入必须包含的事件
+func (o *GenerateOptions) workflowOptions() *workflow.GenerateOptions {
+	mustInclude := make([]string, 0, len(o.Pinned)+len(o.MustInclude))
+	for _, event := range o.Pinned {
+		mustInclude = append(mustInclude, strings.TrimSpace(event.Time+" "+event.Title))
+	}
+	mustInclude = append(mustInclude, o.MustInclude...)
+	return &workflow.GenerateOptions{
+		MustInclude: mustInclude,
+		Exclude:     o.Exclude,
+		From:        o.From,
+		To:          o.To,
+		Language:    o.Language,
+	}
+}
+
+// MergePinned 将置顶事件原样并入生成结果，生成结果中与置顶事件同名的事件被置顶事件替换，结果按时间排序
+func MergePinned(events, pinned []Event) []Event {
+	if len(pinned) == 0 {
+		return events
+	}
+	keys := make(map[string]bool, len(pinned))
+	for _, event := range pinned {
+		keys[NormalizeKey(event.Title)] = true
+	}
+	merged := make([]Event, 0, len(events)+len(pinned))
+	for _, event := range events {
+		if !keys[NormalizeKey(event.Title)] {
+			merged = append(merged, event)
+		}
+	}
+	merged = append(merged, pinned...)
+	SortEventsByTime(merged)
+	return merged
+}
+
+// ReplaceEvents 用重新生成的事件替换记录中的时间链：被人工删除过的事件不再加回，
+// 与原有事件同名的事件沿用原 ID，其余事件分配新的递增 ID，使版本间可按 ID 比较；返回去除的已删除事件数量
+func (r *TimelineRecord) ReplaceEvents(events []Event) int {
+	events, removed := r.FilterDeletedEvents(events)
+	var previous []Event
+	if r.Timeline != nil {
+		previous = r.Timeline.Events
+	}
+	byTitle := make(map[string]string, len(previous))
+	for _, event := range previous {
+		byTitle[NormalizeKey(event.Title)] = event.ID
+	}
+
+	taken := make(map[string]bool, len(events))
+	var fresh []int
+	for i, event := range events {
+		if id, ok := byTitle[NormalizeKey(event.Title)]; ok && !taken[id] {
+			events[i].ID = id
+			taken[id] = true
+			continue
+		}
+		fresh = append(fresh, i)
+	}
+	next := NextEventID(previous)
+	for _, i := range fresh {
+		for taken[strconv.Itoa(next)] {
+			next++
+		}
+		events[i].ID = strconv.Itoa(next)
+		taken[events[i].ID] = true
+	}
+
+	timeline := &TimelineResponse{Keyword: r.Keyword, Events: events}
+	if r.Timeline != nil {
+		timeline.Keyword = r.Timeline.Keyword
+		timeline.Lang = r.Timeline.Lang
+		timeline.PromptVersion = r.Timeline.PromptVersion
+		timeline.Experiment = r.Timeline.Experiment
+	}
+	r.SetTimeline(timeline)
+	return removed
+}
@@ -0,0 +1,486 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 编辑对象类型
+const (
+	EditKindEvent = "event"
+	EditKindNode  = "node"
+	EditKindLink  = "link"
+)
+
+// 编辑操作
+const (
+	EditCreate = "create"
+	EditUpdate = "update"
+	EditDelete = "delete"
+)
+
+// ErrItemNotFound 要编辑的事件、节点或关系不存在
+var ErrItemNotFound = errors.New("编辑对象不存在")
+
+// Edit 一次人工编辑
+type Edit struct {
+	Kind     string          `json:"kind"`   // event、node 或 link
+	Action   string          `json:"action"` // create、update 或 delete
+	TargetID string          `json:"target_id"`
+	Author   string          `json:"author"`
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+	Version  int             `json:"version"` // 编辑后生成的版本号
+	At       time.Time       `json:"at"`
+}
+
+// Tombstone 人工删除的条目，Key 为事件标题、节点名称或关系两端与类型的归一化结果
+type Tombstone struct {
+	Kind   string    `json:"kind"`
+	ID     string    `json:"id"`
+	Key    string    `json:"key"`
+	Author string    `json:"author"`
+	At     time.Time `json:"at"`
+}
+
+// EventPatch 事件的部分修改，只修改非空字段
+type EventPatch struct {
+	Title    *string        `json:"title"`
+	Time     *string        `json:"time"`
+	Location *string        `json:"location"`
+	People   *[]string      `json:"people"`
+	Summary  *string        `json:"summary"`
+	Sources  *[]EventSource `json:"sources"`
+}
+
+// Apply 将修改写入事件
+func (p *EventPatch) Apply(e *Event) {
+	setIfPresent(&e.Title, p.Title)
+	setIfPresent(&e.Time, p.Time)
+	setIfPresent(&e.Location, p.Location)
+	setIfPresent(&e.People, p.People)
+	setIfPresent(&e.Summary, p.Summary)
+	setIfPresent(&e.Sources, p.Sources)
+}
+
+// NodePatch 图谱节点的部分修改，只修改非空字段
+type NodePatch struct {
+	Name        *string            `json:"name"`
+	Category    *string            `json:"category"`
+	Description *string            `json:"description"`
+	Aliases     *[]string          `json:"aliases"`
+	Attributes  *map[string]string `json:"attributes"`
+	FirstSeen   *string            `json:"first_seen"`
+	LastSeen    *string            `json:"last_seen"`
+	Importance  *float64           `json:"importance"`
+}
+
+// Apply 将修改写入节点
+func (p *NodePatch) Apply(n *GraphNode) {
+	setIfPresent(&n.Name, p.Name)
+	setIfPresent(&n.Category, p.Category)
+	setIfPresent(&n.Description, p.Description)
+	setIfPresent(&n.Aliases, p.Aliases)
+	setIfPresent(&n.Attributes, p.Attributes)
+	setIfPresent(&n.FirstSeen, p.FirstSeen)
+	setIfPresent(&n.LastSeen, p.LastSeen)
+	setIfPresent(&n.Importance, p.Importance)
+}
+
+// LinkPatch 图谱关系的部分修改，只修改非空字段
+type LinkPatch struct {
+	Source   *string   `json:"source"`
+	Target   *string   `json:"target"`
+	Relation *string   `json:"relation"`
+	Weight   *float64  `json:"weight"`
+	Time     *string   `json:"time"`
+	Directed *bool     `json:"directed"`
+	EventIDs *[]string `json:"event_ids"`
+}
+
+// Apply 将修改写入关系
+func (p *LinkPatch) Apply(l *GraphLink) {
+	setIfPresent(&l.Source, p.Source)
+	setIfPresent(&l.Target, p.Target)
+	setIfPresent(&l.Relation, p.Relation)
+	setIfPresent(&l.Weight, p.Weight)
+	setIfPresent(&l.Time, p.Time)
+	setIfPresent(&l.Directed, p.Directed)
+	setIfPresent(&l.EventIDs, p.EventIDs)
+}
+
+// setIfPresent value 非空时写入 field
+func setIfPresent[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+// NormalizeKey 归一化标题或名称：转小写并去除空白、标点和符号
+func NormalizeKey(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, value)
+}
+
+// linkKey 关系的归一化标识
+func linkKey(l GraphLink) string {
+	return l.Source + "|" + l.Target + "|" + NormalizeKey(l.Relation)
+}
+
+// AddEvent 人工添加事件，分配新 ID 后按时间排序
+func (r *TimelineRecord) AddEvent(event Event, author string) (*Event, error) {
+	if strings.TrimSpace(event.Title) == "" || strings.TrimSpace(event.Time) == "" {
+		return nil, fmt.Errorf("事件的 title 和 time 不能为空")
+	}
+	if r.Timeline == nil {
+		r.Timeline = &TimelineResponse{ID: r.ID, Keyword: r.Keyword}
+	}
+	event.ID = strconv.Itoa(NextEventID(r.Timeline.Events))
+	event.Edited = true
+	r.Timeline.Events = append(r.Timeline.Events, event)
+	SortEventsByTime(r.Timeline.Events)
+	r.removeTombstone(EditKindEvent, NormalizeKey(event.Title))
+	r.recordEdit(EditKindEvent, EditCreate, event.ID, author, nil, event)
+	r.SetTimeline(r.Timeline)
+	added, _ := r.Timeline.FindEvent(event.ID)
+	return added, nil
+}
+
+// UpdateEvent 人工修改事件
+func (r *TimelineRecord) UpdateEvent(id string, patch EventPatch, author string) (*Event, error) {
+	if r.Timeline == nil {
+		return nil, ErrItemNotFound
+	}
+	event, ok := r.Timeline.FindEvent(id)
+	if !ok {
+		return nil, ErrItemNotFound
+	}
+	before := *event
+	patch.Apply(event)
+	if strings.TrimSpace(event.Title) == "" || strings.TrimSpace(event.Time) == "" {
+		*event = before
+		return nil, fmt.Errorf("事件的 title 和 time 不能为空")
+	}
+	event.Edited = true
+	after := *event
+	SortEventsByTime(r.Timeline.Events)
+	r.recordEdit(EditKindEvent, EditUpdate, id, author, before, after)
+	r.SetTimeline(r.Timeline)
+	updated, _ := r.Timeline.FindEvent(id)
+	return updated, nil
+}
+
+// DeleteEvent 人工删除事件，并记录墓碑防止之后被重新加回
+func (r *TimelineRecord) DeleteEvent(id, author string) error {
+	if r.Timeline == nil {
+		return ErrItemNotFound
+	}
+	for i, event := range r.Timeline.Events {
+		if event.ID != id {
+			continue
+		}
+		r.Timeline.Events = append(r.Timeline.Events[:i], r.Timeline.Events[i+1:]...)
+		r.addTombstone(EditKindEvent, id, NormalizeKey(event.Title), author)
+		r.recordEdit(EditKindEvent, EditDelete, id, author, event, nil)
+		r.SetTimeline(r.Timeline)
+		return nil
+	}
+	return ErrItemNotFound
+}
+
+// AddNode 人工添加图谱节点，未指定 ID 时自动分配
+func (r *TimelineRecord) AddNode(node GraphNode, author string) (*GraphNode, error) {
+	if strings.TrimSpace(node.Name) == "" {
+		return nil, fmt.Errorf("节点的 name 不能为空")
+	}
+	if r.Graph == nil {
+		r.Graph = &GraphResponse{ID: r.ID, Keyword: r.Keyword, Nodes: []GraphNode{}, Links: []GraphLink{}}
+	}
+	if node.ID == "" {
+		node.ID = nextID("n", len(r.Graph.Nodes), func(id string) bool { return r.Graph.findNode(id) >= 0 })
+	} else if r.Graph.findNode(node.ID) >= 0 {
+		return nil, fmt.Errorf("节点 ID 已存在: %s", node.ID)
+	}
+	node.Edited = true
+	r.Graph.Nodes = append(r.Graph.Nodes, node)
+	r.removeTombstone(EditKindNode, NormalizeKey(node.Name))
+	r.recordEdit(EditKindNode, EditCreate, node.ID, author, nil, node)
+	r.SetGraph(r.Graph)
+	return &r.Graph.Nodes[len(r.Graph.Nodes)-1], nil
+}
+
+// UpdateNode 人工修改图谱节点
+func (r *TimelineRecord) UpdateNode(id string, patch NodePatch, author string) (*GraphNode, error) {
+	if r.Graph == nil || r.Graph.findNode(id) < 0 {
+		return nil, ErrItemNotFound
+	}
+	node := &r.Graph.Nodes[r.Graph.findNode(id)]
+	before := *node
+	patch.Apply(node)
+	if strings.TrimSpace(node.Name) == "" {
+		*node = before
+		return nil, fmt.Errorf("节点的 name 不能为空")
+	}
+	// 改名后保留原名称作为别名，重新生成图谱时仍能对应到该节点
+	if NormalizeKey(node.Name) != NormalizeKey(before.Name) {
+		node.Aliases = appendUnique(node.Aliases, before.Name)
+	}
+	node.Edited = true
+	r.recordEdit(EditKindNode, EditUpdate, id, author, before, *node)
+	r.SetGraph(r.Graph)
+	return node, nil
+}
+
+// DeleteNode 人工删除图谱节点及与其相连的关系，并记录墓碑防止重新生成图谱时加回
+func (r *TimelineRecord) DeleteNode(id, author string) error {
+	if r.Graph == nil {
+		return ErrItemNotFound
+	}
+	i := r.Graph.findNode(id)
+	if i < 0 {
+		return ErrItemNotFound
+	}
+	node := r.Graph.Nodes[i]
+	r.Graph.Nodes = append(r.Graph.Nodes[:i], r.Graph.Nodes[i+1:]...)
+	links := r.Graph.Links[:0]
+	for _, link := range r.Graph.Links {
+		if link.Source != id && link.Target != id {
+			links = append(links, link)
+		}
+	}
+	r.Graph.Links = links
+	r.addTombstone(EditKindNode, id, NormalizeKey(node.Name), author)
+	r.recordEdit(EditKindNode, EditDelete, id, author, node, nil)
+	r.SetGraph(r.Graph)
+	return nil
+}
+
+// AddLink 人工添加图谱关系，两端节点必须存在
+func (r *TimelineRecord) AddLink(link GraphLink, author string) (*GraphLink, error) {
+	if r.Graph == nil {
+		return nil, ErrItemNotFound
+	}
+	if err := r.Graph.validateLink(link); err != nil {
+		return nil, err
+	}
+	EnsureLinkIDs(r.Graph)
+	link.ID = nextID("l", len(r.Graph.Links), func(id string) bool { return r.Graph.findLink(id) >= 0 })
+	link.Edited = true
+	r.Graph.Links = append(r.Graph.Links, link)
+	r.removeTombstone(EditKindLink, linkKey(link))
+	r.recordEdit(EditKindLink, EditCreate, link.ID, author, nil, link)
+	r.SetGraph(r.Graph)
+	return &r.Graph.Links[len(r.Graph.Links)-1], nil
+}
+
+// UpdateLink 人工修改图谱关系
+func (r *TimelineRecord) UpdateLink(id string, patch LinkPatch, author string) (*GraphLink, error) {
+	if r.Graph == nil {
+		return nil, ErrItemNotFound
+	}
+	EnsureLinkIDs(r.Graph)
+	i := r.Graph.findLink(id)
+	if i < 0 {
+		return nil, ErrItemNotFound
+	}
+	link := &r.Graph.Links[i]
+	before := *link
+	patch.Apply(link)
+	if err := r.Graph.validateLink(*link); err != nil {
+		*link = before
+		return nil, err
+	}
+	link.Edited = true
+	r.recordEdit(EditKindLink, EditUpdate, id, author, before, *link)
+	r.SetGraph(r.Graph)
+	return link, nil
+}
+
+// DeleteLink 人工删除图谱关系，并记录墓碑防止重新生成图谱时加回
+func (r *TimelineRecord) DeleteLink(id, author string) error {
+	if r.Graph == nil {
+		return ErrItemNotFound
+	}
+	EnsureLinkIDs(r.Graph)
+	i := r.Graph.findLink(id)
+	if i < 0 {
+		return ErrItemNotFound
+	}
+	link := r.Graph.Links[i]
+	r.Graph.Links = append(r.Graph.Links[:i], r.Graph.Links[i+1:]...)
+	r.addTombstone(EditKindLink, id, linkKey(link), author)
+	r.recordEdit(EditKindLink, EditDelete, id, author, link, nil)
+	r.SetGraph(r.Graph)
+	return nil
+}
+
+// FilterDeletedEvents 去除被人工删除过的事件，返回保留的事件和去除的数量
+func (r *TimelineRecord) FilterDeletedEvents(events []Event) ([]Event, int) {
+	kept := make([]Event, 0, len(events))
+	for _, event := range events {
+		if !r.hasTombstone(EditKindEvent, NormalizeKey(event.Title)) {
+			kept = append(kept, event)
+		}
+	}
+	return kept, len(events) - len(kept)
+}
+
+// PreserveGraphEdits 在替换图谱前保留人工编辑：编辑过的节点和关系以旧图谱为准，被删除的节点和关系不再加回
+// 调用前应先通过 StabilizeNodeIDs 对齐节点 ID
+func (r *TimelineRecord) PreserveGraphEdits(graph *GraphResponse) {
+	nodes := graph.Nodes[:0]
+	for _, node := range graph.Nodes {
+		if !r.hasTombstone(EditKindNode, NormalizeKey(node.Name)) {
+			nodes = append(nodes, node)
+		}
+	}
+	graph.Nodes = nodes
+
+	if r.Graph != nil {
+		for _, node := range r.Graph.Nodes {
+			if !node.Edited {
+				continue
+			}
+			if i := graph.findNode(node.ID); i >= 0 {
+				graph.Nodes[i] = node
+			} else {
+				graph.Nodes = append(graph.Nodes, node)
+			}
+		}
+	}
+
+	links := graph.Links[:0]
+	for _, link := range graph.Links {
+		if graph.findNode(link.Source) >= 0 && graph.findNode(link.Target) >= 0 && !r.hasTombstone(EditKindLink, linkKey(link)) {
+			links = append(links, link)
+		}
+	}
+	graph.Links = links
+
+	if r.Graph != nil {
+		for _, link := range r.Graph.Links {
+			if !link.Edited || graph.findNode(link.Source) < 0 || graph.findNode(link.Target) < 0 {
+				continue
+			}
+			replaced := false
+			for i := range graph.Links {
+				if linkKey(graph.Links[i]) == linkKey(link) {
+					graph.Links[i] = link
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				graph.Links = append(graph.Links, link)
+			}
+		}
+	}
+	EnsureLinkIDs(graph)
+}
+
+// EnsureLinkIDs 为没有 ID 的关系分配 ID
+func EnsureLinkIDs(graph *GraphResponse) {
+	for i := range graph.Links {
+		if graph.Links[i].ID == "" {
+			graph.Links[i].ID = nextID("l", i, func(id string) bool { return graph.findLink(id) >= 0 })
+		}
+	}
+}
+
+// findNode 返回节点下标，不存在时返回 -1
+func (g *GraphResponse) findNode(id string) int {
+	for i := range g.Nodes {
+		if g.Nodes[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// findLink 返回关系下标，不存在时返回 -1
+func (g *GraphResponse) findLink(id string) int {
+	for i := range g.Links {
+		if g.Links[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateLink 校验关系两端节点存在且关系类型不为空
+func (g *GraphResponse) validateLink(link GraphLink) error {
+	if strings.TrimSpace(link.Relation) == "" {
+		return fmt.Errorf("关系的 relation 不能为空")
+	}
+	if g.findNode(link.Source) < 0 || g.findNode(link.Target) < 0 {
+		return fmt.Errorf("关系两端的节点不存在: %s -> %s", link.Source, link.Target)
+	}
+	return nil
+}
+
+// nextID 从 prefix+(n+1) 开始寻找未被使用的 ID
+func nextID(prefix string, n int, exists func(id string) bool) string {
+	for i := n + 1; ; i++ {
+		id := prefix + strconv.Itoa(i)
+		if !exists(id) {
+			return id
+		}
+	}
+}
+
+// recordEdit 追加编辑记录，版本号为本次修改保存后的版本
+func (r *TimelineRecord) recordEdit(kind, action, targetID, author string, before, after any) {
+	edit := Edit{
+		Kind:     kind,
+		Action:   action,
+		TargetID: targetID,
+		Author:   author,
+		Version:  r.Version + 1,
+		At:       time.Now(),
+	}
+	if before != nil {
+		edit.Before, _ = json.Marshal(before)
+	}
+	if after != nil {
+		edit.After, _ = json.Marshal(after)
+	}
+	r.Edits = append(r.Edits, edit)
+}
+
+// addTombstone 记录被删除的条目
+func (r *TimelineRecord) addTombstone(kind, id, key, author string) {
+	if r.hasTombstone(kind, key) {
+		return
+	}
+	r.Tombstones = append(r.Tombstones, Tombstone{Kind: kind, ID: id, Key: key, Author: author, At: time.Now()})
+}
+
+// removeTombstone 人工重新添加被删除的条目时移除墓碑
+func (r *TimelineRecord) removeTombstone(kind, key string) {
+	tombstones := r.Tombstones[:0]
+	for _, t := range r.Tombstones {
+		if t.Kind != kind || t.Key != key {
+			tombstones = append(tombstones, t)
+		}
+	}
+	r.Tombstones = tombstones
+}
+
+// hasTombstone 判断条目是否被人工删除过
+func (r *TimelineRecord) hasTombstone(kind, key string) bool {
+	for _, t := range r.Tombstones {
+		if t.Kind == kind && t.Key == key {
+			return true
+		}
+	}
+	return false
+}
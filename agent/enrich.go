@@ -0,0 +1,215 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"lineNews/agent/logutil"
+	"lineNews/model"
+)
+
+// BaikeRelationCategory 由百科关系补充出的节点类别
+const BaikeRelationCategory = "百科关联"
+
+// DefaultBaikeCategories 默认需要查询百科的节点类别关键字
+var DefaultBaikeCategories = []string{"人物", "地点", "主题"}
+
+// BaikeLookupFunc 百科查询函数，便于替换数据源
+type BaikeLookupFunc func(keyword string) (*model.BaiduBaikeResponse, error)
+
+// BaikeEnrichOptions 百科增强选项
+type BaikeEnrichOptions struct {
+	Categories    []string        // 需要查询百科的节点类别关键字，类别包含任一关键字即查询
+	WithRelations bool            // 是否将百科关系补充为图谱的边
+	MaxRelations  int             // 每个节点最多补充的关系数，<=0 表示不限制
+	Concurrency   int             // 并发查询数
+	Lookup        BaikeLookupFunc // 百科查询函数，默认使用 model.BaiduBaikeSearchSimple
+}
+
+// NewBaikeEnrichOptions 创建默认百科增强选项
+func NewBaikeEnrichOptions() *BaikeEnrichOptions {
+	return &BaikeEnrichOptions{
+		Categories:   DefaultBaikeCategories,
+		MaxRelations: 5,
+		Concurrency:  4,
+		Lookup:       model.BaiduBaikeSearchSimple,
+	}
+}
+
+// EnrichGraphWithBaike 使用百度百科补充图谱节点的描述、图片和链接，返回成功补充的节点数
+func EnrichGraphWithBaike(ctx context.Context, graph *GraphResponse, opts *BaikeEnrichOptions) (int, error) {
+	if graph == nil {
+		return 0, fmt.Errorf("知识图谱为空")
+	}
+	if opts == nil {
+		opts = NewBaikeEnrichOptions()
+	}
+	if opts.Lookup == nil {
+		opts.Lookup = model.BaiduBaikeSearchSimple
+	}
+	if len(opts.Categories) == 0 {
+		opts.Categories = DefaultBaikeCategories
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	// 并发查询百科，结果按节点下标存放
+	results := make([]*model.BaikeResult, len(graph.Nodes))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, node := range graph.Nodes {
+		if node.Baike != nil || !matchCategory(node.Category, opts.Categories) {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			response, err := opts.Lookup(name)
+			if err != nil {
+				logutil.LogError("百科查询失败: %s, %v", name, err)
+				return
+			}
+			if response == nil || response.Result == nil || response.Result.LemmaTitle == "" {
+				logutil.LogInfo("百科未找到词条: %s", name)
+				return
+			}
+			results[i] = response.Result
+		}(i, node.Name)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	enriched := 0
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		graph.Nodes[i].Baike = &BaikeInfo{
+			LemmaID:    result.LemmaId,
+			LemmaTitle: result.LemmaTitle,
+			LemmaDesc:  result.LemmaDesc,
+			Summary:    result.Summary,
+			PicURL:     result.PicURL,
+			URL:        result.URL,
+		}
+		if graph.Nodes[i].Description == "" {
+			graph.Nodes[i].Description = result.LemmaDesc
+		}
+		enriched++
+	}
+
+	if opts.WithRelations {
+		added := addBaikeRelations(graph, results, opts.MaxRelations)
+		logutil.LogInfo("百科关系补充完成，新增 %d 条边", added)
+	}
+
+	logutil.LogInfo("百科增强完成，共补充 %d 个节点", enriched)
+	return enriched, nil
+}
+
+// addBaikeRelations 将百科关系补充为图谱的边，返回新增边数
+func addBaikeRelations(graph *GraphResponse, results []*model.BaikeResult, maxRelations int) int {
+	nodeByName := make(map[string]string, len(graph.Nodes))
+	nodeIDs := make(map[string]bool, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		nodeByName[node.Name] = node.ID
+		nodeIDs[node.ID] = true
+	}
+	linkKeys := make(map[string]bool, len(graph.Links))
+	for _, link := range graph.Links {
+		linkKeys[link.Source+"|"+link.Target+"|"+link.Relation] = true
+	}
+
+	added := 0
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		sourceID := graph.Nodes[i].ID
+		count := 0
+		for j, relation := range result.Relations {
+			if maxRelations > 0 && count >= maxRelations {
+				break
+			}
+			if relation.LemmaTitle == "" || relation.RelationName == "" {
+				continue
+			}
+
+			// 关联词条已在图谱中则直接连边，否则新建百科关联节点
+			targetID, exists := nodeByName[relation.LemmaTitle]
+			if !exists {
+				targetID = baikeRelationNodeID(relation.LemmaId, sourceID, j, nodeIDs)
+				graph.Nodes = append(graph.Nodes, GraphNode{
+					ID:       targetID,
+					Name:     relation.LemmaTitle,
+					Category: BaikeRelationCategory,
+					Baike: &BaikeInfo{
+						LemmaID:    relation.LemmaId,
+						LemmaTitle: relation.LemmaTitle,
+						PicURL:     relation.SquarePicURL,
+					},
+				})
+				nodeByName[relation.LemmaTitle] = targetID
+				nodeIDs[targetID] = true
+			}
+			if targetID == sourceID {
+				continue
+			}
+
+			key := sourceID + "|" + targetID + "|" + relation.RelationName
+			if linkKeys[key] {
+				continue
+			}
+			linkKeys[key] = true
+			graph.Links = append(graph.Links, GraphLink{
+				Source:   sourceID,
+				Target:   targetID,
+				Relation: relation.RelationName,
+				Directed: true,
+			})
+			added++
+			count++
+		}
+	}
+	return added
+}
+
+// baikeRelationNodeID 为百科关联节点生成不重复的 ID
+// 有词条 ID 时使用 bk{词条ID}，没有时按来源节点和关系序号生成，与已有 ID 冲突时追加序号
+func baikeRelationNodeID(lemmaID int64, sourceID string, index int, nodeIDs map[string]bool) string {
+	base := fmt.Sprintf("bk%d", lemmaID)
+	if lemmaID == 0 {
+		base = fmt.Sprintf("bk_%s_%d", sourceID, index)
+	} else if nodeIDs[base] {
+		base = fmt.Sprintf("bk%d_%s", lemmaID, sourceID)
+	}
+	id := base
+	for n := 2; nodeIDs[id]; n++ {
+		id = fmt.Sprintf("%s_%d", base, n)
+	}
+	return id
+}
+
+// matchCategory 判断节点类别是否包含任一关键字
+func matchCategory(category string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if keyword != "" && strings.Contains(category, keyword) {
+			return true
+		}
+	}
+	return false
+}
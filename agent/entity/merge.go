@@ -0,0 +1,177 @@
+package entity
+
+import (
+	"strings"
+
+	"lineNews/agent"
+)
+
+// MergeSource 参与合并的一个专题图谱
+type MergeSource struct {
+	RecordID string
+	Keyword  string
+	Graph    *agent.GraphResponse
+}
+
+// MergeGraphs 将多个专题图谱合并为一个图谱，节点按 Resolver 的规则归并为实体，节点 ID 使用实体 ID
+// 同一实体的节点合并别名、属性和百科信息，描述取最长的一个，首次/最近出现时间取最早/最晚，重要度取最大值；
+// 两端和关系名称相同的关系合并为一条（无向关系不区分方向），合并后两端为同一实体的关系丢弃。
+// 每个节点和关系的 Topics 为其出现的专题关键词，Provenance 记录来源记录中的原始 ID 和支撑事件
+func MergeGraphs(sources []MergeSource) *agent.GraphResponse {
+	graphs := make([]*agent.GraphResponse, 0, len(sources))
+	keywords := make([]string, 0, len(sources))
+	for _, source := range sources {
+		graphs = append(graphs, source.Graph)
+		keywords = appendUnique(keywords, source.Keyword)
+	}
+	resolver := NewResolver(graphs...)
+
+	merged := &agent.GraphResponse{Keyword: strings.Join(keywords, " / "), Nodes: []agent.GraphNode{}, Links: []agent.GraphLink{}}
+	nodeIndex := make(map[string]int)
+	linkIndex := make(map[string]int)
+	for _, source := range sources {
+		if source.Graph == nil {
+			continue
+		}
+		provenance := agent.Provenance{RecordID: source.RecordID, Keyword: source.Keyword}
+
+		entityIDs := make(map[string]string, len(source.Graph.Nodes))
+		for _, node := range source.Graph.Nodes {
+			id := resolver.Node(node)
+			entityIDs[node.ID] = id
+			p := provenance
+			p.ID = node.ID
+			i, ok := nodeIndex[id]
+			if !ok {
+				nodeIndex[id] = len(merged.Nodes)
+				node.ID = id
+				node.Aliases = append([]string(nil), node.Aliases...)
+				node.Attributes = copyAttributes(node.Attributes)
+				node.Topics = []string{source.Keyword}
+				node.Provenance = []agent.Provenance{p}
+				merged.Nodes = append(merged.Nodes, node)
+				continue
+			}
+			mergeNode(&merged.Nodes[i], node)
+			merged.Nodes[i].Topics = appendUnique(merged.Nodes[i].Topics, source.Keyword)
+			merged.Nodes[i].Provenance = append(merged.Nodes[i].Provenance, p)
+		}
+
+		for _, link := range source.Graph.Links {
+			src, dst := entityIDs[link.Source], entityIDs[link.Target]
+			if src == "" || dst == "" || src == dst {
+				continue
+			}
+			p := provenance
+			p.ID = link.ID
+			p.EventIDs = link.EventIDs
+			key := mergedLinkKey(src, dst, link)
+			i, ok := linkIndex[key]
+			if !ok {
+				linkIndex[key] = len(merged.Links)
+				link.ID = ""
+				link.Source, link.Target = src, dst
+				link.EventIDs = nil
+				link.Topics = []string{source.Keyword}
+				link.Provenance = []agent.Provenance{p}
+				merged.Links = append(merged.Links, link)
+				continue
+			}
+			target := &merged.Links[i]
+			if link.Weight > target.Weight {
+				target.Weight = link.Weight
+			}
+			if target.Time == "" || earlier(link.Time, target.Time) {
+				target.Time = link.Time
+			}
+			target.Topics = appendUnique(target.Topics, source.Keyword)
+			target.Provenance = append(target.Provenance, p)
+		}
+	}
+	return merged
+}
+
+// mergeNode 将同一实体的另一个节点合并进 target
+func mergeNode(target *agent.GraphNode, node agent.GraphNode) {
+	if node.Name != target.Name {
+		target.Aliases = appendUnique(target.Aliases, node.Name)
+	}
+	for _, alias := range node.Aliases {
+		if alias != target.Name {
+			target.Aliases = appendUnique(target.Aliases, alias)
+		}
+	}
+	if target.Category == "" {
+		target.Category = node.Category
+	}
+	if len([]rune(node.Description)) > len([]rune(target.Description)) {
+		target.Description = node.Description
+	}
+	for k, v := range node.Attributes {
+		if _, ok := target.Attributes[k]; !ok {
+			if target.Attributes == nil {
+				target.Attributes = make(map[string]string)
+			}
+			target.Attributes[k] = v
+		}
+	}
+	if target.FirstSeen == "" || earlier(node.FirstSeen, target.FirstSeen) {
+		target.FirstSeen = node.FirstSeen
+	}
+	if target.LastSeen == "" || earlier(target.LastSeen, node.LastSeen) {
+		target.LastSeen = node.LastSeen
+	}
+	if node.Importance > target.Importance {
+		target.Importance = node.Importance
+	}
+	if target.Baike == nil {
+		target.Baike = node.Baike
+	}
+	target.Edited = target.Edited || node.Edited
+}
+
+// mergedLinkKey 合并关系的标识，无向关系的两端按字典序排列
+func mergedLinkKey(source, target string, link agent.GraphLink) string {
+	if !link.Directed && target < source {
+		source, target = target, source
+	}
+	return source + "|" + target + "|" + agent.NormalizeKey(link.Relation)
+}
+
+// earlier 判断时间 a 是否早于 b，无法解析的时间不早于任何时间
+func earlier(a, b string) bool {
+	ta, tb := agent.ParseEventTime(a), agent.ParseEventTime(b)
+	return ta.Valid && (!tb.Valid || ta.Start.Before(tb.Start))
+}
+
+// copyAttributes 复制属性，避免修改来源图谱
+func copyAttributes(attributes map[string]string) map[string]string {
+	if attributes == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(attributes))
+	for k, v := range attributes {
+		copied[k] = v
+	}
+	return copied
+}
+
+// appendUnique 追加不重复的非空字符串
+func appendUnique(values []string, items ...string) []string {
+	for _, item := range items {
+		if item == "" {
+			continue
+		}
+		found := false
+		for _, v := range values {
+			if v == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, item)
+		}
+	}
+	return values
+}
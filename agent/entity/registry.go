@@ -0,0 +1,343 @@
+package entity
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"lineNews/agent"
+)
+
+// CategoryPerson 仅出现在事件人物中的实体的类别
+const CategoryPerson = "人物"
+
+// Entity 跨专题的实体，合并所有专题记录中指向同一对象的图谱节点和事件人物
+type Entity struct {
+	ID        string           `json:"id"` // 有百科词条时为 "b" + LemmaID，否则为 "n" + 归一化名称的摘要
+	Name      string           `json:"name"`
+	Category  string           `json:"category,omitempty"`
+	Aliases   []string         `json:"aliases,omitempty"`
+	LemmaID   int64            `json:"lemma_id,omitempty"`
+	Baike     *agent.BaikeInfo `json:"baike,omitempty"`
+	Timelines []TimelineRef    `json:"timelines"`
+	Events    []EventRef       `json:"events"`
+	Relations []RelationRef    `json:"relations"`
+
+	timelineIndex map[string]int
+	eventIndex    map[string]bool
+}
+
+// TimelineRef 实体出现的专题记录
+type TimelineRef struct {
+	RecordID string   `json:"record_id"`
+	Keyword  string   `json:"keyword"`
+	NodeIDs  []string `json:"node_ids,omitempty"` // 该记录图谱中对应的节点
+}
+
+// EventRef 涉及实体的事件
+type EventRef struct {
+	RecordID string      `json:"record_id"`
+	Keyword  string      `json:"keyword"`
+	Event    agent.Event `json:"event"`
+}
+
+// RelationRef 涉及实体的图谱关系
+type RelationRef struct {
+	RecordID  string          `json:"record_id"`
+	Keyword   string          `json:"keyword"`
+	Direction string          `json:"direction"` // out 表示实体为关系起点，in 表示实体为关系终点
+	Other     string          `json:"other"`     // 关系另一端的实体 ID
+	OtherName string          `json:"other_name"`
+	Link      agent.GraphLink `json:"link"`
+}
+
+// Summary 实体列表中的摘要
+type Summary struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Category      string `json:"category,omitempty"`
+	LemmaID       int64  `json:"lemma_id,omitempty"`
+	TimelineCount int    `json:"timeline_count"`
+	EventCount    int    `json:"event_count"`
+	RelationCount int    `json:"relation_count"`
+}
+
+// Registry 全局实体注册表，由所有专题记录的图谱节点和事件人物构建
+// 记录变化时只标记为待重建，下次查询时在内存中重新解析
+type Registry struct {
+	mu       sync.Mutex
+	records  map[string]*agent.TimelineRecord
+	entities map[string]*Entity
+	byName   map[string][]string
+	dirty    bool
+}
+
+// NewRegistry 创建空的实体注册表
+func NewRegistry() *Registry {
+	return &Registry{records: make(map[string]*agent.TimelineRecord)}
+}
+
+// Put 写入或替换专题记录
+func (r *Registry) Put(record *agent.TimelineRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records[record.ID] = record
+	r.dirty = true
+}
+
+// Remove 删除专题记录
+func (r *Registry) Remove(recordID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.records, recordID)
+	r.dirty = true
+}
+
+// Get 按实体 ID 获取实体，找不到时按名称或别名解析，名称对应多个实体时返回 false
+func (r *Registry) Get(idOrName string) (*Entity, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rebuild()
+	if e, ok := r.entities[idOrName]; ok {
+		return e, true
+	}
+	if ids := r.byName[agent.NormalizeKey(idOrName)]; len(ids) == 1 {
+		return r.entities[ids[0]], true
+	}
+	return nil, false
+}
+
+// List 列出名称或别名包含 q、类别为 category 的实体（均为空时不过滤），按出现的专题数从多到少排序
+func (r *Registry) List(q, category string, limit int) []Summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rebuild()
+
+	key := agent.NormalizeKey(q)
+	summaries := []Summary{}
+	for _, e := range r.entities {
+		if category != "" && e.Category != category {
+			continue
+		}
+		if key != "" && !e.matches(key) {
+			continue
+		}
+		summaries = append(summaries, e.Summary())
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].TimelineCount != summaries[j].TimelineCount {
+			return summaries[i].TimelineCount > summaries[j].TimelineCount
+		}
+		return summaries[i].ID < summaries[j].ID
+	})
+	if limit > 0 && len(summaries) > limit {
+		summaries = summaries[:limit]
+	}
+	return summaries
+}
+
+// Summary 返回实体摘要
+func (e *Entity) Summary() Summary {
+	return Summary{
+		ID:            e.ID,
+		Name:          e.Name,
+		Category:      e.Category,
+		LemmaID:       e.LemmaID,
+		TimelineCount: len(e.Timelines),
+		EventCount:    len(e.Events),
+		RelationCount: len(e.Relations),
+	}
+}
+
+// matches 判断名称或别名归一化后是否包含 key
+func (e *Entity) matches(key string) bool {
+	for _, name := range append([]string{e.Name}, e.Aliases...) {
+		if strings.Contains(agent.NormalizeKey(name), key) {
+			return true
+		}
+	}
+	return false
+}
+
+// rebuild 在有记录变化时重新解析所有实体，调用方需持有锁
+// 先以百科词条 ID 建立实体，其余节点和事件人物按 Resolver 的规则归并
+func (r *Registry) rebuild() {
+	if !r.dirty && r.entities != nil {
+		return
+	}
+	recordIDs := make([]string, 0, len(r.records))
+	graphs := make([]*agent.GraphResponse, 0, len(r.records))
+	for id := range r.records {
+		recordIDs = append(recordIDs, id)
+	}
+	sort.Strings(recordIDs)
+	for _, id := range recordIDs {
+		graphs = append(graphs, r.records[id].Graph)
+	}
+
+	b := &builder{Resolver: NewResolver(graphs...), entities: make(map[string]*Entity)}
+	for id, node := range b.lemmas {
+		name := node.Baike.LemmaTitle
+		if name == "" {
+			name = node.Name
+		}
+		e := b.ensure(id, name, node.Category)
+		e.LemmaID = node.Baike.LemmaID
+		e.Baike = node.Baike
+	}
+	for _, id := range recordIDs {
+		b.addRecord(r.records[id])
+	}
+
+	r.byName = make(map[string][]string)
+	for _, e := range b.entities {
+		e.sortRefs()
+		seen := make(map[string]bool)
+		for _, name := range append([]string{e.Name}, e.Aliases...) {
+			if key := agent.NormalizeKey(name); key != "" && !seen[key] {
+				seen[key] = true
+				r.byName[key] = append(r.byName[key], e.ID)
+			}
+		}
+	}
+	r.entities = b.entities
+	r.dirty = false
+}
+
+// builder 一次重建过程中的中间状态
+type builder struct {
+	*Resolver
+	entities map[string]*Entity
+}
+
+// ensure 获取或创建实体
+func (b *builder) ensure(id, name, category string) *Entity {
+	e, ok := b.entities[id]
+	if !ok {
+		e = &Entity{
+			ID:            id,
+			Name:          name,
+			Category:      category,
+			Timelines:     []TimelineRef{},
+			Events:        []EventRef{},
+			Relations:     []RelationRef{},
+			timelineIndex: make(map[string]int),
+			eventIndex:    make(map[string]bool),
+		}
+		b.entities[id] = e
+	}
+	if e.Category == "" || e.Category == CategoryPerson && category != "" {
+		e.Category = category
+	}
+	e.addAlias(name)
+	return e
+}
+
+// addRecord 将一条专题记录中的节点、事件人物和关系归入实体
+func (b *builder) addRecord(record *agent.TimelineRecord) {
+	events := make(map[string]agent.Event)
+	if record.Timeline != nil {
+		for _, event := range record.Timeline.Events {
+			events[event.ID] = event
+			for _, person := range event.People {
+				id := b.Name(person)
+				if id == "" {
+					continue
+				}
+				e := b.ensure(id, strings.TrimSpace(person), CategoryPerson)
+				e.addTimeline(record, "")
+				e.addEvent(record, event)
+			}
+		}
+	}
+	if record.Graph == nil {
+		return
+	}
+
+	nodeEntities := make(map[string]*Entity, len(record.Graph.Nodes))
+	for _, node := range record.Graph.Nodes {
+		e := b.ensure(b.Node(node), node.Name, node.Category)
+		for _, alias := range node.Aliases {
+			e.addAlias(alias)
+		}
+		e.addTimeline(record, node.ID)
+		nodeEntities[node.ID] = e
+	}
+	// 译文中的节点名称作为别名，使不同语言的名称都能解析到同一实体
+	langs := make([]string, 0, len(record.Translations))
+	for lang := range record.Translations {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		if graph := record.Translations[lang].Graph; graph != nil {
+			for _, node := range graph.Nodes {
+				if e := nodeEntities[node.ID]; e != nil {
+					e.addAlias(node.Name)
+				}
+			}
+		}
+	}
+	for _, link := range record.Graph.Links {
+		source, target := nodeEntities[link.Source], nodeEntities[link.Target]
+		if source == nil || target == nil {
+			continue
+		}
+		source.Relations = append(source.Relations, RelationRef{RecordID: record.ID, Keyword: record.Keyword, Direction: "out", Other: target.ID, OtherName: target.Name, Link: link})
+		target.Relations = append(target.Relations, RelationRef{RecordID: record.ID, Keyword: record.Keyword, Direction: "in", Other: source.ID, OtherName: source.Name, Link: link})
+		for _, eventID := range link.EventIDs {
+			if event, ok := events[eventID]; ok {
+				source.addEvent(record, event)
+				target.addEvent(record, event)
+			}
+		}
+	}
+}
+
+// addAlias 追加与名称不同的别名
+func (e *Entity) addAlias(alias string) {
+	alias = strings.TrimSpace(alias)
+	if alias == "" || alias == e.Name {
+		return
+	}
+	for _, a := range e.Aliases {
+		if a == alias {
+			return
+		}
+	}
+	e.Aliases = append(e.Aliases, alias)
+}
+
+// addTimeline 登记实体出现的专题记录，nodeID 不为空时同时登记对应节点
+func (e *Entity) addTimeline(record *agent.TimelineRecord, nodeID string) {
+	i, ok := e.timelineIndex[record.ID]
+	if !ok {
+		i = len(e.Timelines)
+		e.timelineIndex[record.ID] = i
+		e.Timelines = append(e.Timelines, TimelineRef{RecordID: record.ID, Keyword: record.Keyword})
+	}
+	if nodeID != "" {
+		e.Timelines[i].NodeIDs = append(e.Timelines[i].NodeIDs, nodeID)
+	}
+}
+
+// addEvent 登记涉及实体的事件，同一记录中的同一事件只登记一次
+func (e *Entity) addEvent(record *agent.TimelineRecord, event agent.Event) {
+	key := record.ID + "/" + event.ID
+	if e.eventIndex[key] {
+		return
+	}
+	e.eventIndex[key] = true
+	e.Events = append(e.Events, EventRef{RecordID: record.ID, Keyword: record.Keyword, Event: event})
+}
+
+// sortRefs 事件按时间从早到晚排序，无法解析时间的排在最后
+func (e *Entity) sortRefs() {
+	sort.SliceStable(e.Events, func(i, j int) bool {
+		ti, tj := agent.ParseEventTime(e.Events[i].Event.Time), agent.ParseEventTime(e.Events[j].Event.Time)
+		if ti.Valid != tj.Valid {
+			return ti.Valid
+		}
+		return ti.Start.Before(tj.Start)
+	})
+}
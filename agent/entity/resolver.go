@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+
+	"lineNews/agent"
+)
+
+// Resolver 实体解析器：带百科词条的节点以词条 ID 为实体 ID，并登记其名称和别名；
+// 其余名称只对应一个词条时归入该词条，否则以归一化名称的摘要为实体 ID
+type Resolver struct {
+	lemmas     map[string]agent.GraphNode // 词条实体 ID → 首个带该词条的节点
+	lemmaNames map[string]map[string]bool // 归一化名称 → 使用该名称的词条实体 ID
+}
+
+// NewResolver 基于若干图谱中带百科词条的节点创建实体解析器
+func NewResolver(graphs ...*agent.GraphResponse) *Resolver {
+	r := &Resolver{lemmas: make(map[string]agent.GraphNode), lemmaNames: make(map[string]map[string]bool)}
+	for _, graph := range graphs {
+		if graph == nil {
+			continue
+		}
+		for _, node := range graph.Nodes {
+			if node.Baike != nil && node.Baike.LemmaID != 0 {
+				r.registerLemma(node)
+			}
+		}
+	}
+	return r
+}
+
+// registerLemma 登记带百科词条的节点的名称、别名和词条标题
+func (r *Resolver) registerLemma(node agent.GraphNode) {
+	id := lemmaEntityID(node.Baike.LemmaID)
+	if _, ok := r.lemmas[id]; !ok {
+		r.lemmas[id] = node
+	}
+	for _, alias := range append([]string{node.Name, node.Baike.LemmaTitle}, node.Aliases...) {
+		if key := agent.NormalizeKey(alias); key != "" {
+			if r.lemmaNames[key] == nil {
+				r.lemmaNames[key] = make(map[string]bool)
+			}
+			r.lemmaNames[key][id] = true
+		}
+	}
+}
+
+// Name 解析名称对应的实体 ID，名称为空时返回空字符串
+func (r *Resolver) Name(name string) string {
+	key := agent.NormalizeKey(name)
+	if key == "" {
+		return ""
+	}
+	if ids := r.lemmaNames[key]; len(ids) == 1 {
+		for id := range ids {
+			return id
+		}
+	}
+	sum := sha1.Sum([]byte(key))
+	return "n" + hex.EncodeToString(sum[:6])
+}
+
+// Node 解析图谱节点对应的实体 ID，依次使用百科词条、别名对应的词条和名称
+func (r *Resolver) Node(node agent.GraphNode) string {
+	if node.Baike != nil && node.Baike.LemmaID != 0 {
+		return lemmaEntityID(node.Baike.LemmaID)
+	}
+	for _, alias := range node.Aliases {
+		if id := r.Name(alias); r.isLemma(id) {
+			return id
+		}
+	}
+	return r.Name(node.Name)
+}
+
+// isLemma 判断实体 ID 是否对应百科词条
+func (r *Resolver) isLemma(id string) bool {
+	_, ok := r.lemmas[id]
+	return ok
+}
+
+// lemmaEntityID 百科词条对应的实体 ID
+func lemmaEntityID(lemmaID int64) string {
+	return fmt.Sprintf("b%d", lemmaID)
+}
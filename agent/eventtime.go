@@ -0,0 +1,222 @@
+package agent
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 事件时间精度
+const (
+	PrecisionYear  = "year"
+	PrecisionMonth = "month"
+	PrecisionDay   = "day"
+)
+
+var (
+	// eventTimeRangeSeparator 时间范围分隔符，如 "2023-01 至 2023-03"、"2020~2021"
+	eventTimeRangeSeparator = regexp.MustCompile(`\s*(?:至|到|~|～|—|–|\s-\s)\s*`)
+	// eventTimeDashedRange 以短横线连接的时间范围，如 "1956-1957"、"2023-01-2023-03"
+	eventTimeDashedRange = regexp.MustCompile(`^(\d{4}(?:-\d{1,2}){0,2})-(\d{4}(?:-\d{1,2}){0,2})$`)
+	// eventTimeDate 归一化后的日期，如 "2023"、"2023-1"、"2023-01-10"
+	eventTimeDate = regexp.MustCompile(`(\d{4})(?:-(\d{1,2}))?(?:-(\d{1,2}))?`)
+	// eventTimeNormalizer 将中文及其他日期分隔符统一为短横线
+	eventTimeNormalizer = strings.NewReplacer("年", "-", "月", "-", "日", "", "号", "", "/", "-", ".", "-")
+)
+
+// EventTime 解析后的事件时间，Start 和 End 均为闭区间的起止日期
+type EventTime struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Precision string    `json:"precision"`
+	Valid     bool      `json:"valid"`
+}
+
+// ParseEventTime 解析事件的 time 字段，支持 YYYY、YYYY-MM、YYYY-MM-DD、中文日期和时间范围
+func ParseEventTime(value string) EventTime {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return EventTime{}
+	}
+
+	// 先识别时间范围
+	if parts := eventTimeRangeSeparator.Split(value, 2); len(parts) == 2 {
+		return parseEventTimeRange(parts[0], parts[1])
+	}
+	normalized := normalizeEventTime(value)
+	if m := eventTimeDashedRange.FindStringSubmatch(normalized); m != nil {
+		return parseEventTimeRange(m[1], m[2])
+	}
+
+	return parseEventTimePoint(normalized)
+}
+
+// parseEventTimeRange 解析时间范围，结束时间无法解析时退化为起始时间
+func parseEventTimeRange(startValue, endValue string) EventTime {
+	start := parseEventTimePoint(normalizeEventTime(startValue))
+	if !start.Valid {
+		return start
+	}
+	end := parseEventTimePoint(normalizeEventTime(endValue))
+	if end.Valid && !end.End.Before(start.Start) {
+		start.End = end.End
+	}
+	return start
+}
+
+// parseEventTimePoint 解析单个时间点，End 为该精度下的最后一天
+func parseEventTimePoint(value string) EventTime {
+	m := eventTimeDate.FindStringSubmatch(value)
+	if m == nil {
+		return EventTime{}
+	}
+
+	year, _ := strconv.Atoi(m[1])
+	month, day := 1, 1
+	precision := PrecisionYear
+	if m[2] != "" {
+		month, _ = strconv.Atoi(m[2])
+		if month < 1 || month > 12 {
+			return EventTime{}
+		}
+		precision = PrecisionMonth
+	}
+	if m[3] != "" {
+		day, _ = strconv.Atoi(m[3])
+		if day < 1 || day > 31 {
+			return EventTime{}
+		}
+		precision = PrecisionDay
+	}
+
+	start := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	var end time.Time
+	switch precision {
+	case PrecisionYear:
+		end = start.AddDate(1, 0, -1)
+	case PrecisionMonth:
+		end = start.AddDate(0, 1, -1)
+	default:
+		end = start
+	}
+	return EventTime{Start: start, End: end, Precision: precision, Valid: true}
+}
+
+// normalizeEventTime 统一日期分隔符并去除空白
+func normalizeEventTime(value string) string {
+	value = eventTimeNormalizer.Replace(strings.TrimSpace(value))
+	value = strings.Join(strings.Fields(value), "")
+	return strings.Trim(value, "-")
+}
+
+// SortEventsByTime 按事件时间从早到晚稳定排序，无法解析时间的事件排在最后
+func SortEventsByTime(events []Event) {
+	times := make(map[string]EventTime, len(events))
+	for _, e := range events {
+		times[e.Time] = ParseEventTime(e.Time)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		ti, tj := times[events[i].Time], times[events[j].Time]
+		if ti.Valid != tj.Valid {
+			return ti.Valid
+		}
+		return ti.Start.Before(tj.Start)
+	})
+}
+
+// MergeEvents 合并时间与标题相同的重复事件（合并人物和来源，保留较长的摘要），按时间排序后重新编号
+func MergeEvents(events []Event) []Event {
+	var merged []Event
+	index := make(map[string]int)
+	for _, e := range events {
+		key := normalizeEventTime(e.Time) + "|" + strings.Join(strings.Fields(e.Title), "")
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, e)
+			continue
+		}
+		target := &merged[i]
+		if len([]rune(e.Summary)) > len([]rune(target.Summary)) {
+			target.Summary = e.Summary
+		}
+		if target.Location == "" {
+			target.Location = e.Location
+		}
+		target.People = appendUnique(target.People, e.People...)
+		for _, source := range e.Sources {
+			if !hasSource(target.Sources, source) {
+				target.Sources = append(target.Sources, source)
+			}
+		}
+	}
+
+	SortEventsByTime(merged)
+	for i := range merged {
+		merged[i].ID = strconv.Itoa(i + 1)
+	}
+	return merged
+}
+
+// appendUnique 追加不重复的字符串
+func appendUnique(values []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, v := range values {
+			if v == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// Label 返回来源的展示名称，依次使用标题、站点名称和 URL
+func (s EventSource) Label() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	if s.SiteName != "" {
+		return s.SiteName
+	}
+	return s.URL
+}
+
+// hasSource 判断来源是否已存在，优先按 URL 比较
+func hasSource(sources []EventSource, source EventSource) bool {
+	for _, s := range sources {
+		if source.URL != "" && s.URL == source.URL || source.URL == "" && s.Title == source.Title {
+			return true
+		}
+	}
+	return false
+}
+
+// LatestEventTime 返回起始时间最晚的事件时间，没有可解析时间的事件时返回 false
+func LatestEventTime(events []Event) (EventTime, bool) {
+	var latest EventTime
+	for _, e := range events {
+		t := ParseEventTime(e.Time)
+		if t.Valid && (!latest.Valid || t.Start.After(latest.Start)) {
+			latest = t
+		}
+	}
+	return latest, latest.Valid
+}
+
+// NextEventID 返回下一个可用的数字事件 ID，新增事件使用递增 ID 以保持已有 ID 不变
+func NextEventID(events []Event) int {
+	next := 1
+	for _, e := range events {
+		if n, err := strconv.Atoi(e.ID); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return next
+}
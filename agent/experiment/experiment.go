@@ -0,0 +1,124 @@
+package experiment
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 实验对象
+const (
+	TargetTimeline = "timeline" // 联网生成时间链，变体可指定 ark_timeline_* 提示词和 Ark 模型
+	TargetGraph    = "graph"    // 知识图谱生成，变体可指定 graph_* 提示词和 DeepSeek 模型
+)
+
+// 实验状态
+const (
+	StatusRunning = "running"
+	StatusStopped = "stopped"
+)
+
+// maxLatencySamples 每个变体保留的最近耗时样本数，用于计算分位数
+const maxLatencySamples = 1000
+
+// Variant 实验变体
+type Variant struct {
+	Name      string `json:"name"`
+	Weight    int    `json:"weight"`               // 分流权重，按各变体权重占比分配请求
+	PromptDir string `json:"prompt_dir,omitempty"` // 提示词模板目录，为空时使用当前的提示词
+	Model     string `json:"model,omitempty"`      // 模型名称，为空时使用默认模型
+}
+
+// Experiment 提示词或模型的对比实验，运行中的实验按权重把生成请求分配到各变体并记录指标
+type Experiment struct {
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Target      string              `json:"target"`
+	Status      string              `json:"status"`
+	Variants    []Variant           `json:"variants"`
+	Metrics     map[string]*Metrics `json:"metrics"` // 按变体名称记录的累计指标
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// Validate 校验实验配置：至少两个名称不重复的变体，权重非负且总和大于 0
+func (e *Experiment) Validate() error {
+	if strings.TrimSpace(e.Name) == "" {
+		return fmt.Errorf("实验名称不能为空")
+	}
+	if e.Target != TargetTimeline && e.Target != TargetGraph {
+		return fmt.Errorf("不支持的实验对象: %s，可选: %s、%s", e.Target, TargetTimeline, TargetGraph)
+	}
+	if e.Status != StatusRunning && e.Status != StatusStopped {
+		return fmt.Errorf("不支持的实验状态: %s", e.Status)
+	}
+	if len(e.Variants) < 2 {
+		return fmt.Errorf("实验至少需要两个变体")
+	}
+	names := make(map[string]bool, len(e.Variants))
+	total := 0
+	for _, v := range e.Variants {
+		if strings.TrimSpace(v.Name) == "" {
+			return fmt.Errorf("变体名称不能为空")
+		}
+		if names[v.Name] {
+			return fmt.Errorf("变体名称重复: %s", v.Name)
+		}
+		names[v.Name] = true
+		if v.Weight < 0 {
+			return fmt.Errorf("变体 %s 的权重不能为负数", v.Name)
+		}
+		total += v.Weight
+	}
+	if total == 0 {
+		return fmt.Errorf("变体权重之和必须大于 0")
+	}
+	return nil
+}
+
+// Assign 按权重选择变体，r 为 [0, 1) 之间的随机数
+func (e *Experiment) Assign(r float64) *Variant {
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+	if total == 0 {
+		return nil
+	}
+	point := int(r * float64(total))
+	for i := range e.Variants {
+		if point < e.Variants[i].Weight {
+			return &e.Variants[i]
+		}
+		point -= e.Variants[i].Weight
+	}
+	return &e.Variants[len(e.Variants)-1]
+}
+
+// Record 把一次运行的结果计入对应变体的指标
+func (e *Experiment) Record(run Run) {
+	if e.Metrics == nil {
+		e.Metrics = make(map[string]*Metrics)
+	}
+	m, ok := e.Metrics[run.Variant]
+	if !ok {
+		m = &Metrics{}
+		e.Metrics[run.Variant] = m
+	}
+	m.add(run)
+	e.UpdatedAt = time.Now()
+}
+
+// Aggregates 按变体顺序返回各变体的汇总指标，没有运行记录的变体各项为 0
+func (e *Experiment) Aggregates() []Aggregate {
+	aggregates := make([]Aggregate, 0, len(e.Variants))
+	for _, v := range e.Variants {
+		a := Aggregate{Variant: v.Name, Weight: v.Weight}
+		if m := e.Metrics[v.Name]; m != nil {
+			a.fill(m)
+		}
+		aggregates = append(aggregates, a)
+	}
+	return aggregates
+}
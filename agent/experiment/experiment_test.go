@@ -0,0 +1,147 @@
+package experiment
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	valid := func() *Experiment {
+		return &Experiment{Name: "提示词对比", Target: TargetTimeline, Status: StatusRunning,
+			Variants: []Variant{{Name: "a", Weight: 50}, {Name: "b", Weight: 50}}}
+	}
+	tests := []struct {
+		name   string
+		modify func(e *Experiment)
+		want   string // 错误信息包含的内容，为空表示校验通过
+	}{
+		{"合法配置", func(e *Experiment) {}, ""},
+		{"权重可以为 0", func(e *Experiment) { e.Variants[1].Weight = 0 }, ""},
+		{"名称为空", func(e *Experiment) { e.Name = " " }, "实验名称不能为空"},
+		{"不支持的对象", func(e *Experiment) { e.Target = "report" }, "不支持的实验对象"},
+		{"不支持的状态", func(e *Experiment) { e.Status = "paused" }, "不支持的实验状态"},
+		{"只有一个变体", func(e *Experiment) { e.Variants = e.Variants[:1] }, "至少需要两个变体"},
+		{"变体名称为空", func(e *Experiment) { e.Variants[0].Name = "" }, "变体名称不能为空"},
+		{"变体名称重复", func(e *Experiment) { e.Variants[1].Name = "a" }, "变体名称重复"},
+		{"权重为负数", func(e *Experiment) { e.Variants[0].Weight = -1 }, "不能为负数"},
+		{"权重之和为 0", func(e *Experiment) { e.Variants[0].Weight, e.Variants[1].Weight = 0, 0 }, "之和必须大于 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := valid()
+			tt.modify(e)
+			err := e.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v; want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v; want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssign(t *testing.T) {
+	e := &Experiment{Variants: []Variant{{Name: "a", Weight: 20}, {Name: "b", Weight: 0}, {Name: "c", Weight: 80}}}
+	tests := []struct {
+		name string
+		r    float64
+		want string
+	}{
+		{"区间起点", 0, "a"},
+		{"第一个变体的区间末尾", 0.19, "a"},
+		{"跳过权重为 0 的变体", 0.2, "c"},
+		{"接近 1", 0.999, "c"},
+		{"越界时使用最后一个变体", 1, "c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.Assign(tt.r); got == nil || got.Name != tt.want {
+				t.Errorf("Assign(%v) = %+v; want %s", tt.r, got, tt.want)
+			}
+		})
+	}
+
+	if got := (&Experiment{Variants: []Variant{{Name: "a"}, {Name: "b"}}}).Assign(0.5); got != nil {
+		t.Errorf("权重之和为 0 时应返回 nil，got %+v", got)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	samples := []int64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}
+	tests := []struct {
+		name   string
+		sorted []int64
+		p      float64
+		want   int64
+	}{
+		{"没有样本", nil, 0.5, 0},
+		{"单个样本", []int64{42}, 0.95, 42},
+		{"P50", samples, 0.5, 50},
+		{"P95", samples, 0.95, 100},
+		{"P0 取最小值", samples, 0, 10},
+		{"P100 取最大值", samples, 1, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.sorted, tt.p); got != tt.want {
+				t.Errorf("percentile(%v, %v) = %d; want %d", tt.sorted, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	e := &Experiment{Variants: []Variant{{Name: "a", Weight: 1}, {Name: "b", Weight: 1}}}
+	runs := []Run{
+		{Variant: "a", Outputs: 10, Calls: 2, RefineRounds: 1, PromptTokens: 100, CompletionTokens: 50, Latency: 300 * time.Millisecond},
+		{Variant: "a", Failed: true, Calls: 1, ParseFailures: 1, PromptTokens: 80, Latency: 1200 * time.Millisecond},
+		{Variant: "b", Outputs: 5, Calls: 1, Latency: 100 * time.Millisecond},
+	}
+	for _, run := range runs {
+		e.Record(run)
+	}
+
+	want := map[string]*Metrics{
+		"a": {Runs: 2, Failures: 1, Calls: 3, ParseFailures: 1, Outputs: 10, RefineRounds: 1, PromptTokens: 180, CompletionTokens: 50,
+			LatencyMs: 1500, MaxLatencyMs: 1200, LatencySamples: []int64{300, 1200}},
+		"b": {Runs: 1, Calls: 1, Outputs: 5, LatencyMs: 100, MaxLatencyMs: 100, LatencySamples: []int64{100}},
+	}
+	if !reflect.DeepEqual(e.Metrics, want) {
+		t.Errorf("Metrics = %+v; want %+v", e.Metrics, want)
+	}
+
+	for i := 0; i < maxLatencySamples+5; i++ {
+		e.Record(Run{Variant: "b", Latency: time.Duration(i) * time.Millisecond})
+	}
+	if samples := e.Metrics["b"].LatencySamples; len(samples) != maxLatencySamples || samples[0] != 5 {
+		t.Errorf("耗时样本应只保留最近 %d 个，got %d 个，首个 %d", maxLatencySamples, len(samples), samples[0])
+	}
+}
+
+func TestAggregates(t *testing.T) {
+	e := &Experiment{
+		Variants: []Variant{{Name: "a", Weight: 70}, {Name: "b", Weight: 30}},
+		Metrics: map[string]*Metrics{
+			"a": {Runs: 3, Failures: 1, Calls: 6, ParseFailures: 2, Outputs: 20, RefineRounds: 4, PromptTokens: 300, CompletionTokens: 100,
+				LatencyMs: 600, MaxLatencyMs: 300, LatencySamples: []int64{300, 100, 200}},
+		},
+	}
+	want := []Aggregate{
+		{Variant: "a", Weight: 70, Runs: 3, Failures: 1, FailureRate: 0.333, ParseFailureRate: 0.333, AvgOutputs: 6.667,
+			AvgRefineRounds: 1.333, AvgPromptTokens: 100, AvgCompletionTokens: 33.333, AvgLatencyMs: 200,
+			P50LatencyMs: 200, P95LatencyMs: 300, MaxLatencyMs: 300},
+		{Variant: "b", Weight: 30},
+	}
+	if got := e.Aggregates(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Aggregates() = %+v; want %+v", got, want)
+	}
+	if got := e.Metrics["a"].LatencySamples; !reflect.DeepEqual(got, []int64{300, 100, 200}) {
+		t.Errorf("计算分位数不应修改样本顺序: %v", got)
+	}
+}
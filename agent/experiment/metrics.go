@@ -0,0 +1,120 @@
+package experiment
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Run 一次参与实验的生成结果
+type Run struct {
+	Variant          string
+	Failed           bool // 生成失败（调用出错或使用了后备数据）
+	Outputs          int  // 产出的条目数：时间链为事件数，图谱为节点数
+	Calls            int  // 模型调用次数
+	ParseFailures    int  // 响应无法解析的次数
+	RefineRounds     int  // 反思优化轮数
+	PromptTokens     int
+	CompletionTokens int
+	Latency          time.Duration
+}
+
+// Metrics 变体的累计指标
+type Metrics struct {
+	Runs             int     `json:"runs"`
+	Failures         int     `json:"failures"`
+	Calls            int     `json:"calls"`
+	ParseFailures    int     `json:"parse_failures"`
+	Outputs          int     `json:"outputs"`
+	RefineRounds     int     `json:"refine_rounds"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	LatencyMs        int64   `json:"latency_ms"`
+	MaxLatencyMs     int64   `json:"max_latency_ms"`
+	LatencySamples   []int64 `json:"latency_samples,omitempty"` // 最近的耗时样本（毫秒）
+}
+
+// add 累加一次运行
+func (m *Metrics) add(run Run) {
+	latency := run.Latency.Milliseconds()
+	m.Runs++
+	if run.Failed {
+		m.Failures++
+	}
+	m.Calls += run.Calls
+	m.ParseFailures += run.ParseFailures
+	m.Outputs += run.Outputs
+	m.RefineRounds += run.RefineRounds
+	m.PromptTokens += run.PromptTokens
+	m.CompletionTokens += run.CompletionTokens
+	m.LatencyMs += latency
+	if latency > m.MaxLatencyMs {
+		m.MaxLatencyMs = latency
+	}
+	m.LatencySamples = append(m.LatencySamples, latency)
+	if len(m.LatencySamples) > maxLatencySamples {
+		m.LatencySamples = m.LatencySamples[len(m.LatencySamples)-maxLatencySamples:]
+	}
+}
+
+// Aggregate 变体的汇总指标，平均值均按运行次数计算
+type Aggregate struct {
+	Variant             string  `json:"variant"`
+	Weight              int     `json:"weight"`
+	Runs                int     `json:"runs"`
+	Failures            int     `json:"failures"`
+	FailureRate         float64 `json:"failure_rate"`
+	ParseFailureRate    float64 `json:"parse_failure_rate"` // 解析失败次数占模型调用次数的比例
+	AvgOutputs          float64 `json:"avg_outputs"`
+	AvgRefineRounds     float64 `json:"avg_refine_rounds"`
+	AvgPromptTokens     float64 `json:"avg_prompt_tokens"`
+	AvgCompletionTokens float64 `json:"avg_completion_tokens"`
+	AvgLatencyMs        float64 `json:"avg_latency_ms"`
+	P50LatencyMs        int64   `json:"p50_latency_ms"`
+	P95LatencyMs        int64   `json:"p95_latency_ms"`
+	MaxLatencyMs        int64   `json:"max_latency_ms"`
+}
+
+// fill 根据累计指标计算汇总值
+func (a *Aggregate) fill(m *Metrics) {
+	a.Runs = m.Runs
+	a.Failures = m.Failures
+	a.MaxLatencyMs = m.MaxLatencyMs
+	a.ParseFailureRate = ratio(m.ParseFailures, m.Calls)
+	if m.Runs == 0 {
+		return
+	}
+	a.FailureRate = ratio(m.Failures, m.Runs)
+	a.AvgOutputs = ratio(m.Outputs, m.Runs)
+	a.AvgRefineRounds = ratio(m.RefineRounds, m.Runs)
+	a.AvgPromptTokens = ratio(m.PromptTokens, m.Runs)
+	a.AvgCompletionTokens = ratio(m.CompletionTokens, m.Runs)
+	a.AvgLatencyMs = round(float64(m.LatencyMs) / float64(m.Runs))
+
+	samples := append([]int64(nil), m.LatencySamples...)
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	a.P50LatencyMs = percentile(samples, 0.5)
+	a.P95LatencyMs = percentile(samples, 0.95)
+}
+
+// ratio 计算比值并保留三位小数，分母为 0 时返回 0
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return round(float64(n) / float64(d))
+}
+
+// round 保留三位小数
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+// percentile 返回已排序样本的分位数（最近秩法）
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
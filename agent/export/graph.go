@@ -0,0 +1,509 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"lineNews/agent"
+)
+
+// Format 导出格式描述
+type Format struct {
+	Name        string
+	ContentType string
+	Extension   string
+}
+
+// 图谱导出格式
+var (
+	FormatGraphML = Format{Name: "graphml", ContentType: "application/graphml+xml; charset=utf-8", Extension: "graphml"}
+	FormatGEXF    = Format{Name: "gexf", ContentType: "application/gexf+xml; charset=utf-8", Extension: "gexf"}
+	FormatCypher  = Format{Name: "cypher", ContentType: "text/plain; charset=utf-8", Extension: "cypher"}
+	FormatTurtle  = Format{Name: "turtle", ContentType: "text/turtle; charset=utf-8", Extension: "ttl"}
+)
+
+// graphFormatAliases 图谱导出格式名称及别名
+var graphFormatAliases = map[string]Format{
+	"graphml": FormatGraphML,
+	"gexf":    FormatGEXF,
+	"cypher":  FormatCypher,
+	"neo4j":   FormatCypher,
+	"turtle":  FormatTurtle,
+	"ttl":     FormatTurtle,
+	"rdf":     FormatTurtle,
+}
+
+// GraphFormat 按名称或别名查找图谱导出格式
+func GraphFormat(format string) (Format, error) {
+	f, ok := graphFormatAliases[strings.ToLower(format)]
+	if !ok {
+		return Format{}, fmt.Errorf("不支持的图谱导出格式: %s", format)
+	}
+	return f, nil
+}
+
+// ExportGraph 按格式名称导出知识图谱
+func ExportGraph(graph *agent.GraphResponse, format string) ([]byte, Format, error) {
+	f, err := GraphFormat(format)
+	if err != nil {
+		return nil, Format{}, err
+	}
+	if graph == nil {
+		return nil, f, fmt.Errorf("知识图谱为空")
+	}
+
+	var data []byte
+	switch f {
+	case FormatGraphML:
+		data, err = GraphToGraphML(graph)
+	case FormatGEXF:
+		data, err = GraphToGEXF(graph)
+	case FormatCypher:
+		data = []byte(GraphToCypher(graph))
+	case FormatTurtle:
+		data = []byte(GraphToTurtle(graph))
+	}
+	return data, f, err
+}
+
+// ==================== GraphML ====================
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID       string        `xml:"id,attr"`
+	Source   string        `xml:"source,attr"`
+	Target   string        `xml:"target,attr"`
+	Directed bool          `xml:"directed,attr"`
+	Data     []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// GraphToGraphML 将知识图谱导出为 GraphML（可导入 Gephi、yEd、Cytoscape 等）
+func GraphToGraphML(graph *agent.GraphResponse) ([]byte, error) {
+	doc := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "name", For: "node", AttrName: "name", AttrType: "string"},
+			{ID: "category", For: "node", AttrName: "category", AttrType: "string"},
+			{ID: "description", For: "node", AttrName: "description", AttrType: "string"},
+			{ID: "aliases", For: "node", AttrName: "aliases", AttrType: "string"},
+			{ID: "first_seen", For: "node", AttrName: "first_seen", AttrType: "string"},
+			{ID: "last_seen", For: "node", AttrName: "last_seen", AttrType: "string"},
+			{ID: "importance", For: "node", AttrName: "importance", AttrType: "double"},
+			{ID: "baike_url", For: "node", AttrName: "baike_url", AttrType: "string"},
+			{ID: "relation", For: "edge", AttrName: "relation", AttrType: "string"},
+			{ID: "weight", For: "edge", AttrName: "weight", AttrType: "double"},
+			{ID: "time", For: "edge", AttrName: "time", AttrType: "string"},
+			{ID: "event_ids", For: "edge", AttrName: "event_ids", AttrType: "string"},
+		},
+		Graph: graphMLGraph{
+			ID:          graph.Keyword,
+			EdgeDefault: "undirected",
+		},
+	}
+
+	for _, node := range graph.Nodes {
+		n := graphMLNode{ID: node.ID}
+		n.Data = appendGraphMLData(n.Data, "name", node.Name)
+		n.Data = appendGraphMLData(n.Data, "category", node.Category)
+		n.Data = appendGraphMLData(n.Data, "description", node.Description)
+		n.Data = appendGraphMLData(n.Data, "aliases", strings.Join(node.Aliases, "|"))
+		n.Data = appendGraphMLData(n.Data, "first_seen", node.FirstSeen)
+		n.Data = appendGraphMLData(n.Data, "last_seen", node.LastSeen)
+		n.Data = appendGraphMLData(n.Data, "importance", formatFloat(node.Importance))
+		if node.Baike != nil {
+			n.Data = appendGraphMLData(n.Data, "baike_url", node.Baike.URL)
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, n)
+	}
+
+	for i, link := range graph.Links {
+		e := graphMLEdge{
+			ID:       fmt.Sprintf("l%d", i),
+			Source:   link.Source,
+			Target:   link.Target,
+			Directed: link.Directed,
+		}
+		e.Data = appendGraphMLData(e.Data, "relation", link.Relation)
+		e.Data = appendGraphMLData(e.Data, "weight", formatFloat(link.Weight))
+		e.Data = appendGraphMLData(e.Data, "time", link.Time)
+		e.Data = appendGraphMLData(e.Data, "event_ids", strings.Join(link.EventIDs, ","))
+		doc.Graph.Edges = append(doc.Graph.Edges, e)
+	}
+
+	return marshalXML(doc)
+}
+
+// appendGraphMLData 追加非空的 GraphML 数据项
+func appendGraphMLData(data []graphMLData, key, value string) []graphMLData {
+	if value == "" {
+		return data
+	}
+	return append(data, graphMLData{Key: key, Value: value})
+}
+
+// ==================== GEXF ====================
+
+type gexf struct {
+	XMLName xml.Name  `xml:"gexf"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Meta    gexfMeta  `xml:"meta"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfMeta struct {
+	Creator     string `xml:"creator"`
+	Description string `xml:"description"`
+}
+
+type gexfGraph struct {
+	Mode            string           `xml:"mode,attr"`
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue,omitempty"`
+}
+
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Type      string         `xml:"type,attr"`
+	Label     string         `xml:"label,attr,omitempty"`
+	Weight    string         `xml:"weight,attr,omitempty"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue,omitempty"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// GraphToGEXF 将知识图谱导出为 GEXF 1.3（Gephi 原生格式）
+func GraphToGEXF(graph *agent.GraphResponse) ([]byte, error) {
+	doc := gexf{
+		Xmlns:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Meta: gexfMeta{
+			Creator:     "lineNews",
+			Description: graph.Keyword,
+		},
+		Graph: gexfGraph{
+			Mode:            "static",
+			DefaultEdgeType: "undirected",
+			Attributes: []gexfAttributes{
+				{
+					Class: "node",
+					Attributes: []gexfAttribute{
+						{ID: "category", Title: "category", Type: "string"},
+						{ID: "description", Title: "description", Type: "string"},
+						{ID: "aliases", Title: "aliases", Type: "string"},
+						{ID: "first_seen", Title: "first_seen", Type: "string"},
+						{ID: "last_seen", Title: "last_seen", Type: "string"},
+						{ID: "importance", Title: "importance", Type: "double"},
+						{ID: "baike_url", Title: "baike_url", Type: "string"},
+					},
+				},
+				{
+					Class: "edge",
+					Attributes: []gexfAttribute{
+						{ID: "time", Title: "time", Type: "string"},
+						{ID: "event_ids", Title: "event_ids", Type: "string"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, node := range graph.Nodes {
+		n := gexfNode{ID: node.ID, Label: node.Name}
+		n.AttValues = appendGEXFValue(n.AttValues, "category", node.Category)
+		n.AttValues = appendGEXFValue(n.AttValues, "description", node.Description)
+		n.AttValues = appendGEXFValue(n.AttValues, "aliases", strings.Join(node.Aliases, "|"))
+		n.AttValues = appendGEXFValue(n.AttValues, "first_seen", node.FirstSeen)
+		n.AttValues = appendGEXFValue(n.AttValues, "last_seen", node.LastSeen)
+		n.AttValues = appendGEXFValue(n.AttValues, "importance", formatFloat(node.Importance))
+		if node.Baike != nil {
+			n.AttValues = appendGEXFValue(n.AttValues, "baike_url", node.Baike.URL)
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, n)
+	}
+
+	for i, link := range graph.Links {
+		edgeType := "undirected"
+		if link.Directed {
+			edgeType = "directed"
+		}
+		e := gexfEdge{
+			ID:     strconv.Itoa(i),
+			Source: link.Source,
+			Target: link.Target,
+			Type:   edgeType,
+			Label:  link.Relation,
+			Weight: formatFloat(link.Weight),
+		}
+		e.AttValues = appendGEXFValue(e.AttValues, "time", link.Time)
+		e.AttValues = appendGEXFValue(e.AttValues, "event_ids", strings.Join(link.EventIDs, ","))
+		doc.Graph.Edges = append(doc.Graph.Edges, e)
+	}
+
+	return marshalXML(doc)
+}
+
+// appendGEXFValue 追加非空的 GEXF 属性值
+func appendGEXFValue(values []gexfAttValue, key, value string) []gexfAttValue {
+	if value == "" {
+		return values
+	}
+	return append(values, gexfAttValue{For: key, Value: value})
+}
+
+// ==================== Cypher ====================
+
+// GraphToCypher 将知识图谱导出为 Neo4j Cypher CREATE 语句
+// 节点使用 Entity 标签加类别标签，关系类型使用关系名称
+func GraphToCypher(graph *agent.GraphResponse) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// lineNews 知识图谱: %s\n", graph.Keyword)
+
+	vars := make(map[string]string, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		v := fmt.Sprintf("n%d", i)
+		vars[node.ID] = v
+
+		props := []string{
+			"id: " + cypherString(node.ID),
+			"name: " + cypherString(node.Name),
+			"keyword: " + cypherString(graph.Keyword),
+		}
+		if node.Description != "" {
+			props = append(props, "description: "+cypherString(node.Description))
+		}
+		if len(node.Aliases) > 0 {
+			props = append(props, "aliases: "+cypherList(node.Aliases))
+		}
+		if node.FirstSeen != "" {
+			props = append(props, "first_seen: "+cypherString(node.FirstSeen))
+		}
+		if node.LastSeen != "" {
+			props = append(props, "last_seen: "+cypherString(node.LastSeen))
+		}
+		if node.Importance != 0 {
+			props = append(props, "importance: "+formatFloat(node.Importance))
+		}
+		if node.Baike != nil && node.Baike.URL != "" {
+			props = append(props, "baike_url: "+cypherString(node.Baike.URL))
+		}
+
+		labels := ":Entity"
+		if node.Category != "" {
+			labels += ":" + cypherIdentifier(node.Category)
+		}
+		fmt.Fprintf(&b, "CREATE (%s%s {%s})\n", v, labels, strings.Join(props, ", "))
+	}
+
+	for _, link := range graph.Links {
+		source, ok1 := vars[link.Source]
+		target, ok2 := vars[link.Target]
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		relType := link.Relation
+		if relType == "" {
+			relType = "RELATED_TO"
+		}
+		props := []string{
+			"relation: " + cypherString(link.Relation),
+			"directed: " + strconv.FormatBool(link.Directed),
+		}
+		if link.Weight != 0 {
+			props = append(props, "weight: "+formatFloat(link.Weight))
+		}
+		if link.Time != "" {
+			props = append(props, "time: "+cypherString(link.Time))
+		}
+		if len(link.EventIDs) > 0 {
+			props = append(props, "event_ids: "+cypherList(link.EventIDs))
+		}
+		fmt.Fprintf(&b, "CREATE (%s)-[:%s {%s}]->(%s)\n", source, cypherIdentifier(relType), strings.Join(props, ", "), target)
+	}
+
+	b.WriteString(";\n")
+	return b.String()
+}
+
+// cypherString 转义 Cypher 字符串字面量
+func cypherString(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`)
+	return "'" + replacer.Replace(s) + "'"
+}
+
+// cypherList 生成 Cypher 字符串列表
+func cypherList(values []string) string {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = cypherString(v)
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// cypherIdentifier 使用反引号转义 Cypher 标识符
+func cypherIdentifier(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
+
+// ==================== RDF/Turtle ====================
+
+// GraphToTurtle 将知识图谱导出为 RDF Turtle
+// 每个节点是一个资源，关系既作为直接三元组输出，也作为带属性的 Edge 资源输出
+func GraphToTurtle(graph *agent.GraphResponse) string {
+	base := "urn:linenews:graph:" + url.PathEscape(graph.Keyword) + ":"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@prefix rdf: <http://www.w3.org/1999/02/22-rdf-syntax-ns#> .\n")
+	fmt.Fprintf(&b, "@prefix rdfs: <http://www.w3.org/2000/01/rdf-schema#> .\n")
+	fmt.Fprintf(&b, "@prefix xsd: <http://www.w3.org/2001/XMLSchema#> .\n")
+	fmt.Fprintf(&b, "@prefix ln: <urn:linenews:vocab:> .\n\n")
+
+	nodeIRI := func(id string) string {
+		return "<" + base + "node:" + url.PathEscape(id) + ">"
+	}
+
+	fmt.Fprintf(&b, "<%s> a ln:Graph ;\n    rdfs:label %s .\n\n", strings.TrimSuffix(base, ":"), turtleString(graph.Keyword))
+
+	for _, node := range graph.Nodes {
+		fmt.Fprintf(&b, "%s a ln:Entity ;\n", nodeIRI(node.ID))
+		fmt.Fprintf(&b, "    ln:id %s ;\n", turtleString(node.ID))
+		if node.Category != "" {
+			fmt.Fprintf(&b, "    ln:category %s ;\n", turtleString(node.Category))
+		}
+		if node.Description != "" {
+			fmt.Fprintf(&b, "    rdfs:comment %s ;\n", turtleString(node.Description))
+		}
+		for _, alias := range node.Aliases {
+			fmt.Fprintf(&b, "    ln:alias %s ;\n", turtleString(alias))
+		}
+		if node.FirstSeen != "" {
+			fmt.Fprintf(&b, "    ln:firstSeen %s ;\n", turtleString(node.FirstSeen))
+		}
+		if node.LastSeen != "" {
+			fmt.Fprintf(&b, "    ln:lastSeen %s ;\n", turtleString(node.LastSeen))
+		}
+		if node.Importance != 0 {
+			fmt.Fprintf(&b, "    ln:importance \"%s\"^^xsd:double ;\n", formatFloat(node.Importance))
+		}
+		if node.Baike != nil && node.Baike.URL != "" {
+			fmt.Fprintf(&b, "    rdfs:seeAlso <%s> ;\n", turtleIRI(node.Baike.URL))
+		}
+		fmt.Fprintf(&b, "    rdfs:label %s .\n\n", turtleString(node.Name))
+	}
+
+	for i, link := range graph.Links {
+		predicate := "<urn:linenews:relation:" + url.PathEscape(link.Relation) + ">"
+		fmt.Fprintf(&b, "%s %s %s .\n", nodeIRI(link.Source), predicate, nodeIRI(link.Target))
+
+		fmt.Fprintf(&b, "<%sedge:%d> a ln:Edge ;\n", base, i)
+		fmt.Fprintf(&b, "    ln:source %s ;\n", nodeIRI(link.Source))
+		fmt.Fprintf(&b, "    ln:target %s ;\n", nodeIRI(link.Target))
+		fmt.Fprintf(&b, "    ln:directed \"%t\"^^xsd:boolean ;\n", link.Directed)
+		if link.Weight != 0 {
+			fmt.Fprintf(&b, "    ln:weight \"%s\"^^xsd:double ;\n", formatFloat(link.Weight))
+		}
+		if link.Time != "" {
+			fmt.Fprintf(&b, "    ln:time %s ;\n", turtleString(link.Time))
+		}
+		for _, eventID := range link.EventIDs {
+			fmt.Fprintf(&b, "    ln:evidence %s ;\n", turtleString(eventID))
+		}
+		fmt.Fprintf(&b, "    ln:relation %s .\n\n", turtleString(link.Relation))
+	}
+
+	return b.String()
+}
+
+// turtleString 转义 Turtle 字符串字面量
+func turtleString(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+// turtleIRI 转义 IRI 中 Turtle 不允许出现的字符
+func turtleIRI(s string) string {
+	replacer := strings.NewReplacer("<", "%3C", ">", "%3E", `"`, "%22", " ", "%20", "{", "%7B", "}", "%7D", "|", "%7C", `\`, "%5C", "^", "%5E", "`", "%60")
+	return replacer.Replace(s)
+}
+
+// ==================== 工具函数 ====================
+
+// marshalXML 序列化 XML 并添加声明头
+func marshalXML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("序列化 XML 失败: %w", err)
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// formatFloat 格式化浮点数，零值返回空字符串
+func formatFloat(f float64) string {
+	if f == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,355 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"lineNews/agent"
+)
+
+// 时间链导出格式
+var (
+	FormatICS        = Format{Name: "ics", ContentType: "text/calendar; charset=utf-8", Extension: "ics"}
+	FormatCSV        = Format{Name: "csv", ContentType: "text/csv; charset=utf-8", Extension: "csv"}
+	FormatMarkdown   = Format{Name: "md", ContentType: "text/markdown; charset=utf-8", Extension: "md"}
+	FormatTimelineJS = Format{Name: "timelinejs", ContentType: "application/json; charset=utf-8", Extension: "json"}
+)
+
+// timelineFormatAliases 时间链导出格式名称及别名
+var timelineFormatAliases = map[string]Format{
+	"ics":        FormatICS,
+	"ical":       FormatICS,
+	"icalendar":  FormatICS,
+	"csv":        FormatCSV,
+	"md":         FormatMarkdown,
+	"markdown":   FormatMarkdown,
+	"timelinejs": FormatTimelineJS,
+}
+
+// TimelineFormat 按名称或别名查找时间链导出格式
+func TimelineFormat(format string) (Format, error) {
+	f, ok := timelineFormatAliases[strings.ToLower(format)]
+	if !ok {
+		return Format{}, fmt.Errorf("不支持的时间链导出格式: %s", format)
+	}
+	return f, nil
+}
+
+// ExportTimeline 按格式名称导出时间链
+func ExportTimeline(timeline *agent.TimelineResponse, format string) ([]byte, Format, error) {
+	f, err := TimelineFormat(format)
+	if err != nil {
+		return nil, Format{}, err
+	}
+	if timeline == nil {
+		return nil, f, fmt.Errorf("时间链为空")
+	}
+
+	var data []byte
+	switch f {
+	case FormatICS:
+		data = []byte(TimelineToICS(timeline))
+	case FormatCSV:
+		data, err = TimelineToCSV(timeline)
+	case FormatMarkdown:
+		data = []byte(TimelineToMarkdown(timeline))
+	case FormatTimelineJS:
+		data, err = TimelineToTimelineJS(timeline)
+	}
+	return data, f, err
+}
+
+// ==================== iCalendar ====================
+
+// TimelineToICS 将时间链导出为 iCalendar，每个事件对应一个全天 VEVENT，无法解析时间的事件会被跳过
+func TimelineToICS(timeline *agent.TimelineResponse) string {
+	var b strings.Builder
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//lineNews//Timeline Export//ZH")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "X-WR-CALNAME:"+icsText(timeline.Keyword))
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	for i, event := range timeline.Events {
+		t := agent.ParseEventTime(event.Time)
+		if !t.Valid {
+			continue
+		}
+
+		id := event.ID
+		if id == "" {
+			id = strconv.Itoa(i + 1)
+		}
+
+		var description []string
+		if event.Summary != "" {
+			description = append(description, event.Summary)
+		}
+		if len(event.People) > 0 {
+			description = append(description, "相关人物："+strings.Join(event.People, "、"))
+		}
+		description = append(description, "时间："+event.Time)
+		for _, source := range event.Sources {
+			description = append(description, "来源："+source.Label()+" "+source.URL)
+		}
+
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, "UID:"+icsText(fmt.Sprintf("%s-%s@linenews", url.PathEscape(timeline.Keyword), id)))
+		writeICSLine(&b, "DTSTAMP:"+stamp)
+		writeICSLine(&b, "DTSTART;VALUE=DATE:"+t.Start.Format("20060102"))
+		// DTEND 为不包含的结束日期；非精确到日的事件只占用起始日
+		end := t.Start.AddDate(0, 0, 1)
+		if t.End.After(t.Start) && t.Precision == agent.PrecisionDay {
+			end = t.End.AddDate(0, 0, 1)
+		}
+		writeICSLine(&b, "DTEND;VALUE=DATE:"+end.Format("20060102"))
+		writeICSLine(&b, "SUMMARY:"+icsText(event.Title))
+		if event.Location != "" {
+			writeICSLine(&b, "LOCATION:"+icsText(event.Location))
+		}
+		writeICSLine(&b, "DESCRIPTION:"+icsText(strings.Join(description, "\n")))
+		if len(event.Sources) > 0 && event.Sources[0].URL != "" {
+			writeICSLine(&b, "URL:"+event.Sources[0].URL)
+		}
+		writeICSLine(&b, "END:VEVENT")
+	}
+
+	writeICSLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// icsText 转义 iCalendar 文本值
+func icsText(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return replacer.Replace(s)
+}
+
+// writeICSLine 按 RFC 5545 写入一行，超过 75 字节时折行且不截断 UTF-8 字符
+func writeICSLine(b *strings.Builder, line string) {
+	const limit = 75
+	width := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+}
+
+// ==================== CSV ====================
+
+// TimelineToCSV 将时间链导出为 CSV（带 UTF-8 BOM，便于 Excel 正确识别中文）
+func TimelineToCSV(timeline *agent.TimelineResponse) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+
+	writer := csv.NewWriter(&buf)
+	records := [][]string{{"id", "time", "title", "location", "people", "summary", "sources"}}
+	for _, event := range timeline.Events {
+		var sources []string
+		for _, source := range event.Sources {
+			sources = append(sources, source.URL)
+		}
+		records = append(records, []string{
+			event.ID,
+			event.Time,
+			event.Title,
+			event.Location,
+			strings.Join(event.People, "、"),
+			event.Summary,
+			strings.Join(sources, " "),
+		})
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("写入 CSV 失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ==================== Markdown ====================
+
+// TimelineToMarkdown 将时间链导出为 Markdown，便于粘贴到 CMS 文章中
+func TimelineToMarkdown(timeline *agent.TimelineResponse) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s 时间线\n\n", timeline.Keyword)
+
+	for _, event := range timeline.Events {
+		title := event.Title
+		if event.Time != "" {
+			title = event.Time + " · " + title
+		}
+		fmt.Fprintf(&b, "## %s\n\n", title)
+
+		if event.Location != "" {
+			fmt.Fprintf(&b, "- **地点**：%s\n", event.Location)
+		}
+		if len(event.People) > 0 {
+			fmt.Fprintf(&b, "- **人物**：%s\n", strings.Join(event.People, "、"))
+		}
+		if event.Location != "" || len(event.People) > 0 {
+			b.WriteString("\n")
+		}
+		if event.Summary != "" {
+			fmt.Fprintf(&b, "%s\n\n", event.Summary)
+		}
+		if len(event.Sources) > 0 {
+			b.WriteString("来源：\n\n")
+			for _, source := range event.Sources {
+				fmt.Fprintf(&b, "- [%s](%s)\n", markdownLinkText(source.Label()), markdownLinkURL(source.URL))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// markdownLinkText 转义 Markdown 链接文本中的反斜杠和方括号，换行替换为空格
+func markdownLinkText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// markdownLinkURL 将链接地址放在尖括号中，地址可以包含空格和括号；尖括号、反斜杠和换行按 URL 编码
+func markdownLinkURL(link string) string {
+	return "<" + strings.NewReplacer("<", "%3C", ">", "%3E", `\`, "%5C", "\n", "%0A", "\r", "%0D").Replace(link) + ">"
+}
+
+// ==================== TimelineJS ====================
+
+// timelineJSDate TimelineJS3 日期
+type timelineJSDate struct {
+	Year        string `json:"year"`
+	Month       string `json:"month,omitempty"`
+	Day         string `json:"day,omitempty"`
+	DisplayDate string `json:"display_date,omitempty"`
+}
+
+// timelineJSText TimelineJS3 文本
+type timelineJSText struct {
+	Headline string `json:"headline,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+// timelineJSMedia TimelineJS3 媒体
+type timelineJSMedia struct {
+	URL     string `json:"url"`
+	Caption string `json:"caption,omitempty"`
+	Credit  string `json:"credit,omitempty"`
+}
+
+// timelineJSEvent TimelineJS3 事件（slide）
+type timelineJSEvent struct {
+	StartDate timelineJSDate   `json:"start_date"`
+	EndDate   *timelineJSDate  `json:"end_date,omitempty"`
+	Text      timelineJSText   `json:"text"`
+	Media     *timelineJSMedia `json:"media,omitempty"`
+	Group     string           `json:"group,omitempty"`
+	UniqueID  string           `json:"unique_id,omitempty"`
+}
+
+// timelineJSTitle TimelineJS3 标题页
+type timelineJSTitle struct {
+	Text timelineJSText `json:"text"`
+}
+
+// timelineJSDocument TimelineJS3 文档
+type timelineJSDocument struct {
+	Title  *timelineJSTitle  `json:"title,omitempty"`
+	Events []timelineJSEvent `json:"events"`
+}
+
+// TimelineToTimelineJS 将时间链导出为 TimelineJS3 JSON，无法解析时间的事件会被跳过
+func TimelineToTimelineJS(timeline *agent.TimelineResponse) ([]byte, error) {
+	doc := timelineJSDocument{
+		Title: &timelineJSTitle{
+			Text: timelineJSText{
+				Headline: html.EscapeString(timeline.Keyword),
+				Text:     fmt.Sprintf("<p>共 %d 个事件</p>", len(timeline.Events)),
+			},
+		},
+		Events: []timelineJSEvent{},
+	}
+
+	for i, event := range timeline.Events {
+		t := agent.ParseEventTime(event.Time)
+		if !t.Valid {
+			continue
+		}
+
+		var text strings.Builder
+		if event.Summary != "" {
+			fmt.Fprintf(&text, "<p>%s</p>", html.EscapeString(event.Summary))
+		}
+		if event.Location != "" {
+			fmt.Fprintf(&text, "<p>地点：%s</p>", html.EscapeString(event.Location))
+		}
+		if len(event.People) > 0 {
+			fmt.Fprintf(&text, "<p>人物：%s</p>", html.EscapeString(strings.Join(event.People, "、")))
+		}
+		if len(event.Sources) > 0 {
+			text.WriteString("<p>来源：")
+			for j, source := range event.Sources {
+				if j > 0 {
+					text.WriteString("、")
+				}
+				fmt.Fprintf(&text, `<a href="%s" target="_blank">%s</a>`, html.EscapeString(source.URL), html.EscapeString(source.Label()))
+			}
+			text.WriteString("</p>")
+		}
+
+		slide := timelineJSEvent{
+			StartDate: timelineJSDateOf(t.Start, t.Precision, event.Time),
+			Text: timelineJSText{
+				Headline: html.EscapeString(event.Title),
+				Text:     text.String(),
+			},
+			Group:    event.Location,
+			UniqueID: fmt.Sprintf("event-%d", i+1),
+		}
+		if event.ID != "" {
+			slide.UniqueID = "event-" + event.ID
+		}
+		if !t.End.Equal(t.Start) && t.Precision == agent.PrecisionDay {
+			end := timelineJSDateOf(t.End, agent.PrecisionDay, "")
+			slide.EndDate = &end
+		}
+		if len(event.Sources) > 0 && event.Sources[0].URL != "" {
+			slide.Media = &timelineJSMedia{
+				URL:     event.Sources[0].URL,
+				Caption: event.Sources[0].Label(),
+				Credit:  event.Sources[0].SiteName,
+			}
+		}
+		doc.Events = append(doc.Events, slide)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化 TimelineJS 失败: %w", err)
+	}
+	return data, nil
+}
+
+// timelineJSDateOf 按精度生成 TimelineJS3 日期
+func timelineJSDateOf(t time.Time, precision string, display string) timelineJSDate {
+	date := timelineJSDate{Year: strconv.Itoa(t.Year()), DisplayDate: display}
+	if precision == agent.PrecisionMonth || precision == agent.PrecisionDay {
+		date.Month = strconv.Itoa(int(t.Month()))
+	}
+	if precision == agent.PrecisionDay {
+		date.Day = strconv.Itoa(t.Day())
+	}
+	return date
+}
@@ -0,0 +1,32 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"lineNews/agent"
+)
+
+func TestTimelineToMarkdownSources(t *testing.T) {
+	tests := []struct {
+		name   string
+		source agent.EventSource
+		want   string
+	}{
+		{"普通链接", agent.EventSource{Title: "新闻", URL: "https://example.com/a"}, "- [新闻](<https://example.com/a>)"},
+		{"标题包含方括号", agent.EventSource{Title: "[快讯] 发布会]", URL: "https://example.com/a"}, `- [\[快讯\] 发布会\]](<https://example.com/a>)`},
+		{"地址包含括号和空格", agent.EventSource{Title: "百科", URL: "https://example.com/wiki/A (B)"}, "- [百科](<https://example.com/wiki/A (B)>)"},
+		{"地址包含尖括号和反斜杠", agent.EventSource{Title: "新闻", URL: `https://example.com/<a>\`}, "- [新闻](<https://example.com/%3Ca%3E%5C>)"},
+		{"标题包含反斜杠和换行", agent.EventSource{Title: "A\\\nB", URL: "https://example.com/a"}, `- [A\\ B](<https://example.com/a>)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := TimelineToMarkdown(&agent.TimelineResponse{Keyword: "测试", Events: []agent.Event{
+				{ID: "1", Title: "事件", Sources: []agent.EventSource{tt.source}},
+			}})
+			if !strings.Contains(md, tt.want+"\n") {
+				t.Errorf("Markdown 中没有 %q:\n%s", tt.want, md)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EventFilter 时间链检索的过滤条件，在归一化后的事件数据上匹配
+type EventFilter struct {
+	From     string // 时间窗口起点，如 2020、2020-03、2020-03-01
+	To       string // 时间窗口终点，按精度取该年、月或日的最后一天
+	Location string // 地点，忽略大小写、空白和标点后包含即匹配
+	Person   string // 人物，与任一相关人物忽略大小写、空白和标点后包含即匹配
+	Limit    int    // 最多返回的事件数，0 表示不限
+}
+
+// IsZero 判断是否没有任何过滤条件
+func (f *EventFilter) IsZero() bool {
+	return f == nil || f.From == "" && f.To == "" && f.Location == "" && f.Person == "" && f.Limit == 0
+}
+
+// Validate 校验时间窗口和数量限制
+func (f *EventFilter) Validate() error {
+	if f.Limit < 0 {
+		return fmt.Errorf("limit 不能为负数")
+	}
+	_, _, err := parseTimeWindow(f.From, f.To)
+	return err
+}
+
+// Match 判断事件是否满足时间窗口、地点和人物条件；设置了时间窗口时，无法解析时间的事件不匹配
+func (f *EventFilter) Match(event Event) bool {
+	from, to, _ := parseTimeWindow(f.From, f.To)
+	return f.match(event, from, to)
+}
+
+// match 使用已解析的时间窗口匹配事件
+func (f *EventFilter) match(event Event, from, to time.Time) bool {
+	if (!from.IsZero() || !to.IsZero()) && !inWindow(ParseEventTime(event.Time), from, to) {
+		return false
+	}
+	if key := NormalizeKey(f.Location); key != "" && !strings.Contains(NormalizeKey(event.Location), key) {
+		return false
+	}
+	if key := NormalizeKey(f.Person); key != "" {
+		found := false
+		for _, person := range event.People {
+			if strings.Contains(NormalizeKey(person), key) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterEvents 按条件过滤事件，保持原有顺序，超过 Limit 时只保留前 Limit 个
+func (f *EventFilter) FilterEvents(events []Event) []Event {
+	if f.IsZero() {
+		return events
+	}
+	from, to, _ := parseTimeWindow(f.From, f.To)
+	kept := make([]Event, 0, len(events))
+	for _, event := range events {
+		if !f.match(event, from, to) {
+			continue
+		}
+		kept = append(kept, event)
+		if f.Limit > 0 && len(kept) == f.Limit {
+			break
+		}
+	}
+	return kept
+}
+
+// FilterTimeline 返回过滤后的时间链副本，不修改原时间链
+func (f *EventFilter) FilterTimeline(timeline *TimelineResponse) *TimelineResponse {
+	if timeline == nil || f.IsZero() {
+		return timeline
+	}
+	filtered := *timeline
+	filtered.Events = f.FilterEvents(timeline.Events)
+	return &filtered
+}
+
+// FilterGraph 基于时间链中满足条件的事件构建子图，返回副本，不修改原图谱
+// 保留由这些事件支撑的关系及其两端节点，以及名称或别名出现在这些事件的标题、地点、人物中的节点；
+// 没有关联事件的关系在两端节点都被保留时保留。timeline 为 nil 时无法判断节点与事件的关系，返回原图谱
+func (f *EventFilter) FilterGraph(graph *GraphResponse, timeline *TimelineResponse) *GraphResponse {
+	if graph == nil || timeline == nil || f.IsZero() {
+		return graph
+	}
+	events := f.FilterEvents(timeline.Events)
+	eventIDs := make(map[string]bool, len(events))
+	var mentions []string
+	for _, event := range events {
+		eventIDs[event.ID] = true
+		mentions = append(mentions, NormalizeKey(event.Title), NormalizeKey(event.Location))
+		for _, person := range event.People {
+			mentions = append(mentions, NormalizeKey(person))
+		}
+	}
+
+	keepNodes := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		for _, name := range append([]string{node.Name}, node.Aliases...) {
+			if key := NormalizeKey(name); key != "" && mentioned(mentions, key) {
+				keepNodes[node.ID] = true
+				break
+			}
+		}
+	}
+	supported := make(map[int]bool)
+	for i, link := range graph.Links {
+		for _, id := range link.EventIDs {
+			if eventIDs[id] {
+				supported[i] = true
+				keepNodes[link.Source] = true
+				keepNodes[link.Target] = true
+				break
+			}
+		}
+	}
+
+	sub := &GraphResponse{ID: graph.ID, Keyword: graph.Keyword, Nodes: []GraphNode{}, Links: []GraphLink{}}
+	for _, node := range graph.Nodes {
+		if keepNodes[node.ID] {
+			sub.Nodes = append(sub.Nodes, node)
+		}
+	}
+	for i, link := range graph.Links {
+		if supported[i] || len(link.EventIDs) == 0 && keepNodes[link.Source] && keepNodes[link.Target] {
+			sub.Links = append(sub.Links, link)
+		}
+	}
+	return sub
+}
+
+// mentioned 判断归一化后的名称是否出现在任一归一化文本中
+func mentioned(texts []string, key string) bool {
+	for _, text := range texts {
+		if strings.Contains(text, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTimeWindow 解析时间窗口的起止日期，未设置的一端为零值
+func parseTimeWindow(fromValue, toValue string) (from, to time.Time, err error) {
+	if fromValue != "" {
+		t := ParseEventTime(fromValue)
+		if !t.Valid {
+			return from, to, fmt.Errorf("无效的时间窗口起点: %s", fromValue)
+		}
+		from = t.Start
+	}
+	if toValue != "" {
+		t := ParseEventTime(toValue)
+		if !t.Valid {
+			return from, to, fmt.Errorf("无效的时间窗口终点: %s", toValue)
+		}
+		to = t.End
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return from, to, fmt.Errorf("时间窗口起点 %s 晚于终点 %s", fromValue, toValue)
+	}
+	return from, to, nil
+}
+
+// inWindow 判断事件时间是否与时间窗口有重叠
+func inWindow(t EventTime, from, to time.Time) bool {
+	if !t.Valid {
+		return false
+	}
+	return (from.IsZero() || !t.End.Before(from)) && (to.IsZero() || !t.Start.After(to))
+}
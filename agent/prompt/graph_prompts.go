@@ -34,6 +34,19 @@ const GraphGenerationSystemPrompt = `你是一个专业的知识图谱构建助
 3. 关系描述要具体，避免泛泛而谈
 4. 优先构建能够揭示事件本质和因果链条的关系
 
+节点与关系的属性要求：
+1. 每个节点尽量补充以下字段（无法确定时可省略）：
+   - description：一句话描述该节点在事件中的角色或意义
+   - aliases：常见别名、简称或译名（字符串数组）
+   - attributes：扩展属性（字符串键值对），如人物的职务、所属机构，地点的所属地区
+   - first_seen / last_seen：该节点在时间链中首次和最近出现的时间，格式与时间链的 time 字段一致
+   - importance：节点在整个事件链中的重要度，取值 0-1，核心节点接近 1
+2. 每条关系尽量补充以下字段：
+   - weight：关系强度，取值 0-1
+   - time：关系发生或成立的时间，格式与时间链的 time 字段一致
+   - directed：关系是否有方向（source 指向 target），如"导致"、"主导"为 true，"合作"、"对立"为 false
+   - event_ids：支撑该关系的时间链事件 id 数组，必须引用时间链中真实存在的事件 id
+
 质量标准：
 1. 图谱应能清晰呈现事件的核心脉络和关键节点
 2. 节点和关系的选择应体现出明确的优先级和层次感
@@ -46,15 +59,15 @@ const GraphGenerationSystemPrompt = `你是一个专业的知识图谱构建助
 {
   "keyword": "关键词",
   "nodes": [
-    {"id": "e1", "name": "标志性事件名称", "category": "核心事件"},
-    {"id": "p1", "name": "关键人物名称", "category": "关键人物"},
-    {"id": "l1", "name": "重要地点名称", "category": "重要地点"},
-    {"id": "t1", "name": "主要主题/概念", "category": "主要主题"}
+    {"id": "e1", "name": "标志性事件名称", "category": "核心事件", "description": "事件的一句话描述", "first_seen": "2023-01-10", "last_seen": "2023-03-05", "importance": 0.9},
+    {"id": "p1", "name": "关键人物名称", "category": "关键人物", "description": "人物在事件中的角色", "aliases": ["别名"], "attributes": {"职务": "董事长"}, "first_seen": "2023-01-10", "last_seen": "2023-05-20", "importance": 0.8},
+    {"id": "l1", "name": "重要地点名称", "category": "重要地点", "importance": 0.5},
+    {"id": "t1", "name": "主要主题/概念", "category": "主要主题", "description": "主题的一句话描述", "importance": 0.6}
   ],
   "links": [
-    {"source": "e1", "target": "p1", "relation": "由...主导"},
-    {"source": "e1", "target": "l1", "relation": "发生于"},
-    {"source": "e1", "target": "t1", "relation": "推动了"}
+    {"source": "e1", "target": "p1", "relation": "由...主导", "weight": 0.9, "time": "2023-01-10", "directed": true, "event_ids": ["1"]},
+    {"source": "e1", "target": "l1", "relation": "发生于", "weight": 0.6, "time": "2023-01-10", "directed": true, "event_ids": ["1"]},
+    {"source": "e1", "target": "t1", "relation": "推动了", "weight": 0.7, "time": "2023-03-05", "directed": true, "event_ids": ["1", "2"]}
   ]
 }`
 
@@ -74,7 +87,8 @@ const GraphRefinementSystemPrompt = `你是一个知识图谱的反思与优化
    - 删除信息含量低的关系（如泛泛的"相关"、"有关"）
    - 增加有意义的因果、参与、影响关系
    - 使关系描述更具体、更有信息量
-5. 合并明显重复或信息高度重合的节点和关系
+5. 合并明显重复或信息高度重合的节点和关系，合并时保留并合并它们的 aliases、attributes 和 event_ids
+6. 检查并补全节点的 description、aliases、attributes、first_seen、last_seen、importance 字段，以及关系的 weight、time、directed、event_ids 字段；event_ids 只能引用原有事件 id
 
 重点优化方向：
 1. 保留并强化：
@@ -94,10 +108,10 @@ const GraphRefinementSystemPrompt = `你是一个知识图谱的反思与优化
 {
   "keyword": "关键词",
   "nodes": [
-    {"id": "e1", "name": "核心事件名称", "category": "核心事件"},
-    {"id": "p1", "name": "关键人物名称", "category": "关键人物"}
+    {"id": "e1", "name": "核心事件名称", "category": "核心事件", "description": "事件的一句话描述", "first_seen": "2023-01-10", "last_seen": "2023-03-05", "importance": 0.9},
+    {"id": "p1", "name": "关键人物名称", "category": "关键人物", "description": "人物在事件中的角色", "aliases": ["别名"], "attributes": {"职务": "董事长"}, "first_seen": "2023-01-10", "last_seen": "2023-05-20", "importance": 0.8}
   ],
   "links": [
-    {"source": "e1", "target": "p1", "relation": "具体关系描述"}
+    {"source": "e1", "target": "p1", "relation": "具体关系描述", "weight": 0.9, "time": "2023-01-10", "directed": true, "event_ids": ["1"]}
   ]
 }`
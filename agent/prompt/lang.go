@@ -0,0 +1,63 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 支持的输出语言
+const (
+	LangZH = "zh"
+	LangEN = "en"
+)
+
+// SupportedLangs 有完整提示词译本的输出语言
+var SupportedLangs = []string{LangZH, LangEN}
+
+// langNames 语言代码对应的中文名称，用于提示词中描述目标语言；除 SupportedLangs 外的语言只能作为生成约束中的输出语言
+var langNames = map[string]string{
+	LangZH: "简体中文",
+	LangEN: "英文",
+	"ja":   "日文",
+	"ko":   "韩文",
+	"fr":   "法文",
+	"de":   "德文",
+	"es":   "西班牙文",
+	"ru":   "俄文",
+}
+
+// NormalizeLang 归一化语言代码，如 "en-US"、"EN" 归一化为 "en"，空字符串视为中文；不支持的语言返回错误
+func NormalizeLang(lang string) (string, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		return LangZH, nil
+	}
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	for _, supported := range SupportedLangs {
+		if lang == supported {
+			return lang, nil
+		}
+	}
+	return "", fmt.Errorf("不支持的语言: %s，可选: %s", lang, strings.Join(SupportedLangs, "、"))
+}
+
+// LangName 返回语言的中文名称，未知语言返回传入的值本身
+func LangName(lang string) string {
+	if name, ok := langNames[lang]; ok {
+		return name
+	}
+	return lang
+}
+
+// Localized 同一提示词的多语言版本，键为语言代码
+type Localized map[string]string
+
+// For 返回指定语言的提示词，没有该语言版本时返回中文版本
+func (l Localized) For(lang string) string {
+	if p, ok := l[lang]; ok {
+		return p
+	}
+	return l[LangZH]
+}
@@ -0,0 +1,348 @@
+// Package prompt 管理生成时间链、图谱、增量更新、翻译和问答使用的提示词
+//
+// 这些提示词可以由模板目录（默认 prompts）中的 Go text/template 文件覆盖，目录中没有的模板使用内置提示词。
+// 目前的限制：
+//   - 热加载按间隔轮询模板文件的大小和修改时间，不使用 fsnotify，修改后最多延迟一个轮询间隔生效；
+//   - 图片摘要提示词、追加在时间链提示词之后的生成约束和关键词澄清提示词仍为 Go 常量，不能通过模板目录修改；
+//   - 快速、深度搜索和均衡模式目前返回模拟数据（workflow.GenerateMockMode），不调用模型，没有对应的提示词。
+package prompt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"lineNews/agent/logutil"
+)
+
+// 可在模板目录中覆盖的提示词，文件名为 <名称>.tmpl（中文）或 <名称>.<语言>.tmpl，如 timeline_generation_system.en.tmpl
+const (
+	TimelineGenerationSystem = "timeline_generation_system"
+	TimelineGenerationUser   = "timeline_generation_user"
+	TimelineRefinementSystem = "timeline_refinement_system"
+	TimelineRefinementUser   = "timeline_refinement_user"
+	TimelineDocumentUser     = "timeline_document_user"
+	GraphGenerationSystem    = "graph_generation_system"
+	GraphGenerationUser      = "graph_generation_user"
+	GraphRefinementSystem    = "graph_refinement_system"
+	GraphRefinementUser      = "graph_refinement_user"
+	ArkTimelineSystem        = "ark_timeline_system"
+	ArkTimelineUser          = "ark_timeline_user"
+	TimelineUpdateSystem     = "timeline_update_system"
+	TimelineUpdateUser       = "timeline_update_user"
+	TranslationSystem        = "translation_system"
+	TopicQASystem            = "topic_qa_system"
+	TopicQAUser              = "topic_qa_user"
+)
+
+// BuiltinVersion 未配置模板目录或目录中没有模板时的版本标识
+const BuiltinVersion = "builtin"
+
+// versionFile 模板目录中记录版本号的文件，不存在时使用模板内容的摘要作为版本
+const versionFile = "VERSION"
+
+// builtins 内置提示词，模板目录中没有对应文件时使用
+var builtins = map[string]Localized{
+	TimelineGenerationSystem: {LangZH: TimelineGenerationSystemPrompt, LangEN: TimelineGenerationSystemPromptEN},
+	TimelineGenerationUser: {
+		LangZH: "请为关键词「{{.Keyword}}」生成新闻时间链",
+		LangEN: "Generate a news timeline for the keyword \"{{.Keyword}}\".",
+	},
+	TimelineRefinementSystem: {LangZH: TimelineRefinementSystemPrompt, LangEN: TimelineRefinementSystemPromptEN},
+	TimelineRefinementUser: {
+		LangZH: "下面是模型第一次为关键词「{{.Keyword}}」生成的时间链 JSON：\n{{.Input}}\n\n请在内部使用 ReAct 模式进行反思和推理，检查事件数量和内容是否满足上述要求，并在此基础上进行补充、合并和优化，生成最终的高质量时间链。请直接返回最终的 JSON，不要输出任何解释性文字。",
+		LangEN: "Below is the timeline JSON first generated for the keyword \"{{.Keyword}}\":\n{{.Input}}\n\nReflect and reason internally in the ReAct style, check whether the number and content of events meet the requirements above, then supplement, merge and refine them into the final high-quality timeline. Return only the final JSON without any explanation.",
+	},
+	TimelineDocumentUser: {
+		LangZH: "请根据下面用户提供的文档生成时间链。本次为文档抽取，以下要求优先于事件数量和内容覆盖方面的要求：\n" +
+			"1. 只抽取文档中明确记载的事件，不要补充文档以外的事实，不要求达到最少事件数；文档中没有可抽取的事件时返回空的 events 数组；\n" +
+			"2. {{if .Keyword}}只保留与关键词「{{.Keyword}}」相关的事件{{else}}在 keyword 字段中给出最能概括文档主题的关键词{{end}}；\n" +
+			"3. 文档只给出相对时间（如\"昨天\"、\"上周\"）时，结合文档发布时间换算为具体日期；无法确定时间的事件可以省略。\n\n" +
+			"{{.Input}}\n\n请直接返回 JSON。",
+		LangEN: "Generate a timeline from the documents provided by the user below. This is document extraction, and the following requirements take precedence over the requirements on the number and coverage of events:\n" +
+			"1. Only extract events explicitly recorded in the documents, do not add facts from outside the documents, and there is no minimum number of events; return an empty events array when there are no events to extract;\n" +
+			"2. {{if .Keyword}}Only keep events related to the keyword \"{{.Keyword}}\"{{else}}Give the keyword that best summarizes the documents in the keyword field{{end}};\n" +
+			"3. When a document only gives a relative time (such as \"yesterday\" or \"last week\"), convert it to a concrete date using the document's publish time; events whose time cannot be determined may be omitted.\n\n" +
+			"{{.Input}}\n\nReturn the JSON directly. Write titles, locations, people and summaries in English.",
+	},
+	GraphGenerationSystem: {LangZH: GraphGenerationSystemPrompt, LangEN: GraphGenerationSystemPromptEN},
+	GraphGenerationUser: {
+		LangZH: "请根据以下时间链构建知识图谱：\n{{.Input}}",
+		LangEN: "Build a knowledge graph from the following timeline:\n{{.Input}}",
+	},
+	GraphRefinementSystem: {LangZH: GraphRefinementSystemPrompt, LangEN: GraphRefinementSystemPromptEN},
+	GraphRefinementUser: {
+		LangZH: "下面是模型第一次为关键词「{{.Keyword}}」生成的知识图谱 JSON：\n{{.Input}}\n\n请在内部使用 ReAct 模式进行反思和推理，检查节点数量和内容是否满足上述要求，并在此基础上进行补充、合并和优化，生成最终的高质量知识图谱。请直接返回最终的 JSON，不要输出任何解释性文字。",
+		LangEN: "Below is the knowledge graph JSON first generated for the keyword \"{{.Keyword}}\":\n{{.Input}}\n\nReflect and reason internally in the ReAct style, check whether the number and content of nodes meet the requirements above, then supplement, merge and refine them into the final high-quality knowledge graph. Return only the final JSON without any explanation.",
+	},
+	ArkTimelineSystem: {
+		LangZH: "你是一个专业的新闻时间线生成助手。",
+		LangEN: "You are a professional assistant that builds news timelines. Always write in English.",
+	},
+	ArkTimelineUser: {
+		LangZH: "请为关键词 '{{.Keyword}}' 生成新闻时间线，返回有效的JSON格式结果，包含Keyword和Events字段。Events数组应包含至少5-10个独立的新闻事件，每个事件必须包含以下字段：ID（字符串类型，如\"1\", \"2\", \"3\"等）、Title（字符串，事件标题）、Time（字符串，具体时间如\"2024-01-15\"）、Location（字符串，地点）、People（字符串数组，涉及人物）、Summary（字符串，事件摘要）、Sources（数组，报道该事件的联网搜索结果，每项包含 Title、URL、SiteName、PublishTime，只能使用搜索结果中真实出现的链接，没有时为空数组）。确保时间线覆盖不同时间段，从早期到近期，每个事件都应有明确的时间、地点、人物和内容。",
+		LangEN: "Generate a news timeline for the keyword '{{.Keyword}}' and return a valid JSON result with the fields Keyword and Events. The Events array should contain at least 5-10 distinct news events, and every event must include: ID (string, such as \"1\", \"2\", \"3\"), Title (string, event title), Time (string, a concrete date such as \"2024-01-15\"), Location (string), People (array of strings, people involved) Summary (string, event summary) and Sources (array of the web search results reporting the event, each with Title, URL, SiteName and PublishTime; only use links that actually appear in the search results, or an empty array if there are none). Cover different periods from early to recent; every event needs a clear time, place, people and content. Write titles, locations, people and summaries in English.",
+	},
+	TimelineUpdateSystem: {LangZH: TimelineUpdateSystemPrompt, LangEN: TimelineUpdateSystemPromptEN},
+	TimelineUpdateUser:   {LangZH: TimelineUpdateUserPrompt, LangEN: TimelineUpdateUserPromptEN},
+	TranslationSystem:    {LangZH: TranslationSystemPrompt, LangEN: TranslationSystemPromptEN},
+	TopicQASystem:        {LangZH: TopicQASystemPrompt, LangEN: TopicQASystemPromptEN},
+	TopicQAUser:          {LangZH: TopicQAUserPrompt, LangEN: TopicQAUserPromptEN},
+}
+
+// Vars 渲染提示词模板时可用的变量
+type Vars struct {
+	Keyword  string // 专题关键词
+	Lang     string // 输出语言代码，如 zh、en
+	LangName string // 输出语言的中文名称，如 英文
+	Input    string // 待优化的时间链或图谱 JSON，生成图谱时为时间链 JSON，增量更新时为已有近期事件，问答时为专题资料
+	Since    string // 增量更新的起始日期
+	Question string // 问答的用户问题
+}
+
+// TemplateInfo 模板列表中的一项
+type TemplateInfo struct {
+	Name   string `json:"name"`
+	Lang   string `json:"lang"`
+	Source string `json:"source"` // 模板文件路径，内置提示词为 builtin
+}
+
+// Set 一组已解析的提示词模板，加载后不再修改，可以并发使用
+type Set struct {
+	Version  string    `json:"version"`
+	Dir      string    `json:"dir,omitempty"`
+	LoadedAt time.Time `json:"loaded_at"`
+
+	templates map[string]*template.Template // 键为 名称.语言
+	sources   map[string]string
+}
+
+// current 当前使用的模板集合，热加载时整体替换
+var current atomic.Pointer[Set]
+
+func init() {
+	set, err := newSet("", nil)
+	if err != nil {
+		panic(fmt.Sprintf("解析内置提示词失败: %v", err))
+	}
+	current.Store(set)
+}
+
+// Current 返回当前的模板集合；一次生成过程应只获取一次，保证各轮调用使用同一版本的提示词
+func Current() *Set {
+	return current.Load()
+}
+
+// setKey 模板集合在 context 中的键
+type setKey struct{}
+
+// WithSet 返回指定模板集合的 context，用于实验中让一次生成使用变体的提示词
+func WithSet(ctx context.Context, set *Set) context.Context {
+	return context.WithValue(ctx, setKey{}, set)
+}
+
+// FromContext 返回 context 中指定的模板集合，没有时返回当前的模板集合
+func FromContext(ctx context.Context) *Set {
+	if set, ok := ctx.Value(setKey{}).(*Set); ok && set != nil {
+		return set
+	}
+	return Current()
+}
+
+// Render 渲染指定语言的提示词，lang 为空或没有该语言的模板时使用中文模板
+func (s *Set) Render(name, lang string, vars Vars) (string, error) {
+	if lang == "" {
+		lang = LangZH
+	}
+	t, ok := s.templates[name+"."+lang]
+	if !ok {
+		t, ok = s.templates[name+"."+LangZH]
+	}
+	if !ok {
+		return "", fmt.Errorf("提示词模板不存在: %s", name)
+	}
+	if vars.Lang == "" {
+		vars.Lang = lang
+	}
+	if vars.LangName == "" {
+		vars.LangName = LangName(vars.Lang)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("渲染提示词模板 %s 失败: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// Templates 列出所有模板及其来源，按名称和语言排序
+func (s *Set) Templates() []TemplateInfo {
+	infos := make([]TemplateInfo, 0, len(s.sources))
+	for key, source := range s.sources {
+		name, lang, _ := strings.Cut(key, ".")
+		infos = append(infos, TemplateInfo{Name: name, Lang: lang, Source: source})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Name != infos[j].Name {
+			return infos[i].Name < infos[j].Name
+		}
+		return infos[i].Lang < infos[j].Lang
+	})
+	return infos
+}
+
+// Load 从模板目录加载提示词，目录中没有的模板使用内置提示词；dir 为空时只使用内置提示词
+// 任一模板解析或试渲染失败时返回错误，调用方应继续使用之前的模板集合
+func Load(dir string) (*Set, error) {
+	if dir == "" {
+		return newSet("", nil)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取提示词模板目录失败: %w", err)
+	}
+
+	files := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tmpl") {
+			continue
+		}
+		name, lang := strings.TrimSuffix(entry.Name(), ".tmpl"), LangZH
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name, lang = name[:i], name[i+1:]
+		}
+		if _, ok := builtins[name]; !ok {
+			logutil.LogInfo("忽略未知的提示词模板: %s", entry.Name())
+			continue
+		}
+		normalized, err := NormalizeLang(lang)
+		if err != nil {
+			logutil.LogInfo("忽略不支持语言的提示词模板: %s", entry.Name())
+			continue
+		}
+		files[name+"."+normalized] = filepath.Join(dir, entry.Name())
+	}
+	return newSet(dir, files)
+}
+
+// newSet 解析内置提示词和模板文件，files 的键为 名称.语言，值为文件路径
+func newSet(dir string, files map[string]string) (*Set, error) {
+	set := &Set{
+		Version:   BuiltinVersion,
+		Dir:       dir,
+		LoadedAt:  time.Now(),
+		templates: make(map[string]*template.Template),
+		sources:   make(map[string]string),
+	}
+	for name, localized := range builtins {
+		for lang, text := range localized {
+			key := name + "." + lang
+			t, err := template.New(key).Option("missingkey=error").Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("解析内置提示词 %s 失败: %w", key, err)
+			}
+			set.templates[key] = t
+			set.sources[key] = BuiltinVersion
+		}
+	}
+	if len(files) == 0 {
+		return set, nil
+	}
+
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, key := range keys {
+		content, err := os.ReadFile(files[key])
+		if err != nil {
+			return nil, fmt.Errorf("读取提示词模板 %s 失败: %w", files[key], err)
+		}
+		t, err := template.New(key).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("解析提示词模板 %s 失败: %w", files[key], err)
+		}
+		// 试渲染一次，提前发现引用了不存在变量的模板
+		if err := t.Execute(&strings.Builder{}, Vars{Keyword: "示例", Lang: LangZH, LangName: LangName(LangZH), Input: "{}", Since: "2024-01-01", Question: "示例"}); err != nil {
+			return nil, fmt.Errorf("试渲染提示词模板 %s 失败: %w", files[key], err)
+		}
+		set.templates[key] = t
+		set.sources[key] = files[key]
+		fmt.Fprintf(hash, "%s\n%s\n", key, content)
+	}
+
+	set.Version = "sha256:" + hex.EncodeToString(hash.Sum(nil))[:12]
+	if content, err := os.ReadFile(filepath.Join(dir, versionFile)); err == nil {
+		if version := strings.TrimSpace(string(content)); version != "" {
+			set.Version = version
+		}
+	}
+	return set, nil
+}
+
+// Reload 重新加载模板目录，成功后替换当前的模板集合，失败时保留之前的模板集合
+func Reload(dir string) (*Set, error) {
+	set, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	previous := current.Swap(set)
+	if previous == nil || previous.Version != set.Version {
+		logutil.LogInfo("提示词模板已加载: %s (版本: %s)", dir, set.Version)
+	}
+	return set, nil
+}
+
+// Watch 每隔 interval 检查一次模板目录中文件的修改时间和大小，有变化时重新加载，直到 ctx 结束
+func Watch(ctx context.Context, dir string, interval time.Duration) {
+	if dir == "" || interval <= 0 {
+		return
+	}
+	last := fingerprint(dir)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		fp := fingerprint(dir)
+		if fp == last {
+			continue
+		}
+		last = fp
+		if _, err := Reload(dir); err != nil {
+			logutil.LogError("提示词模板热加载失败，继续使用版本 %s: %v", Current().Version, err)
+		}
+	}
+}
+
+// fingerprint 模板目录中模板文件和版本文件的名称、大小和修改时间，用于判断目录是否变化
+func fingerprint(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tmpl") && entry.Name() != versionFile {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "%s|%d|%d\n", entry.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String()
+}
@@ -109,3 +109,17 @@ const KeywordClarificationSystemPrompt = `你是一个专业的关键词提取
    - "description": 关键词的简要描述
    - "processing_direction": 为后续处理提供的方向建议
 2. 只输出JSON格式内容，不要包含其他文字说明。`
+
+// 时间链生成约束，追加在初次生成和反思优化的用户提示词之后
+const (
+	// TimelineConstraintsHeader 约束部分的标题
+	TimelineConstraintsHeader = "\n\n生成约束（必须严格遵守）："
+	// TimelineMustIncludeTemplate 必须包含的事件，填入逐行列出的事件
+	TimelineMustIncludeTemplate = "\n- 时间链中必须包含以下事件，保持其时间和标题不变，可以补充摘要：\n%s"
+	// TimelineExcludeTemplate 排除的子话题，填入以顿号分隔的子话题
+	TimelineExcludeTemplate = "\n- 不要包含与以下子话题相关的事件：%s"
+	// TimelineWindowTemplate 时间窗口，填入起止时间描述
+	TimelineWindowTemplate = "\n- 只包含发生在 %s 至 %s 之间的事件，窗口之外的事件一律不要返回"
+	// TimelineLanguageTemplate 输出语言，填入语言名称
+	TimelineLanguageTemplate = "\n- 事件的 title、location、people 和 summary 使用%s输出，JSON 字段名保持不变"
+)
@@ -0,0 +1,350 @@
+package qa
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"lineNews/agent"
+	"lineNews/agent/chat"
+	"lineNews/agent/prompt"
+)
+
+// 检索默认参数
+const (
+	DefaultMaxEvents = 12
+	maxContextNodes  = 30
+)
+
+// Context 检索出的问答上下文
+type Context struct {
+	Events []agent.Event
+	Nodes  []agent.GraphNode
+	Links  []agent.GraphLink
+}
+
+// Citation 回答中引用的事件
+type Citation struct {
+	EventID string              `json:"event_id"`
+	Title   string              `json:"title"`
+	Time    string              `json:"time"`
+	Sources []agent.EventSource `json:"sources,omitempty"`
+}
+
+// Answer 问答结果
+type Answer struct {
+	Question         string     `json:"question"`
+	Answer           string     `json:"answer"`
+	Provider         string     `json:"provider"`
+	Citations        []Citation `json:"citations"`
+	ContextEvents    int        `json:"context_events"`
+	ContextNodes     int        `json:"context_nodes"`
+	PromptTokens     int        `json:"prompt_tokens"`
+	CompletionTokens int        `json:"completion_tokens"`
+	TotalTokens      int        `json:"total_tokens"`
+	PromptVersion    string     `json:"prompt_version,omitempty"` // 回答时使用的提示词模板版本
+}
+
+// Ask 检索专题记录中与问题相关的事件和实体，仅以这些资料作为上下文调用模型回答
+func Ask(ctx context.Context, provider string, record *agent.TimelineRecord, question string, maxEvents int) (*Answer, error) {
+	if record.Timeline == nil || len(record.Timeline.Events) == 0 {
+		return nil, fmt.Errorf("专题记录中没有可用的事件")
+	}
+
+	retrieved := Retrieve(record, question, maxEvents)
+	// 使用专题时间链的语言回答
+	prompts := prompt.FromContext(ctx)
+	lang := record.Lang()
+	systemPrompt, err := prompts.Render(prompt.TopicQASystem, lang, prompt.Vars{Keyword: record.Keyword})
+	if err != nil {
+		return nil, err
+	}
+	userPrompt, err := prompts.Render(prompt.TopicQAUser, lang, prompt.Vars{
+		Keyword:  record.Keyword,
+		Input:    FormatContext(retrieved),
+		Question: question,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := chat.Complete(ctx, provider, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("生成回答失败: %w", err)
+	}
+
+	return &Answer{
+		Question:         question,
+		Answer:           reply.Content,
+		Provider:         reply.Provider,
+		Citations:        ExtractCitations(reply.Content, record.Timeline),
+		ContextEvents:    len(retrieved.Events),
+		ContextNodes:     len(retrieved.Nodes),
+		PromptTokens:     reply.PromptTokens,
+		CompletionTokens: reply.CompletionTokens,
+		TotalTokens:      reply.TotalTokens,
+		PromptVersion:    prompts.Version,
+	}, nil
+}
+
+// Retrieve 按问题与事件的词项重合度选取相关事件，并补充问题或事件中提到的实体及其关系
+// 没有任何事件命中时退化为时间链的前 maxEvents 个事件
+func Retrieve(record *agent.TimelineRecord, question string, maxEvents int) *Context {
+	if maxEvents <= 0 {
+		maxEvents = DefaultMaxEvents
+	}
+	result := &Context{}
+	if record.Timeline == nil {
+		return result
+	}
+	events := record.Timeline.Events
+	queryTerms := terms(question)
+
+	// 问题中直接提到的实体名称，命中这些实体的事件额外加分
+	var mentioned []string
+	if record.Graph != nil {
+		for _, node := range record.Graph.Nodes {
+			if node.Name != "" && strings.Contains(question, node.Name) {
+				mentioned = append(mentioned, node.Name)
+			}
+		}
+	}
+
+	type scored struct {
+		index int
+		score float64
+	}
+	var candidates []scored
+	for i, event := range events {
+		score := 0.0
+		text := eventText(event)
+		titleTerms := terms(event.Title)
+		bodyTerms := terms(text)
+		for term := range queryTerms {
+			if titleTerms[term] {
+				score += 2
+			} else if bodyTerms[term] {
+				score++
+			}
+		}
+		for _, name := range mentioned {
+			if strings.Contains(text, name) {
+				score += 3
+			}
+		}
+		if score > 0 {
+			candidates = append(candidates, scored{index: i, score: score})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	if len(candidates) > maxEvents {
+		candidates = candidates[:maxEvents]
+	}
+
+	var selected []int
+	for _, candidate := range candidates {
+		selected = append(selected, candidate.index)
+	}
+	if len(selected) == 0 {
+		for i := 0; i < len(events) && i < maxEvents; i++ {
+			selected = append(selected, i)
+		}
+	}
+	// 恢复时间链中的原始顺序，便于模型梳理因果
+	sort.Ints(selected)
+
+	selectedIDs := make(map[string]bool, len(selected))
+	var selectedText strings.Builder
+	for _, i := range selected {
+		result.Events = append(result.Events, events[i])
+		selectedIDs[events[i].ID] = true
+		selectedText.WriteString(eventText(events[i]))
+	}
+
+	if record.Graph == nil {
+		return result
+	}
+
+	nodeIDs := make(map[string]bool)
+	for _, node := range record.Graph.Nodes {
+		if len(result.Nodes) >= maxContextNodes {
+			break
+		}
+		if node.Name == "" {
+			continue
+		}
+		if strings.Contains(question, node.Name) || strings.Contains(selectedText.String(), node.Name) {
+			result.Nodes = append(result.Nodes, node)
+			nodeIDs[node.ID] = true
+		}
+	}
+	endpoints := make(map[string]bool)
+	for _, link := range record.Graph.Links {
+		if nodeIDs[link.Source] && nodeIDs[link.Target] || sharesEvent(link.EventIDs, selectedIDs) {
+			result.Links = append(result.Links, link)
+			endpoints[link.Source] = true
+			endpoints[link.Target] = true
+		}
+	}
+	// 补充关系另一端的实体，保证关系描述完整
+	for _, node := range record.Graph.Nodes {
+		if endpoints[node.ID] && !nodeIDs[node.ID] {
+			result.Nodes = append(result.Nodes, node)
+			nodeIDs[node.ID] = true
+		}
+	}
+	return result
+}
+
+// FormatContext 将检索结果格式化为提示词中的专题资料
+func FormatContext(c *Context) string {
+	var b strings.Builder
+
+	b.WriteString("【事件】\n")
+	for _, event := range c.Events {
+		fmt.Fprintf(&b, "事件ID: %s | 时间: %s | 标题: %s", event.ID, event.Time, event.Title)
+		if event.Location != "" {
+			fmt.Fprintf(&b, " | 地点: %s", event.Location)
+		}
+		if len(event.People) > 0 {
+			fmt.Fprintf(&b, " | 人物: %s", strings.Join(event.People, "、"))
+		}
+		fmt.Fprintf(&b, "\n  摘要: %s\n", event.Summary)
+		for _, source := range event.Sources {
+			fmt.Fprintf(&b, "  来源: %s %s\n", source.Title, source.URL)
+		}
+	}
+
+	if len(c.Nodes) > 0 {
+		names := make(map[string]string, len(c.Nodes))
+		b.WriteString("\n【实体】\n")
+		for _, node := range c.Nodes {
+			names[node.ID] = node.Name
+			fmt.Fprintf(&b, "- %s（%s）", node.Name, node.Category)
+			if node.Description != "" {
+				fmt.Fprintf(&b, "：%s", node.Description)
+			} else if node.Baike != nil && node.Baike.LemmaDesc != "" {
+				fmt.Fprintf(&b, "：%s", node.Baike.LemmaDesc)
+			}
+			b.WriteString("\n")
+		}
+
+		if len(c.Links) > 0 {
+			b.WriteString("\n【关系】\n")
+			for _, link := range c.Links {
+				source, target := names[link.Source], names[link.Target]
+				if source == "" {
+					source = link.Source
+				}
+				if target == "" {
+					target = link.Target
+				}
+				fmt.Fprintf(&b, "- %s -[%s]-> %s", source, link.Relation, target)
+				if link.Time != "" {
+					fmt.Fprintf(&b, "（%s）", link.Time)
+				}
+				if len(link.EventIDs) > 0 {
+					fmt.Fprintf(&b, " 相关事件: %s", strings.Join(link.EventIDs, ","))
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// citationPattern 匹配回答中的方括号引用
+var citationPattern = regexp.MustCompile(`\[([^\[\]]{1,40})\]`)
+
+// ExtractCitations 从回答中解析 [事件ID] 引用，只保留时间链中存在的事件，按首次出现顺序去重
+func ExtractCitations(answer string, timeline *agent.TimelineResponse) []Citation {
+	citations := []Citation{}
+	seen := make(map[string]bool)
+	for _, match := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		for _, token := range strings.FieldsFunc(match[1], func(r rune) bool {
+			return r == ',' || r == '，' || r == '、' || unicode.IsSpace(r)
+		}) {
+			id := strings.TrimPrefix(strings.TrimPrefix(token, "事件"), "ID")
+			id = strings.TrimSpace(strings.TrimPrefix(id, ":"))
+			if id == "" || seen[id] {
+				continue
+			}
+			event, ok := timeline.FindEvent(id)
+			if !ok {
+				continue
+			}
+			seen[id] = true
+			citations = append(citations, Citation{
+				EventID: event.ID,
+				Title:   event.Title,
+				Time:    event.Time,
+				Sources: event.Sources,
+			})
+		}
+	}
+	return citations
+}
+
+// eventText 拼接事件的可检索文本
+func eventText(event agent.Event) string {
+	return strings.Join([]string{event.Time, event.Title, event.Location, strings.Join(event.People, " "), event.Summary}, " ")
+}
+
+// stopTerms 疑问句中常见但不携带主题信息的二元组
+var stopTerms = map[string]bool{
+	"为什": true, "什么": true, "怎么": true, "如何": true, "哪些": true, "是否": true,
+	"之后": true, "之前": true, "发生": true, "原因": true, "为何": true, "请问": true,
+}
+
+// terms 将文本切分为检索词项：汉字取相邻二元组，字母数字取完整单词（转小写）
+func terms(text string) map[string]bool {
+	result := make(map[string]bool)
+	var han []rune
+	var word []rune
+	flushHan := func() {
+		for i := 0; i+1 < len(han); i++ {
+			if term := string(han[i : i+2]); !stopTerms[term] {
+				result[term] = true
+			}
+		}
+		han = han[:0]
+	}
+	flushWord := func() {
+		if len(word) >= 2 {
+			result[strings.ToLower(string(word))] = true
+		}
+		word = word[:0]
+	}
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushHan()
+			flushWord()
+		}
+	}
+	flushHan()
+	flushWord()
+	return result
+}
+
+// sharesEvent 判断关系是否关联到已选事件
+func sharesEvent(eventIDs []string, selected map[string]bool) bool {
+	for _, id := range eventIDs {
+		if selected[id] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package agent
+
+import "time"
+
+// TimelineRecord 已保存的专题记录，包含同一关键词生成的时间链和知识图谱
+type TimelineRecord struct {
+	ID           string                  `json:"id"`
+	Keyword      string                  `json:"keyword"`
+	Mode         string                  `json:"mode"`
+	Timeline     *TimelineResponse       `json:"timeline,omitempty"`
+	Graph        *GraphResponse          `json:"graph,omitempty"`
+	Version      int                     `json:"version"`                // 当前版本号，历史版本见 TimelineVersion
+	Edits        []Edit                  `json:"edits,omitempty"`        // 人工编辑记录
+	Tombstones   []Tombstone             `json:"tombstones,omitempty"`   // 人工删除的条目，重新生成和增量更新时不再加回
+	Translations map[string]*Translation `json:"translations,omitempty"` // 按语言保存的译文
+	CreatedAt    time.Time               `json:"created_at"`
+	UpdatedAt    time.Time               `json:"updated_at"`
+}
+
+// NewTimelineRecord 创建专题记录，并将记录 ID 写入时间链和图谱
+func NewTimelineRecord(id, keyword, mode string, timeline *TimelineResponse, graph *GraphResponse) *TimelineRecord {
+	now := time.Now()
+	record := &TimelineRecord{
+		ID:        id,
+		Keyword:   keyword,
+		Mode:      mode,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	record.SetTimeline(timeline)
+	record.SetGraph(graph)
+	return record
+}
+
+// SetTimeline 更新记录中的时间链
+func (r *TimelineRecord) SetTimeline(timeline *TimelineResponse) {
+	if timeline != nil {
+		timeline.ID = r.ID
+	}
+	r.Timeline = timeline
+	r.UpdatedAt = time.Now()
+}
+
+// SetGraph 更新记录中的知识图谱
+func (r *TimelineRecord) SetGraph(graph *GraphResponse) {
+	if graph != nil {
+		graph.ID = r.ID
+	}
+	r.Graph = graph
+	r.UpdatedAt = time.Now()
+}
+
+// FindEvent 按 ID 查找事件
+func (t *TimelineResponse) FindEvent(id string) (*Event, bool) {
+	for i := range t.Events {
+		if t.Events[i].ID == id {
+			return &t.Events[i], true
+		}
+	}
+	return nil, false
+}
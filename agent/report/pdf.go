@@ -0,0 +1,332 @@
+package report
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"lineNews/agent"
+)
+
+// PDF 页面尺寸（A4，单位 pt）与版式
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+	pdfMargin     = 50.0
+	pdfBodyWidth  = pdfPageWidth - 2*pdfMargin
+)
+
+// pdfColor RGB 颜色，分量取值 0-1
+type pdfColor [3]float64
+
+var (
+	pdfBlack = pdfColor{0.2, 0.2, 0.2}
+	pdfGray  = pdfColor{0.55, 0.55, 0.55}
+	pdfBlue  = pdfColor{0.33, 0.44, 0.78}
+)
+
+// pdfDocument 极简 PDF 文档，使用 Adobe 标准中文字体 STSong-Light（阅读器内置，无需嵌入字体）
+type pdfDocument struct {
+	pages   []*bytes.Buffer
+	current *bytes.Buffer
+	y       float64
+}
+
+// renderPDF 将报告数据渲染为 PDF
+func renderPDF(w io.Writer, data *Data) error {
+	doc := &pdfDocument{}
+	doc.newPage()
+
+	doc.paragraph(data.Brand, 10, 0, pdfBlue)
+	doc.paragraph(data.Keyword+" 专题简报", 20, 0, pdfBlack)
+	doc.paragraph("生成时间："+data.GeneratedAt.Format("2006-01-02 15:04"), 9, 0, pdfGray)
+	doc.space(10)
+
+	if data.Timeline != nil {
+		doc.heading("事件时间线")
+		for _, event := range data.Timeline.Events {
+			doc.ensure(60)
+			doc.paragraph(strings.TrimSpace(event.Time+"  "+event.Title), 11, 0, pdfBlue)
+			var meta []string
+			if event.Location != "" {
+				meta = append(meta, event.Location)
+			}
+			if len(event.People) > 0 {
+				meta = append(meta, strings.Join(event.People, "、"))
+			}
+			if len(meta) > 0 {
+				doc.paragraph(strings.Join(meta, " · "), 9, 12, pdfGray)
+			}
+			if event.Summary != "" {
+				doc.paragraph(event.Summary, 10, 12, pdfBlack)
+			}
+			for _, source := range event.Sources {
+				doc.paragraph("来源："+source.Label()+" "+source.URL, 8, 12, pdfGray)
+			}
+			doc.space(6)
+		}
+	}
+
+	if data.Graph != nil && len(data.Graph.Nodes) > 0 {
+		doc.newPage()
+		doc.heading("知识图谱")
+		doc.graph(data.Graph, 380)
+	}
+
+	if len(data.Entities) > 0 {
+		doc.heading("关键实体")
+		for _, entity := range data.Entities {
+			doc.ensure(50)
+			title := entity.Name + "（" + entity.Category + "）"
+			if entity.Description != "" {
+				title += " " + entity.Description
+			}
+			doc.paragraph(title, 11, 0, pdfBlue)
+			if entity.Summary != "" {
+				doc.paragraph(truncateRunes(entity.Summary, 200), 9, 12, pdfBlack)
+			}
+			if entity.URL != "" {
+				doc.paragraph(entity.URL, 8, 12, pdfGray)
+			}
+			doc.space(4)
+		}
+	}
+
+	if len(data.Sources) > 0 {
+		doc.heading("信息来源")
+		for i, source := range data.Sources {
+			doc.paragraph(fmt.Sprintf("%d. %s %s", i+1, source.Label(), source.URL), 9, 0, pdfBlack)
+		}
+	}
+
+	return doc.write(w)
+}
+
+// newPage 新建页面
+func (d *pdfDocument) newPage() {
+	d.current = &bytes.Buffer{}
+	d.pages = append(d.pages, d.current)
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// ensure 剩余空间不足 height 时换页
+func (d *pdfDocument) ensure(height float64) {
+	if d.y-height < pdfMargin {
+		d.newPage()
+	}
+}
+
+// space 增加垂直间距
+func (d *pdfDocument) space(height float64) {
+	d.y -= height
+}
+
+// heading 输出章节标题
+func (d *pdfDocument) heading(text string) {
+	d.ensure(40)
+	d.space(10)
+	d.paragraph(text, 14, 0, pdfBlack)
+	fmt.Fprintf(d.current, "%s RG 1 w %.2f %.2f m %.2f %.2f l S\n", d.colorOp(pdfBlue), pdfMargin, d.y+4, pdfPageWidth-pdfMargin, d.y+4)
+	d.space(8)
+}
+
+// paragraph 输出自动换行的段落
+func (d *pdfDocument) paragraph(text string, size, indent float64, color pdfColor) {
+	lineHeight := size * 1.5
+	for _, line := range wrapText(text, size, pdfBodyWidth-indent) {
+		d.ensure(lineHeight)
+		d.y -= lineHeight
+		d.text(pdfMargin+indent, d.y, size, line, color)
+	}
+}
+
+// text 在指定位置输出单行文本
+func (d *pdfDocument) text(x, y, size float64, text string, color pdfColor) {
+	fmt.Fprintf(d.current, "BT %s rg /F1 %.1f Tf %.2f %.2f Td <%s> Tj ET\n", d.colorOp(color), size, x, y, encodeUCS2(text))
+}
+
+// graph 以矢量方式绘制知识图谱
+func (d *pdfDocument) graph(graph *agent.GraphResponse, height float64) {
+	d.ensure(height)
+	top := d.y
+	layout := layoutGraph(graph, pdfBodyWidth, height)
+	// 布局坐标原点在左上角，转换为 PDF 坐标
+	px := func(x float64) float64 { return pdfMargin + x }
+	py := func(y float64) float64 { return top - y }
+
+	fmt.Fprintf(d.current, "0.75 0.75 0.75 RG 0.6 w\n")
+	for _, link := range graph.Links {
+		si, ok1 := layout.Index[link.Source]
+		ti, ok2 := layout.Index[link.Target]
+		if !ok1 || !ok2 {
+			continue
+		}
+		s, t := layout.Nodes[si], layout.Nodes[ti]
+		fmt.Fprintf(d.current, "%.2f %.2f m %.2f %.2f l S\n", px(s.X), py(s.Y), px(t.X), py(t.Y))
+	}
+
+	for _, n := range layout.Nodes {
+		d.circle(px(n.X), py(n.Y), n.Radius*0.7, parseHexColor(n.Color))
+		size := 7.0
+		x := px(n.X) + n.Radius*0.7 + 2
+		if n.X < pdfBodyWidth/2 {
+			x = px(n.X) - n.Radius*0.7 - 2 - textWidth(n.Node.Name, size)
+		}
+		d.text(x, py(n.Y)-size/3, size, n.Node.Name, pdfBlack)
+	}
+
+	// 图例
+	for i, category := range layout.Categories {
+		y := top - 10 - float64(i)*12
+		d.circle(pdfMargin+4, y, 3.5, parseHexColor(layout.Colors[category]))
+		d.text(pdfMargin+12, y-2.5, 8, category, pdfGray)
+	}
+
+	d.y = top - height
+}
+
+// circle 使用贝塞尔曲线绘制实心圆
+func (d *pdfDocument) circle(cx, cy, r float64, color pdfColor) {
+	k := 0.5523 * r
+	fmt.Fprintf(d.current, "%s rg %.2f %.2f m ", d.colorOp(color), cx+r, cy)
+	fmt.Fprintf(d.current, "%.2f %.2f %.2f %.2f %.2f %.2f c ", cx+r, cy+k, cx+k, cy+r, cx, cy+r)
+	fmt.Fprintf(d.current, "%.2f %.2f %.2f %.2f %.2f %.2f c ", cx-k, cy+r, cx-r, cy+k, cx-r, cy)
+	fmt.Fprintf(d.current, "%.2f %.2f %.2f %.2f %.2f %.2f c ", cx-r, cy-k, cx-k, cy-r, cx, cy-r)
+	fmt.Fprintf(d.current, "%.2f %.2f %.2f %.2f %.2f %.2f c f\n", cx+k, cy-r, cx+r, cy-k, cx+r, cy)
+}
+
+// colorOp 返回颜色分量字符串
+func (d *pdfDocument) colorOp(color pdfColor) string {
+	return fmt.Sprintf("%.2f %.2f %.2f", color[0], color[1], color[2])
+}
+
+// write 输出完整的 PDF 文件
+func (d *pdfDocument) write(w io.Writer) error {
+	var buf bytes.Buffer
+	var offsets []int
+	addObject := func(body string) int {
+		offsets = append(offsets, buf.Len())
+		id := len(offsets)
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", id, body)
+		return id
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1: Catalog，2: Pages（页对象生成后再回填），3-5: 字体
+	addObject("<< /Type /Catalog /Pages 2 0 R >>")
+	pagesOffset := len(offsets)
+	offsets = append(offsets, 0)
+	addObject("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>")
+	addObject("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light /CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> /FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>")
+	addObject("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+
+	var kids []string
+	for _, page := range d.pages {
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return fmt.Errorf("压缩 PDF 内容失败: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("压缩 PDF 内容失败: %w", err)
+		}
+		contentID := addObject(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.String()))
+		pageID := addObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, contentID))
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+	}
+
+	offsets[pagesOffset] = buf.Len()
+	fmt.Fprintf(&buf, "2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(kids))
+
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("写入 PDF 失败: %w", err)
+	}
+	return nil
+}
+
+// encodeUCS2 将文本编码为 UCS-2 大端十六进制串，超出基本平面的字符替换为问号
+func encodeUCS2(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if r > 0xFFFF || r < 0x20 {
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+// runeWidth 估算字符宽度：ASCII 为半角，其余为全角
+func runeWidth(r rune, size float64) float64 {
+	if r < 0x80 {
+		return size * 0.5
+	}
+	return size
+}
+
+// textWidth 估算文本宽度
+func textWidth(text string, size float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		width += runeWidth(r, size)
+	}
+	return width
+}
+
+// wrapText 按宽度将文本拆分为多行
+func wrapText(text string, size, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		var line []rune
+		lineWidth := 0.0
+		for _, r := range paragraph {
+			w := runeWidth(r, size)
+			if lineWidth+w > width && len(line) > 0 {
+				lines = append(lines, string(line))
+				line, lineWidth = nil, 0
+			}
+			line = append(line, r)
+			lineWidth += w
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// truncateRunes 按字符数截断文本
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
+
+// parseHexColor 解析 #rrggbb 颜色
+func parseHexColor(hex string) pdfColor {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return pdfGray
+	}
+	var color pdfColor
+	for i := 0; i < 3; i++ {
+		v, err := strconv.ParseUint(hex[i*2:i*2+2], 16, 8)
+		if err != nil {
+			return pdfGray
+		}
+		color[i] = math.Round(float64(v)/255*100) / 100
+	}
+	return color
+}
@@ -0,0 +1,190 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"lineNews/agent"
+	"lineNews/agent/analysis"
+)
+
+// TemplateName 报告模板文件名
+const TemplateName = "report.html"
+
+//go:embed templates/report.html
+var defaultTemplates embed.FS
+
+// EntitySummary 关键实体的百科摘要
+type EntitySummary struct {
+	ID          string
+	Name        string
+	Category    string
+	Description string
+	Summary     string
+	PicURL      string
+	URL         string
+}
+
+// Data 报告渲染数据
+type Data struct {
+	Brand       string
+	Keyword     string
+	GeneratedAt time.Time
+	Timeline    *agent.TimelineResponse
+	Graph       *agent.GraphResponse
+	GraphSVG    template.HTML
+	Entities    []EntitySummary
+	Sources     []agent.EventSource
+}
+
+// NewData 根据时间链和知识图谱构建报告数据，maxEntities 为关键实体数量上限
+func NewData(brand string, timeline *agent.TimelineResponse, graph *agent.GraphResponse, maxEntities int) *Data {
+	data := &Data{
+		Brand:       brand,
+		GeneratedAt: time.Now(),
+		Timeline:    timeline,
+		Graph:       graph,
+	}
+	if data.Brand == "" {
+		data.Brand = "LineNews"
+	}
+	if timeline != nil {
+		data.Keyword = timeline.Keyword
+		data.Sources = collectSources(timeline.Events)
+	}
+	if graph != nil {
+		if data.Keyword == "" {
+			data.Keyword = graph.Keyword
+		}
+		data.GraphSVG = template.HTML(RenderGraphSVG(graph, 900, 600))
+		data.Entities = keyEntities(graph, maxEntities)
+	}
+	return data
+}
+
+// Renderer 报告渲染器
+type Renderer struct {
+	templateDir string
+}
+
+// NewRenderer 创建报告渲染器，templateDir 下存在 report.html 时优先使用，否则使用内置模板
+func NewRenderer(templateDir string) *Renderer {
+	return &Renderer{templateDir: templateDir}
+}
+
+// RenderHTML 渲染自包含的 HTML 报告
+func (r *Renderer) RenderHTML(w io.Writer, data *Data) error {
+	tmpl, err := r.loadTemplate()
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("渲染报告模板失败: %w", err)
+	}
+	return nil
+}
+
+// RenderPDF 渲染 PDF 报告
+func (r *Renderer) RenderPDF(w io.Writer, data *Data) error {
+	return renderPDF(w, data)
+}
+
+// loadTemplate 加载报告模板，每次渲染时重新读取以便修改模板后无需重启
+func (r *Renderer) loadTemplate() (*template.Template, error) {
+	tmpl := template.New(TemplateName).Funcs(templateFuncs)
+
+	if r.templateDir != "" {
+		path := filepath.Join(r.templateDir, TemplateName)
+		if content, err := os.ReadFile(path); err == nil {
+			parsed, err := tmpl.Parse(string(content))
+			if err != nil {
+				return nil, fmt.Errorf("解析报告模板失败: %s, %w", path, err)
+			}
+			return parsed, nil
+		}
+	}
+
+	parsed, err := tmpl.ParseFS(defaultTemplates, "templates/"+TemplateName)
+	if err != nil {
+		return nil, fmt.Errorf("解析内置报告模板失败: %w", err)
+	}
+	return parsed, nil
+}
+
+// templateFuncs 报告模板函数
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"formatTime": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04")
+	},
+	"sourceLabel": agent.EventSource.Label, // 兼容自定义模板
+}
+
+// keyEntities 选取带百科信息的关键实体，按重要度和度中心性排序
+func keyEntities(graph *agent.GraphResponse, limit int) []EntitySummary {
+	degree := make(map[string]float64)
+	for _, score := range analysis.NewGraph(graph).DegreeCentrality() {
+		degree[score.ID] = score.Score
+	}
+
+	nodes := make([]agent.GraphNode, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		if node.Baike != nil && node.Category != agent.BaikeRelationCategory {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Importance != nodes[j].Importance {
+			return nodes[i].Importance > nodes[j].Importance
+		}
+		return degree[nodes[i].ID] > degree[nodes[j].ID]
+	})
+	if limit > 0 && len(nodes) > limit {
+		nodes = nodes[:limit]
+	}
+
+	entities := make([]EntitySummary, len(nodes))
+	for i, node := range nodes {
+		description := node.Description
+		if description == "" {
+			description = node.Baike.LemmaDesc
+		}
+		entities[i] = EntitySummary{
+			ID:          node.ID,
+			Name:        node.Name,
+			Category:    node.Category,
+			Description: description,
+			Summary:     node.Baike.Summary,
+			PicURL:      node.Baike.PicURL,
+			URL:         node.Baike.URL,
+		}
+	}
+	return entities
+}
+
+// collectSources 汇总所有事件的来源并按 URL 去重
+func collectSources(events []agent.Event) []agent.EventSource {
+	seen := make(map[string]bool)
+	var sources []agent.EventSource
+	for _, event := range events {
+		for _, source := range event.Sources {
+			key := source.URL
+			if key == "" {
+				key = source.Title
+			}
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
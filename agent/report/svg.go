@@ -0,0 +1,138 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+
+	"lineNews/agent"
+)
+
+// categoryColors 节点类别配色，按类别首次出现顺序循环使用
+var categoryColors = []string{"#5470c6", "#91cc75", "#fac858", "#ee6666", "#73c0de", "#3ba272", "#fc8452", "#9a60b4", "#ea7ccc"}
+
+// positionedNode 布局后的节点
+type positionedNode struct {
+	Node   agent.GraphNode
+	X, Y   float64
+	Radius float64
+	Color  string
+}
+
+// graphLayout 图谱布局结果
+type graphLayout struct {
+	Nodes      []positionedNode
+	Index      map[string]int
+	Categories []string
+	Colors     map[string]string
+}
+
+// layoutGraph 按类别分组的环形布局，同类节点相邻排列
+func layoutGraph(graph *agent.GraphResponse, width, height float64) *graphLayout {
+	layout := &graphLayout{
+		Index:  make(map[string]int, len(graph.Nodes)),
+		Colors: make(map[string]string),
+	}
+	for _, node := range graph.Nodes {
+		if _, ok := layout.Colors[node.Category]; !ok {
+			layout.Colors[node.Category] = categoryColors[len(layout.Categories)%len(categoryColors)]
+			layout.Categories = append(layout.Categories, node.Category)
+		}
+	}
+
+	order := make(map[string]int, len(layout.Categories))
+	for i, category := range layout.Categories {
+		order[category] = i
+	}
+	nodes := append([]agent.GraphNode(nil), graph.Nodes...)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return order[nodes[i].Category] < order[nodes[j].Category]
+	})
+
+	cx, cy := width/2, height/2
+	radius := math.Min(width, height)/2 - 80
+	if radius < 50 {
+		radius = 50
+	}
+	for i, node := range nodes {
+		angle := 2*math.Pi*float64(i)/float64(len(nodes)) - math.Pi/2
+		r := 6.0
+		if node.Importance > 0 {
+			r += node.Importance * 10
+		}
+		layout.Index[node.ID] = i
+		layout.Nodes = append(layout.Nodes, positionedNode{
+			Node:   node,
+			X:      cx + radius*math.Cos(angle),
+			Y:      cy + radius*math.Sin(angle),
+			Radius: r,
+			Color:  layout.Colors[node.Category],
+		})
+	}
+	return layout
+}
+
+// RenderGraphSVG 将知识图谱渲染为 SVG 字符串
+func RenderGraphSVG(graph *agent.GraphResponse, width, height int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="100%%" font-family="sans-serif">`, width, height)
+	b.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="#999"/></marker></defs>`)
+
+	if graph == nil || len(graph.Nodes) == 0 {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" fill="#999">暂无知识图谱数据</text></svg>`, width/2, height/2)
+		return b.String()
+	}
+
+	layout := layoutGraph(graph, float64(width), float64(height))
+
+	for _, link := range graph.Links {
+		si, ok1 := layout.Index[link.Source]
+		ti, ok2 := layout.Index[link.Target]
+		if !ok1 || !ok2 {
+			continue
+		}
+		s, t := layout.Nodes[si], layout.Nodes[ti]
+		// 线段止于目标节点边缘，便于显示箭头
+		dx, dy := t.X-s.X, t.Y-s.Y
+		dist := math.Hypot(dx, dy)
+		if dist == 0 {
+			continue
+		}
+		x2, y2 := t.X-dx/dist*t.Radius, t.Y-dy/dist*t.Radius
+		strokeWidth := 1.0
+		if link.Weight > 0 {
+			strokeWidth += link.Weight * 2
+		}
+		marker := ""
+		if link.Directed {
+			marker = ` marker-end="url(#arrow)"`
+		}
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#bbb" stroke-width="%.1f"%s><title>%s</title></line>`,
+			s.X, s.Y, x2, y2, strokeWidth, marker, html.EscapeString(link.Relation))
+	}
+
+	for _, n := range layout.Nodes {
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s"><title>%s（%s）</title></circle>`,
+			n.X, n.Y, n.Radius, n.Color, html.EscapeString(n.Node.Name), html.EscapeString(n.Node.Category))
+		anchor := "start"
+		dx := n.Radius + 4
+		if n.X < float64(width)/2 {
+			anchor = "end"
+			dx = -dx
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="12" fill="#333" text-anchor="%s" dominant-baseline="middle">%s</text>`,
+			n.X+dx, n.Y, anchor, html.EscapeString(n.Node.Name))
+	}
+
+	// 图例
+	for i, category := range layout.Categories {
+		y := 20 + i*20
+		fmt.Fprintf(&b, `<circle cx="16" cy="%d" r="6" fill="%s"/><text x="28" y="%d" font-size="12" fill="#555" dominant-baseline="middle">%s</text>`,
+			y, layout.Colors[category], y, html.EscapeString(category))
+	}
+
+	b.WriteString(`</svg>`)
+	return b.String()
+}
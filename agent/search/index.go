@@ -0,0 +1,406 @@
+// Package search 为已保存的时间链提供事件全文检索
+//
+// 索引保存在内存中，服务启动时从所有记录重建，记录变化时同步更新，不单独持久化。
+// 记录本身以 JSON 文件保存在 store 中，数据量为专题数乘以每个专题几十到上百个事件，
+// 重建只需遍历一次记录；使用 bleve 或 SQLite FTS5 需要再维护一份与 store 同步的磁盘索引，
+// FTS5 还依赖 cgo。中文按单字和二字切分，与 bleve 的 CJK 分词方式相同。
+// 数据量增长到内存索引不再合适时，可以在 Index 的 Put、Remove、Search 接口后替换为上述引擎。
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"lineNews/agent"
+)
+
+// 检索参数
+const (
+	DefaultLimit = 20  // 默认每页结果数
+	MaxLimit     = 100 // 每页结果数上限
+	snippetRunes = 40  // 摘要高亮片段在命中位置前后保留的字符数
+)
+
+// 字段名，同时用作高亮结果的键
+const (
+	FieldTitle    = "title"
+	FieldSummary  = "summary"
+	FieldPeople   = "people"
+	FieldLocation = "location"
+)
+
+// fieldWeights 各字段命中的权重，标题命中最重要
+var fieldWeights = map[string]float64{
+	FieldTitle:    3,
+	FieldPeople:   2,
+	FieldLocation: 2,
+	FieldSummary:  1,
+}
+
+// document 索引中的一个事件
+type document struct {
+	recordID string
+	keyword  string
+	lang     string // 译文的语言，原文为空
+	event    agent.Event
+	fields   map[string]string // 小写后的字段文本，用于校验和计分
+}
+
+// Query 事件检索条件
+type Query struct {
+	Text     string            // 检索词，多个词以空白分隔，要求全部命中
+	Filter   agent.EventFilter // 时间窗口、地点和人物过滤，Limit 不生效
+	RecordID string            // 只检索指定的专题记录
+	Topic    string            // 只检索专题关键词包含该词的记录
+	Limit    int               // 每页结果数，默认 DefaultLimit，最大 MaxLimit
+	Offset   int               // 跳过的结果数
+}
+
+// Hit 检索命中的事件
+type Hit struct {
+	RecordID   string            `json:"record_id"`
+	Keyword    string            `json:"keyword"`        // 所属专题的关键词
+	Lang       string            `json:"lang,omitempty"` // 命中译文时为译文的语言
+	Event      agent.Event       `json:"event"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"` // 命中字段的高亮文本，命中词以 <em> 标记，其余内容已做 HTML 转义
+}
+
+// Result 检索结果
+type Result struct {
+	Total int   `json:"total"` // 命中总数
+	Hits  []Hit `json:"hits"`
+}
+
+// Index 内存中的事件全文索引，汉字按单字和二字切分，支持并发读写
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[int]*document
+	postings map[string]map[int]bool
+	byRecord map[string][]int
+	nextID   int
+}
+
+// NewIndex 创建空索引
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[int]*document),
+		postings: make(map[string]map[int]bool),
+		byRecord: make(map[string][]int),
+	}
+}
+
+// Put 写入专题记录的所有事件及其译文，替换该记录之前的索引内容，使不同语言的检索词都能命中
+func (idx *Index) Put(record *agent.TimelineRecord) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(record.ID)
+	if record.Timeline == nil {
+		return
+	}
+	idx.put(record, "", record.Timeline)
+	langs := make([]string, 0, len(record.Translations))
+	for lang := range record.Translations {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		if t := record.Translations[lang]; t.Timeline != nil {
+			idx.put(record, lang, t.Timeline)
+		}
+	}
+}
+
+// put 写入一份时间链的事件，调用方需持有写锁
+func (idx *Index) put(record *agent.TimelineRecord, lang string, timeline *agent.TimelineResponse) {
+	for _, event := range timeline.Events {
+		doc := &document{
+			recordID: record.ID,
+			keyword:  record.Keyword,
+			lang:     lang,
+			event:    event,
+			fields: map[string]string{
+				FieldTitle:    strings.ToLower(event.Title),
+				FieldSummary:  strings.ToLower(event.Summary),
+				FieldPeople:   strings.ToLower(strings.Join(event.People, "、")),
+				FieldLocation: strings.ToLower(event.Location),
+			},
+		}
+		id := idx.nextID
+		idx.nextID++
+		idx.docs[id] = doc
+		idx.byRecord[record.ID] = append(idx.byRecord[record.ID], id)
+		for _, text := range doc.fields {
+			for _, token := range indexTokens(text) {
+				if idx.postings[token] == nil {
+					idx.postings[token] = make(map[int]bool)
+				}
+				idx.postings[token][id] = true
+			}
+		}
+	}
+}
+
+// Remove 删除专题记录的索引内容
+func (idx *Index) Remove(recordID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(recordID)
+}
+
+// remove 删除专题记录的索引内容，调用方需持有写锁
+func (idx *Index) remove(recordID string) {
+	for _, id := range idx.byRecord[recordID] {
+		for _, text := range idx.docs[id].fields {
+			for _, token := range indexTokens(text) {
+				delete(idx.postings[token], id)
+				if len(idx.postings[token]) == 0 {
+					delete(idx.postings, token)
+				}
+			}
+		}
+		delete(idx.docs, id)
+	}
+	delete(idx.byRecord, recordID)
+}
+
+// Size 返回已索引的事件数
+func (idx *Index) Size() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Search 检索同时包含所有检索词的事件，按相关度从高到低排序
+// 先用词元倒排表求候选，再校验检索词在字段中连续出现；相关度为各字段命中次数乘以字段权重和检索词的逆文档频率之和
+func (idx *Index) Search(q Query) Result {
+	terms := strings.Fields(strings.ToLower(q.Text))
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	topic := strings.ToLower(strings.TrimSpace(q.Topic))
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	result := Result{Hits: []Hit{}}
+	if len(terms) == 0 {
+		return result
+	}
+	idf := make([]float64, len(terms))
+	var candidates map[int]bool
+	for i, term := range terms {
+		matched := idx.candidates(term)
+		idf[i] = math.Log(1 + float64(len(idx.docs))/float64(len(matched)+1))
+		if candidates == nil {
+			candidates = matched
+			continue
+		}
+		for id := range candidates {
+			if !matched[id] {
+				delete(candidates, id)
+			}
+		}
+	}
+
+	var hits []Hit
+	for id := range candidates {
+		doc := idx.docs[id]
+		if q.RecordID != "" && doc.recordID != q.RecordID {
+			continue
+		}
+		if topic != "" && !strings.Contains(strings.ToLower(doc.keyword), topic) {
+			continue
+		}
+		if !q.Filter.Match(doc.event) {
+			continue
+		}
+		score, ok := doc.score(terms, idf)
+		if !ok {
+			continue
+		}
+		hits = append(hits, Hit{RecordID: doc.recordID, Keyword: doc.keyword, Lang: doc.lang, Event: doc.event, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].RecordID != hits[j].RecordID {
+			return hits[i].RecordID < hits[j].RecordID
+		}
+		if hits[i].Event.ID != hits[j].Event.ID {
+			return hits[i].Event.ID < hits[j].Event.ID
+		}
+		return hits[i].Lang < hits[j].Lang
+	})
+
+	result.Total = len(hits)
+	if q.Offset >= len(hits) {
+		return result
+	}
+	hits = hits[max(q.Offset, 0):]
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	for i := range hits {
+		hits[i].Highlights = highlights(hits[i].Event, terms)
+	}
+	result.Hits = hits
+	return result
+}
+
+// candidates 返回包含检索词全部词元的事件，调用方需持有读锁
+func (idx *Index) candidates(term string) map[int]bool {
+	matched := make(map[int]bool)
+	tokens := queryTokens(term)
+	if len(tokens) == 0 {
+		return matched
+	}
+	for id := range idx.postings[tokens[0]] {
+		matched[id] = true
+	}
+	for _, token := range tokens[1:] {
+		posting := idx.postings[token]
+		for id := range matched {
+			if !posting[id] {
+				delete(matched, id)
+			}
+		}
+	}
+	return matched
+}
+
+// score 计算相关度，任一检索词未在字段中连续出现时返回 false
+func (d *document) score(terms []string, idf []float64) (float64, bool) {
+	var score float64
+	for i, term := range terms {
+		var count float64
+		for field, text := range d.fields {
+			count += float64(strings.Count(text, term)) * fieldWeights[field]
+		}
+		if count == 0 {
+			return 0, false
+		}
+		score += count * idf[i]
+	}
+	return math.Round(score*1000) / 1000, true
+}
+
+// highlights 生成命中字段的高亮文本，摘要只保留第一个命中位置附近的片段
+func highlights(event agent.Event, terms []string) map[string]string {
+	result := make(map[string]string)
+	fields := map[string]string{
+		FieldTitle:    event.Title,
+		FieldSummary:  event.Summary,
+		FieldPeople:   strings.Join(event.People, "、"),
+		FieldLocation: event.Location,
+	}
+	for field, text := range fields {
+		if field == FieldSummary {
+			text = snippet(text, terms)
+		}
+		if marked, ok := highlight(text, terms); ok {
+			result[field] = marked
+		}
+	}
+	return result
+}
+
+// highlight 用 <em> 标记文本中的检索词（不区分大小写），其余部分做 HTML 转义；没有命中时返回 false
+func highlight(text string, terms []string) (string, bool) {
+	// 在小写文本上查找，再按位置对应关系标记原文，保留原文的大小写
+	lower, offsets := foldCase(text)
+	marks := make([]bool, len(text))
+	found := false
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		for start := 0; ; {
+			i := strings.Index(lower[start:], term)
+			if i < 0 {
+				break
+			}
+			for j := offsets[start+i]; j < offsets[start+i+len(term)]; j++ {
+				marks[j] = true
+			}
+			found = true
+			start += i + len(term)
+		}
+	}
+	if !found {
+		return "", false
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		j := i
+		for j < len(text) && marks[j] == marks[i] {
+			j++
+		}
+		if marks[i] {
+			b.WriteString("<em>" + html.EscapeString(text[i:j]) + "</em>")
+		} else {
+			b.WriteString(html.EscapeString(text[i:j]))
+		}
+		i = j
+	}
+	return b.String(), true
+}
+
+// snippet 截取第一个命中位置前后 snippetRunes 个字符，被截断的一端以省略号表示
+func snippet(text string, terms []string) string {
+	if utf8.RuneCountInString(text) <= 2*snippetRunes {
+		return text
+	}
+	lower, offsets := foldCase(text)
+	first := -1
+	for _, term := range terms {
+		if i := strings.Index(lower, term); term != "" && i >= 0 && (first < 0 || offsets[i] < first) {
+			first = offsets[i]
+		}
+	}
+	if first < 0 {
+		return text
+	}
+
+	runes := []rune(text)
+	pos := utf8.RuneCountInString(text[:first])
+	start, end := max(pos-snippetRunes, 0), min(pos+snippetRunes, len(runes))
+	out := string(runes[start:end])
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(runes) {
+		out += "…"
+	}
+	return out
+}
+
+// foldCase 按字符转为小写，并返回小写文本每个字节位置对应的原文字节位置
+// 小写转换可能改变字节长度（如部分 Unicode 字母、无效的 UTF-8 字节），offsets 用于把小写文本中的命中位置换算回原文；
+// offsets 比小写文本多一项，对应原文末尾
+func foldCase(text string) (string, []int) {
+	var b strings.Builder
+	b.Grow(len(text))
+	offsets := make([]int, 0, len(text)+1)
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		n, _ := b.WriteRune(unicode.ToLower(r))
+		for k := 0; k < n; k++ {
+			offsets = append(offsets, i)
+		}
+		i += size
+	}
+	offsets = append(offsets, len(text))
+	return b.String(), offsets
+}
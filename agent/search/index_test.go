@@ -0,0 +1,122 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"lineNews/agent"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+		found bool
+	}{
+		{"中文命中", "华为发布新手机", []string{"华为"}, "<em>华为</em>发布新手机", true},
+		{"保留原文大小写", "Huawei Mate 60", []string{"huawei"}, "<em>Huawei</em> Mate 60", true},
+		{"多个检索词", "华为与苹果", []string{"华为", "苹果"}, "<em>华为</em>与<em>苹果</em>", true},
+		{"转义 HTML", "<b>华为</b>", []string{"华为"}, "&lt;b&gt;<em>华为</em>&lt;/b&gt;", true},
+		{"小写改变字节长度", "İstanbul 会议", []string{"会议"}, "İstanbul <em>会议</em>", true},
+		{"命中改变字节长度的字符", "ȺB 公司", []string{"ⱥb"}, "<em>ȺB</em> 公司", true},
+		{"没有命中", "华为发布新手机", []string{"苹果"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := highlight(tt.text, tt.terms)
+			if got != tt.want || found != tt.found {
+				t.Errorf("highlight(%q, %q) = %q, %v; want %q, %v", tt.text, tt.terms, got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("甲", 60) + "华为" + strings.Repeat("乙", 60)
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{"短文本原样返回", "华为发布新手机", []string{"华为"}, "华为发布新手机"},
+		{"截取命中位置前后", long, []string{"华为"}, "…" + strings.Repeat("甲", 40) + "华为" + strings.Repeat("乙", 38) + "…"},
+		{"没有命中原样返回", long, []string{"苹果"}, long},
+		{"小写改变字节长度时仍能截取", "İ" + long, []string{"华为"}, "…" + strings.Repeat("甲", 40) + "华为" + strings.Repeat("乙", 38) + "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snippet(tt.text, tt.terms); got != tt.want {
+				t.Errorf("snippet() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(string) []string
+		text string
+		want []string
+	}{
+		{"索引汉字单字和二字", indexTokens, "华为手机", []string{"华", "华为", "为", "为手", "手", "手机", "机"}},
+		{"索引字母数字转小写", indexTokens, "Mate 60", []string{"mate", "60"}},
+		{"检索汉字只用二字", queryTokens, "华为手机", []string{"华为", "为手", "手机"}},
+		{"检索单个汉字", queryTokens, "华", []string{"华"}},
+		{"丢弃标点", queryTokens, "华为，Mate!", []string{"华为", "mate"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokens(%q) = %q; want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	idx := NewIndex()
+	idx.Put(&agent.TimelineRecord{ID: "r1", Keyword: "华为", Timeline: &agent.TimelineResponse{Events: []agent.Event{
+		{ID: "1", Title: "华为发布 Mate 60", Time: "2023-08-29", Location: "深圳", Summary: "华为在深圳发布新手机"},
+		{ID: "2", Title: "芯片出口管制", Time: "2023-10-17", Location: "华盛顿", Summary: "美国更新出口管制规则"},
+	}}})
+	idx.Put(&agent.TimelineRecord{ID: "r2", Keyword: "苹果", Timeline: &agent.TimelineResponse{Events: []agent.Event{
+		{ID: "1", Title: "苹果发布 iPhone 15", Time: "2023-09-12", Location: "库比蒂诺", Summary: "与华为新机同期发布"},
+	}}})
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string // 按顺序的 记录ID/事件ID
+	}{
+		{"标题命中排在摘要命中之前", Query{Text: "华为"}, []string{"r1/1", "r2/1"}},
+		{"多个检索词全部命中", Query{Text: "华为 深圳"}, []string{"r1/1"}},
+		{"英文不区分大小写", Query{Text: "mate"}, []string{"r1/1"}},
+		{"检索词需要连续出现", Query{Text: "华发"}, nil},
+		{"限定记录", Query{Text: "华为", RecordID: "r2"}, []string{"r2/1"}},
+		{"限定专题", Query{Text: "发布", Topic: "苹果"}, []string{"r2/1"}},
+		{"分页", Query{Text: "华为", Limit: 1, Offset: 1}, []string{"r2/1"}},
+		{"空检索词", Query{Text: " "}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := idx.Search(tt.query)
+			var got []string
+			for _, hit := range result.Hits {
+				got = append(got, hit.RecordID+"/"+hit.Event.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%+v) = %v; want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	idx.Remove("r1")
+	if result := idx.Search(Query{Text: "华为"}); result.Total != 1 || result.Hits[0].RecordID != "r2" {
+		t.Errorf("删除记录后仍命中: %+v", result)
+	}
+}
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// indexTokens 索引文本时使用的词元：汉字按单字和相邻二字切分，其余字母数字按连续片段切分，均转为小写
+func indexTokens(text string) []string {
+	var tokens []string
+	for _, run := range splitRuns(strings.ToLower(text)) {
+		if !run.han {
+			tokens = append(tokens, string(run.runes))
+			continue
+		}
+		for i, r := range run.runes {
+			tokens = append(tokens, string(r))
+			if i+1 < len(run.runes) {
+				tokens = append(tokens, string(run.runes[i:i+2]))
+			}
+		}
+	}
+	return tokens
+}
+
+// queryTokens 检索词对应的词元：汉字只使用相邻二字（单个汉字时使用单字），与 indexTokens 的结果取交集即可得到候选
+func queryTokens(term string) []string {
+	var tokens []string
+	for _, run := range splitRuns(strings.ToLower(term)) {
+		if !run.han || len(run.runes) == 1 {
+			tokens = append(tokens, string(run.runes))
+			continue
+		}
+		for i := 0; i+1 < len(run.runes); i++ {
+			tokens = append(tokens, string(run.runes[i:i+2]))
+		}
+	}
+	return tokens
+}
+
+// textRun 连续的汉字或字母数字片段
+type textRun struct {
+	runes []rune
+	han   bool
+}
+
+// splitRuns 将文本切分为连续的汉字片段和字母数字片段，丢弃空白和标点
+func splitRuns(text string) []textRun {
+	var runs []textRun
+	var current textRun
+	flush := func() {
+		if len(current.runes) > 0 {
+			runs = append(runs, current)
+		}
+		current = textRun{}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if !current.han {
+				flush()
+				current.han = true
+			}
+			current.runes = append(current.runes, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if current.han {
+				flush()
+			}
+			current.runes = append(current.runes, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return runs
+}
@@ -0,0 +1,266 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+// Document 用于生成时间链的原始文档
+type Document struct {
+	Title       string `json:"title"`
+	URL         string `json:"url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+	PublishTime string `json:"publish_time,omitempty"`
+	Content     string `json:"content"`
+}
+
+// DocumentFetcher 文档抓取器，按 URL 获取文档正文；可替换为带缓存、代理或付费墙处理的实现
+type DocumentFetcher interface {
+	Fetch(ctx context.Context, rawURL string) (*Document, error)
+}
+
+// HTTPDocumentFetcher 基于 HTTP GET 的默认文档抓取器
+type HTTPDocumentFetcher struct {
+	Client       *http.Client
+	UserAgent    string
+	MaxBytes     int64
+	AllowPrivate bool // 是否允许访问内网地址，默认禁止以避免 SSRF
+}
+
+// NewHTTPDocumentFetcher 创建默认文档抓取器
+func NewHTTPDocumentFetcher() *HTTPDocumentFetcher {
+	f := &HTTPDocumentFetcher{
+		UserAgent: "Mozilla/5.0 (compatible; LineNewsBot/1.0)",
+		MaxBytes:  5 << 20,
+	}
+	f.Client = &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: NewPublicTransport(func() bool { return f.AllowPrivate }),
+	}
+	return f
+}
+
+// NewPublicTransport 创建禁止连接内网地址的 Transport，在建立连接时按解析后的 IP 检查，重定向同样受限
+// allowPrivate 返回 true 时不做限制
+func NewPublicTransport(allowPrivate func() bool) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if allowPrivate() {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || IsPrivateIP(ip) {
+				return fmt.Errorf("禁止访问内网地址: %s", host)
+			}
+			return nil
+		},
+	}
+	return &http.Transport{DialContext: dialer.DialContext}
+}
+
+// IsPrivateIP 判断是否为回环、内网、链路本地或未指定地址
+func IsPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+}
+
+// Fetch 抓取网页并提取标题、站点名、发布时间和正文
+func (f *HTTPDocumentFetcher) Fetch(ctx context.Context, rawURL string) (*Document, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("无效的文档地址: %s", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+	req.Header.Set("User-Agent", f.UserAgent)
+	req.Header.Set("Accept", "text/html,text/plain;q=0.9,*/*;q=0.5")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("抓取文档失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("抓取文档失败，状态码: %d, 地址: %s", resp.StatusCode, rawURL)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.MaxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("读取文档失败: %w", err)
+	}
+	if !utf8.Valid(body) {
+		return nil, fmt.Errorf("文档不是 UTF-8 编码: %s", rawURL)
+	}
+
+	var doc *Document
+	if strings.Contains(resp.Header.Get("Content-Type"), "text/plain") {
+		doc = &Document{Content: strings.TrimSpace(string(body))}
+	} else {
+		doc = ParseHTMLDocument(string(body))
+	}
+	doc.URL = rawURL
+	if doc.Title == "" {
+		doc.Title = rawURL
+	}
+	if doc.SiteName == "" {
+		doc.SiteName = u.Hostname()
+	}
+	return doc, nil
+}
+
+// FetchDocuments 并发抓取多个 URL，返回结果与输入顺序一致；任一失败时返回第一个错误
+func FetchDocuments(ctx context.Context, fetcher DocumentFetcher, urls []string, concurrency int) ([]*Document, error) {
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	docs := make([]*Document, len(urls))
+	errs := make([]error, len(urls))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, rawURL := range urls {
+		wg.Add(1)
+		go func(i int, rawURL string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			docs[i], errs[i] = fetcher.Fetch(ctx, rawURL)
+		}(i, rawURL)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+var (
+	htmlDropPattern    = regexp.MustCompile(`(?is)<(script|style|noscript|svg|head|nav|footer|aside|form)\b.*?</(script|style|noscript|svg|head|nav|footer|aside|form)>`)
+	htmlTitlePattern   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlMetaPattern    = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	htmlAttrPattern    = regexp.MustCompile(`(?is)(property|name|content)\s*=\s*["']([^"']*)["']`)
+	htmlArticlePattern = regexp.MustCompile(`(?is)<article\b[^>]*>(.*)</article>`)
+	htmlBlockPattern   = regexp.MustCompile(`(?i)</?(p|div|br|li|h[1-6]|tr|section|article|blockquote)\b[^>]*>`)
+	htmlTagPattern     = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLinesPattern  = regexp.MustCompile(`\n\s*\n+`)
+	spacesPattern      = regexp.MustCompile(`[ \t\f\r\x{3000}]+`)
+)
+
+// ParseHTMLDocument 从 HTML 中提取标题、元信息和正文；存在 <article> 时只取其内容
+func ParseHTMLDocument(source string) *Document {
+	doc := &Document{}
+	if m := htmlTitlePattern.FindStringSubmatch(source); m != nil {
+		doc.Title = cleanText(m[1])
+	}
+
+	for _, meta := range htmlMetaPattern.FindAllString(source, -1) {
+		attrs := make(map[string]string)
+		for _, attr := range htmlAttrPattern.FindAllStringSubmatch(meta, -1) {
+			attrs[strings.ToLower(attr[1])] = attr[2]
+		}
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		value := html.UnescapeString(attrs["content"])
+		switch strings.ToLower(key) {
+		case "og:title":
+			if value != "" {
+				doc.Title = value
+			}
+		case "og:site_name":
+			doc.SiteName = value
+		case "article:published_time", "pubdate", "publishdate":
+			doc.PublishTime = value
+		}
+	}
+
+	body := htmlDropPattern.ReplaceAllString(source, " ")
+	if m := htmlArticlePattern.FindStringSubmatch(body); m != nil {
+		body = m[1]
+	}
+	doc.Content = HTMLToText(body)
+	return doc
+}
+
+// HTMLToText 去除 HTML 标签并保留段落换行
+func HTMLToText(source string) string {
+	text := htmlDropPattern.ReplaceAllString(source, " ")
+	text = htmlBlockPattern.ReplaceAllString(text, "\n")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	return cleanText(text)
+}
+
+// cleanText 反转义 HTML 实体并规整空白
+func cleanText(text string) string {
+	text = html.UnescapeString(text)
+	text = spacesPattern.ReplaceAllString(text, " ")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// ChunkText 将长文本按段落和句子边界切分为不超过 maxRunes 个字符的片段，相邻片段重叠 overlap 个字符以保留上下文
+func ChunkText(text string, maxRunes, overlap int) []string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) == 0 {
+		return nil
+	}
+	if maxRunes <= 0 || len(runes) <= maxRunes {
+		return []string{string(runes)}
+	}
+	if overlap < 0 || overlap >= maxRunes/2 {
+		overlap = maxRunes / 10
+	}
+
+	var chunks []string
+	start := 0
+	for start < len(runes) {
+		end := start + maxRunes
+		if end >= len(runes) {
+			chunks = append(chunks, string(runes[start:]))
+			break
+		}
+		// 在后半段中从后往前寻找段落或句子边界
+		cut := -1
+		for i := end; i > start+maxRunes/2; i-- {
+			if runes[i-1] == '\n' {
+				cut = i
+				break
+			}
+			if cut == -1 && strings.ContainsRune("。！？；.!?", runes[i-1]) {
+				cut = i
+			}
+		}
+		if cut == -1 {
+			cut = end
+		}
+		chunks = append(chunks, strings.TrimSpace(string(runes[start:cut])))
+		start = cut - overlap
+	}
+	return chunks
+}
@@ -39,6 +39,11 @@ func (c *LLMCaller) CallWithPrompt(ctx context.Context, systemPrompt, userPrompt
 	if err != nil {
 		return "", fmt.Errorf("LLM调用失败: %w", err)
 	}
+	if response.ResponseMeta != nil && response.ResponseMeta.Usage != nil {
+		CallStatsFrom(ctx).AddCall(response.ResponseMeta.Usage.PromptTokens, response.ResponseMeta.Usage.CompletionTokens)
+	} else {
+		CallStatsFrom(ctx).AddCall(0, 0)
+	}
 
 	logutil.LogInfo("[LLMCaller] %s阶段 AI 响应: %s", stage, response.Content)
 	return response.Content, nil
@@ -52,6 +57,7 @@ func (c *LLMCaller) CallAndUnmarshal(ctx context.Context, systemPrompt, userProm
 	}
 
 	if err := json.Unmarshal([]byte(content), result); err != nil {
+		CallStatsFrom(ctx).AddParseFailure()
 		return fmt.Errorf("解析JSON失败: %w, 原始内容: %s", err, content)
 	}
 
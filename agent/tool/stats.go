@@ -0,0 +1,62 @@
+package tool
+
+import (
+	"context"
+	"sync"
+)
+
+// statsKey CallStats 在 context 中的键
+type statsKey struct{}
+
+// CallStats 一次生成过程中模型调用的统计，用于实验指标；方法在 nil 上调用时不做任何事
+type CallStats struct {
+	mu               sync.Mutex
+	Calls            int // 模型调用次数
+	ParseFailures    int // 响应无法解析为 JSON 的次数
+	RefineRounds     int // 结果被采用的反思优化轮数
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// WithCallStats 返回携带统计的 context，LLMCaller 和工作流会把调用情况记录到其中
+func WithCallStats(ctx context.Context, stats *CallStats) context.Context {
+	return context.WithValue(ctx, statsKey{}, stats)
+}
+
+// CallStatsFrom 返回 context 中的统计，没有时返回 nil
+func CallStatsFrom(ctx context.Context) *CallStats {
+	stats, _ := ctx.Value(statsKey{}).(*CallStats)
+	return stats
+}
+
+// AddCall 记录一次模型调用及其 Token 用量
+func (s *CallStats) AddCall(promptTokens, completionTokens int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Calls++
+	s.PromptTokens += promptTokens
+	s.CompletionTokens += completionTokens
+}
+
+// AddParseFailure 记录一次响应解析失败
+func (s *CallStats) AddParseFailure() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ParseFailures++
+}
+
+// AddRefineRound 记录一轮结果被采用的反思优化，失败或返回空结果的轮次不计入
+func (s *CallStats) AddRefineRound() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.RefineRounds++
+}
@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"lineNews/agent/logutil"
+	"lineNews/agent/prompt"
+	"lineNews/agent/workflow"
+)
+
+// Translation 专题记录时间链和图谱的译文
+type Translation struct {
+	Lang      string            `json:"lang"`
+	Version   int               `json:"version"` // 翻译时记录的版本号，记录更新后译文过期
+	Timeline  *TimelineResponse `json:"timeline,omitempty"`
+	Graph     *GraphResponse    `json:"graph,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// Lang 返回记录时间链的原文语言，未标注时为中文
+func (r *TimelineRecord) Lang() string {
+	if r.Timeline == nil || r.Timeline.Lang == "" {
+		return prompt.LangZH
+	}
+	return r.Timeline.Lang
+}
+
+// SetTranslation 保存译文，替换同一语言之前的译文
+func (r *TimelineRecord) SetTranslation(t *Translation) {
+	if r.Translations == nil {
+		r.Translations = make(map[string]*Translation)
+	}
+	if t.Timeline != nil {
+		t.Timeline.ID = r.ID
+	}
+	if t.Graph != nil {
+		t.Graph.ID = r.ID
+	}
+	r.Translations[t.Lang] = t
+}
+
+// Stale 判断译文是否落后于记录的当前版本
+func (t *Translation) Stale(r *TimelineRecord) bool {
+	return t.Version < r.Version
+}
+
+// Translate 将时间链和图谱翻译为 lang 对应的语言，graph 可以为 nil
+// 译文中的事件在 Original 中保留原文标题、地点和人物，节点在 OriginalName 中保留原文名称并将原文名称加入别名
+func (a *NewsTimelineAgent) Translate(ctx context.Context, timeline *TimelineResponse, graph *GraphResponse, lang string) (*TimelineResponse, *GraphResponse, error) {
+	if timeline == nil {
+		return nil, nil, fmt.Errorf("时间链为空")
+	}
+	logutil.LogInfo("开始翻译时间链: %s (语言: %s)", timeline.Keyword, lang)
+
+	batch := &workflow.TranslationBatch{}
+	for _, e := range timeline.Events {
+		batch.Events = append(batch.Events, workflow.TranslationEvent{ID: e.ID, Title: e.Title, Location: e.Location, People: e.People, Summary: e.Summary})
+	}
+	relationIndex := make(map[string]int)
+	if graph != nil {
+		for _, n := range graph.Nodes {
+			batch.Nodes = append(batch.Nodes, workflow.TranslationNode{ID: n.ID, Name: n.Name, Category: n.Category, Description: n.Description})
+		}
+		for _, l := range graph.Links {
+			if _, ok := relationIndex[l.Relation]; !ok {
+				relationIndex[l.Relation] = len(batch.Relations)
+				batch.Relations = append(batch.Relations, l.Relation)
+			}
+		}
+	}
+
+	translated, err := a.translateWorkflow.Translate(ctx, batch, lang)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outTimeline := &TimelineResponse{Keyword: timeline.Keyword, Lang: lang, Events: make([]Event, len(timeline.Events))}
+	for i, e := range timeline.Events {
+		t := translated.Events[i]
+		e.Original = &EventOriginal{Title: e.Title, Location: e.Location, People: e.People}
+		e.Title, e.Location, e.People, e.Summary = t.Title, t.Location, t.People, t.Summary
+		outTimeline.Events[i] = e
+	}
+	if graph == nil {
+		return outTimeline, nil, nil
+	}
+
+	outGraph := &GraphResponse{Keyword: graph.Keyword, Nodes: make([]GraphNode, len(graph.Nodes)), Links: make([]GraphLink, len(graph.Links))}
+	for i, n := range graph.Nodes {
+		t := translated.Nodes[i]
+		n.Aliases = append([]string(nil), n.Aliases...)
+		if t.Name != "" && t.Name != n.Name {
+			n.OriginalName = n.Name
+			n.Aliases = appendAlias(n.Aliases, n.Name)
+			n.Name = t.Name
+		}
+		n.Category, n.Description = t.Category, t.Description
+		outGraph.Nodes[i] = n
+	}
+	for i, l := range graph.Links {
+		l.Relation = translated.Relations[relationIndex[l.Relation]]
+		outGraph.Links[i] = l
+	}
+	return outTimeline, outGraph, nil
+}
+
+// appendAlias 追加不重复的别名
+func appendAlias(aliases []string, alias string) []string {
+	for _, a := range aliases {
+		if a == alias {
+			return aliases
+		}
+	}
+	return append(aliases, alias)
+}
@@ -2,39 +2,107 @@ package agent
 
 // Event 事件数据结构
 type Event struct {
-	ID       string   `json:"id"`
+	ID       string         `json:"id"`
+	Title    string         `json:"title"`
+	Time     string         `json:"time"`
+	Location string         `json:"location"`
+	People   []string       `json:"people"`
+	Summary  string         `json:"summary"`
+	Sources  []EventSource  `json:"sources,omitempty"`  // 事件来源
+	Edited   bool           `json:"edited,omitempty"`   // 是否经过人工编辑，重新生成和增量更新时保留
+	Original *EventOriginal `json:"original,omitempty"` // 译文中事件的原文名称
+}
+
+// EventOriginal 翻译前事件中的名称，实体名称在译文中保留原文
+type EventOriginal struct {
 	Title    string   `json:"title"`
-	Time     string   `json:"time"`
-	Location string   `json:"location"`
-	People   []string `json:"people"`
-	Summary  string   `json:"summary"`
+	Location string   `json:"location,omitempty"`
+	People   []string `json:"people,omitempty"`
+}
+
+// EventSource 事件来源
+type EventSource struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	SiteName    string `json:"site_name,omitempty"`
+	PublishTime string `json:"publish_time,omitempty"`
 }
 
 // TimelineResponse 时间链响应
 type TimelineResponse struct {
-	Keyword string  `json:"keyword"`
-	Events  []Event `json:"events"`
+	ID            string         `json:"id,omitempty"` // 保存后的记录 ID
+	Keyword       string         `json:"keyword"`
+	Lang          string         `json:"lang,omitempty"`           // 输出语言，为空表示中文
+	PromptVersion string         `json:"prompt_version,omitempty"` // 生成时使用的提示词模板版本
+	Experiment    *ExperimentTag `json:"experiment,omitempty"`     // 生成时所属的实验变体
+	Events        []Event        `json:"events"`
 }
 
 // GraphNode 图谱节点
 type GraphNode struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Category string `json:"category"`
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Category     string            `json:"category"`
+	Description  string            `json:"description,omitempty"`   // 节点描述
+	Aliases      []string          `json:"aliases,omitempty"`       // 别名
+	Attributes   map[string]string `json:"attributes,omitempty"`    // 扩展属性，如职务、所属机构
+	FirstSeen    string            `json:"first_seen,omitempty"`    // 首次出现时间
+	LastSeen     string            `json:"last_seen,omitempty"`     // 最近出现时间
+	Importance   float64           `json:"importance,omitempty"`    // 重要度，取值 0-1
+	Baike        *BaikeInfo        `json:"baike,omitempty"`         // 百度百科补充信息
+	Edited       bool              `json:"edited,omitempty"`        // 是否经过人工编辑，重新生成图谱时保留
+	Topics       []string          `json:"topics,omitempty"`        // 合并图谱中节点所属的专题关键词
+	Provenance   []Provenance      `json:"provenance,omitempty"`    // 合并图谱中节点的来源
+	OriginalName string            `json:"original_name,omitempty"` // 译文中节点的原文名称
+}
+
+// BaikeInfo 节点的百度百科补充信息
+type BaikeInfo struct {
+	LemmaID    int64  `json:"lemma_id"`
+	LemmaTitle string `json:"lemma_title"`
+	LemmaDesc  string `json:"lemma_desc,omitempty"`
+	Summary    string `json:"summary,omitempty"`
+	PicURL     string `json:"pic_url,omitempty"`
+	URL        string `json:"url,omitempty"`
 }
 
 // GraphLink 图谱连接
 type GraphLink struct {
-	Source   string `json:"source"`
-	Target   string `json:"target"`
-	Relation string `json:"relation"`
+	ID         string       `json:"id,omitempty"` // 关系 ID，编辑关系时分配
+	Source     string       `json:"source"`
+	Target     string       `json:"target"`
+	Relation   string       `json:"relation"`
+	Weight     float64      `json:"weight,omitempty"`     // 关系强度，取值 0-1
+	Time       string       `json:"time,omitempty"`       // 关系发生时间
+	Directed   bool         `json:"directed,omitempty"`   // 是否为有向关系（source 指向 target）
+	EventIDs   []string     `json:"event_ids,omitempty"`  // 支撑该关系的事件ID
+	Edited     bool         `json:"edited,omitempty"`     // 是否经过人工编辑，重新生成图谱时保留
+	Topics     []string     `json:"topics,omitempty"`     // 合并图谱中关系所属的专题关键词
+	Provenance []Provenance `json:"provenance,omitempty"` // 合并图谱中关系的来源
+}
+
+// Provenance 合并图谱中节点或关系的来源
+type Provenance struct {
+	RecordID string   `json:"record_id,omitempty"`
+	Keyword  string   `json:"keyword"`
+	ID       string   `json:"id,omitempty"`        // 在来源图谱中的节点或关系 ID
+	EventIDs []string `json:"event_ids,omitempty"` // 来源时间链中支撑该关系的事件 ID
+}
+
+// ExperimentTag 生成结果所属的实验和变体
+type ExperimentTag struct {
+	ID      string `json:"id"`
+	Variant string `json:"variant"`
 }
 
 // GraphResponse 图谱响应
 type GraphResponse struct {
-	Keyword string      `json:"keyword"`
-	Nodes   []GraphNode `json:"nodes"`
-	Links   []GraphLink `json:"links"`
+	ID            string         `json:"id,omitempty"` // 保存后的记录 ID
+	Keyword       string         `json:"keyword"`
+	PromptVersion string         `json:"prompt_version,omitempty"` // 生成时使用的提示词模板版本
+	Experiment    *ExperimentTag `json:"experiment,omitempty"`     // 生成时所属的实验变体
+	Nodes         []GraphNode    `json:"nodes"`
+	Links         []GraphLink    `json:"links"`
 }
 
 // KeywordClarificationResponse 关键词澄清响应
@@ -0,0 +1,282 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"lineNews/agent"
+	"lineNews/agent/logutil"
+	"lineNews/agent/prompt"
+	"lineNews/model"
+)
+
+// 增量搜索来源
+const (
+	SourceDeepSearch = "deepsearch" // 百度深度搜索，按 SearchRecencyFilter 限定时间范围
+	SourceArk        = "ark"        // Ark 联网搜索
+)
+
+// recentEventsInPrompt 提示词中列出的已有近期事件数，用于避免重复
+const recentEventsInPrompt = 10
+
+// Options 增量更新选项
+type Options struct {
+	Source  string // deepsearch 或 ark，默认 deepsearch
+	Recency string // week、month、semiyear 或 year，为空时根据最新事件日期自动选择
+}
+
+// Diff 增量更新结果
+type Diff struct {
+	Since   string        `json:"since"`   // 时间链中最新事件的日期，只保留晚于该日期的新事件
+	Source  string        `json:"source"`  // 实际使用的搜索来源
+	Recency string        `json:"recency"` // 深度搜索使用的时间范围
+	Added   []agent.Event `json:"added"`   // 新增事件，已分配 ID
+	Skipped int           `json:"skipped"` // 因时间过早或与已有事件重复而丢弃的事件数
+
+	PromptVersion string `json:"prompt_version,omitempty"` // 搜索时使用的提示词模板版本
+}
+
+// searchResult 模型返回的新事件
+type searchResult struct {
+	Events []agent.Event `json:"events"`
+}
+
+// Search 搜索时间链最新事件之后的新闻，返回去重后的新增事件，不修改原时间链
+func Search(ctx context.Context, timeline *agent.TimelineResponse, opts Options) (*Diff, error) {
+	latest, ok := agent.LatestEventTime(timeline.Events)
+	if !ok {
+		return nil, fmt.Errorf("时间链中没有可解析时间的事件，无法增量更新")
+	}
+	since := latest.Start.Format("2006-01-02")
+
+	prompts := prompt.FromContext(ctx)
+	diff := &Diff{Since: since, Source: opts.Source, PromptVersion: prompts.Version}
+	if diff.Source == "" {
+		diff.Source = SourceDeepSearch
+	}
+
+	// 新事件与时间链使用同一语言
+	lang := timeline.Lang
+	systemPrompt, err := prompts.Render(prompt.TimelineUpdateSystem, lang, prompt.Vars{Keyword: timeline.Keyword})
+	if err != nil {
+		return nil, err
+	}
+	userPrompt, err := prompts.Render(prompt.TimelineUpdateUser, lang, prompt.Vars{
+		Keyword: timeline.Keyword,
+		Since:   since,
+		Input:   recentEventsText(timeline.Events),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var found []agent.Event
+	switch diff.Source {
+	case SourceDeepSearch:
+		diff.Recency = opts.Recency
+		if diff.Recency == "" {
+			diff.Recency = RecencyFor(latest.Start, time.Now())
+		}
+		found, err = searchDeepSearch(systemPrompt, userPrompt, diff.Recency)
+	case SourceArk:
+		found, err = searchArk(ctx, systemPrompt, userPrompt)
+	default:
+		return nil, fmt.Errorf("不支持的搜索来源: %s", diff.Source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nextID := agent.NextEventID(timeline.Events)
+	existing := append([]agent.Event(nil), timeline.Events...)
+	for _, event := range found {
+		t := agent.ParseEventTime(event.Time)
+		if !t.Valid || t.Start.Before(latest.Start) || isDuplicate(event, existing) {
+			diff.Skipped++
+			continue
+		}
+		event.ID = strconv.Itoa(nextID)
+		nextID++
+		diff.Added = append(diff.Added, event)
+		existing = append(existing, event)
+	}
+	agent.SortEventsByTime(diff.Added)
+
+	logutil.LogInfo("增量更新「%s」: 自 %s 起新增 %d 个事件，丢弃 %d 个", timeline.Keyword, since, len(diff.Added), diff.Skipped)
+	return diff, nil
+}
+
+// Apply 将新增事件合并进时间链并按时间排序，已有事件的 ID 和内容保持不变
+// 新增事件按合并时的时间链重新分配 ID，避免搜索期间时间链被修改导致 ID 冲突
+func Apply(timeline *agent.TimelineResponse, diff *Diff) {
+	next := agent.NextEventID(timeline.Events)
+	for i := range diff.Added {
+		diff.Added[i].ID = strconv.Itoa(next)
+		next++
+	}
+	timeline.Events = append(timeline.Events, diff.Added...)
+	agent.SortEventsByTime(timeline.Events)
+}
+
+// RecencyFor 根据最新事件距今的时间选择深度搜索的时间范围
+func RecencyFor(latest, now time.Time) string {
+	age := now.Sub(latest)
+	switch {
+	case age <= 7*24*time.Hour:
+		return "week"
+	case age <= 31*24*time.Hour:
+		return "month"
+	case age <= 183*24*time.Hour:
+		return "semiyear"
+	default:
+		return "year"
+	}
+}
+
+// searchDeepSearch 使用百度深度搜索查找新事件，并将角标引用转换为事件来源
+func searchDeepSearch(systemPrompt, userPrompt, recency string) ([]agent.Event, error) {
+	req := model.NewDefaultRequest(systemPrompt + "\n\n" + userPrompt)
+	req.SearchRecencyFilter = recency
+	req.MaxCompletionTokens = 4096
+
+	resp, err := model.BaiduDeepSearch(req.Messages[0].Content, req)
+	if err != nil {
+		return nil, fmt.Errorf("深度搜索失败: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("深度搜索返回空结果")
+	}
+
+	events, err := parseEvents(resp.Choices[0].Message.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	references := make(map[int]model.Reference, len(resp.References))
+	for _, ref := range resp.References {
+		references[ref.ID] = ref
+	}
+	for i := range events {
+		for _, id := range cornerMarkerIDs(events[i].Title + events[i].Summary) {
+			if ref, ok := references[id]; ok {
+				events[i].Sources = appendSource(events[i].Sources, agent.EventSource{
+					Title:       ref.Title,
+					URL:         ref.URL,
+					SiteName:    ref.Website,
+					PublishTime: ref.Date,
+				})
+			}
+		}
+		events[i].Title = stripCornerMarkers(events[i].Title)
+		events[i].Summary = stripCornerMarkers(events[i].Summary)
+	}
+	return events, nil
+}
+
+// searchArk 使用 Ark 联网搜索查找新事件
+func searchArk(ctx context.Context, systemPrompt, userPrompt string) ([]agent.Event, error) {
+	modelID := os.Getenv("ARK_MODEL_ID")
+	if modelID == "" {
+		modelID = model.DefaultArkModel
+	}
+	resp, err := model.SendArkMessage(ctx, modelID, userPrompt, systemPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("Ark 联网搜索失败: %w", err)
+	}
+	return parseEvents(resp.Content)
+}
+
+// parseEvents 解析模型返回的事件 JSON，兼容外层包含说明文字或代码块的情况
+func parseEvents(content string) ([]agent.Event, error) {
+	content = strings.TrimSpace(content)
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("未找到 JSON 内容: %s", content)
+	}
+
+	var result searchResult
+	if err := json.Unmarshal([]byte(content[start:end+1]), &result); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %w, 原始内容: %s", err, content)
+	}
+	return result.Events, nil
+}
+
+// recentEventsText 列出最近的若干事件，提示模型避免重复
+func recentEventsText(events []agent.Event) string {
+	sorted := append([]agent.Event(nil), events...)
+	agent.SortEventsByTime(sorted)
+	if len(sorted) > recentEventsInPrompt {
+		sorted = sorted[len(sorted)-recentEventsInPrompt:]
+	}
+	var b strings.Builder
+	for _, e := range sorted {
+		fmt.Fprintf(&b, "- %s %s\n", e.Time, e.Title)
+	}
+	return b.String()
+}
+
+// isDuplicate 判断事件是否与已有事件重复：标题相同，或同一天且标题互相包含
+func isDuplicate(event agent.Event, existing []agent.Event) bool {
+	title := normalizeTitle(event.Title)
+	day := agent.ParseEventTime(event.Time).Start
+	for _, e := range existing {
+		other := normalizeTitle(e.Title)
+		if title == other {
+			return true
+		}
+		if agent.ParseEventTime(e.Time).Start.Equal(day) && (strings.Contains(title, other) || strings.Contains(other, title)) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTitle 去除标题中的空白和标点
+func normalizeTitle(title string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(" \t\n，。、：；！？“”‘’「」《》（）,.:;!?\"'()", r) {
+			return -1
+		}
+		return r
+	}, stripCornerMarkers(title))
+}
+
+var (
+	// cornerMarkerPattern 深度搜索的角标引用，如 ^[1]^、^[1][3]^
+	cornerMarkerPattern = regexp.MustCompile(`\^((?:\[\d+\])+)\^`)
+	cornerMarkerID      = regexp.MustCompile(`\[(\d+)\]`)
+)
+
+// cornerMarkerIDs 提取文本中的角标引用编号
+func cornerMarkerIDs(text string) []int {
+	var ids []int
+	for _, marker := range cornerMarkerPattern.FindAllStringSubmatch(text, -1) {
+		for _, m := range cornerMarkerID.FindAllStringSubmatch(marker[1], -1) {
+			if id, err := strconv.Atoi(m[1]); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// stripCornerMarkers 去除文本中的角标引用
+func stripCornerMarkers(text string) string {
+	return strings.TrimSpace(cornerMarkerPattern.ReplaceAllString(text, ""))
+}
+
+// appendSource 追加不重复的来源
+func appendSource(sources []agent.EventSource, source agent.EventSource) []agent.EventSource {
+	for _, s := range sources {
+		if s.URL == source.URL {
+			return sources
+		}
+	}
+	return append(sources, source)
+}
@@ -0,0 +1,206 @@
+package agent
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// TimelineVersion 专题记录的历史版本，每次保存时间链或图谱时生成
+type TimelineVersion struct {
+	RecordID  string            `json:"record_id"`
+	Version   int               `json:"version"`
+	Reason    string            `json:"reason"` // 生成原因，如 create、update、graph
+	Timeline  *TimelineResponse `json:"timeline,omitempty"`
+	Graph     *GraphResponse    `json:"graph,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// VersionSummary 版本列表中的摘要
+type VersionSummary struct {
+	Version    int       `json:"version"`
+	Reason     string    `json:"reason"`
+	EventCount int       `json:"event_count"`
+	NodeCount  int       `json:"node_count"`
+	LinkCount  int       `json:"link_count"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// VersionID 版本的存储 ID，补零以保证按 ID 排序即按版本排序
+func VersionID(version int) string {
+	return fmt.Sprintf("%06d", version)
+}
+
+// Snapshot 将记录当前的时间链和图谱保存为新版本，并递增记录的版本号
+func (r *TimelineRecord) Snapshot(reason string) *TimelineVersion {
+	r.Version++
+	return &TimelineVersion{
+		RecordID:  r.ID,
+		Version:   r.Version,
+		Reason:    reason,
+		Timeline:  r.Timeline,
+		Graph:     r.Graph,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Summary 返回版本摘要
+func (v *TimelineVersion) Summary() VersionSummary {
+	s := VersionSummary{Version: v.Version, Reason: v.Reason, CreatedAt: v.CreatedAt}
+	if v.Timeline != nil {
+		s.EventCount = len(v.Timeline.Events)
+	}
+	if v.Graph != nil {
+		s.NodeCount = len(v.Graph.Nodes)
+		s.LinkCount = len(v.Graph.Links)
+	}
+	return s
+}
+
+// Modification 同一 ID 在两个版本间的修改
+type Modification[T any] struct {
+	ID     string   `json:"id"`
+	Fields []string `json:"fields"` // 发生变化的字段（JSON 字段名）
+	Before T        `json:"before"`
+	After  T        `json:"after"`
+}
+
+// Changes 按 ID 匹配的新增、删除和修改
+type Changes[T any] struct {
+	Added    []T               `json:"added"`
+	Removed  []T               `json:"removed"`
+	Modified []Modification[T] `json:"modified"`
+}
+
+// VersionDiff 两个版本之间的差异
+type VersionDiff struct {
+	RecordID string             `json:"record_id"`
+	From     int                `json:"from"`
+	To       int                `json:"to"`
+	Events   Changes[Event]     `json:"events"`
+	Nodes    Changes[GraphNode] `json:"nodes"`
+}
+
+// DiffVersions 比较两个版本的事件和图谱节点，按 ID 匹配
+func DiffVersions(from, to *TimelineVersion) *VersionDiff {
+	diff := &VersionDiff{RecordID: to.RecordID, From: from.Version, To: to.Version}
+	diff.Events = diffByID(versionEvents(from), versionEvents(to), func(e Event) string { return e.ID })
+	diff.Nodes = diffByID(versionNodes(from), versionNodes(to), func(n GraphNode) string { return n.ID })
+	return diff
+}
+
+// versionEvents 返回版本中的事件
+func versionEvents(v *TimelineVersion) []Event {
+	if v.Timeline == nil {
+		return nil
+	}
+	return v.Timeline.Events
+}
+
+// versionNodes 返回版本中的图谱节点
+func versionNodes(v *TimelineVersion) []GraphNode {
+	if v.Graph == nil {
+		return nil
+	}
+	return v.Graph.Nodes
+}
+
+// diffByID 按 ID 比较两组元素，结果保持各自原有顺序
+func diffByID[T any](before, after []T, id func(T) string) Changes[T] {
+	changes := Changes[T]{Added: []T{}, Removed: []T{}, Modified: []Modification[T]{}}
+	previous := make(map[string]T, len(before))
+	for _, item := range before {
+		previous[id(item)] = item
+	}
+	current := make(map[string]bool, len(after))
+	for _, item := range after {
+		key := id(item)
+		current[key] = true
+		old, ok := previous[key]
+		if !ok {
+			changes.Added = append(changes.Added, item)
+			continue
+		}
+		if fields := changedFields(old, item); len(fields) > 0 {
+			changes.Modified = append(changes.Modified, Modification[T]{ID: key, Fields: fields, Before: old, After: item})
+		}
+	}
+	for _, item := range before {
+		if !current[id(item)] {
+			changes.Removed = append(changes.Removed, item)
+		}
+	}
+	return changes
+}
+
+// changedFields 逐字段比较两个结构体，返回不同字段的 JSON 名称
+func changedFields(a, b any) []string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	var fields []string
+	for i := 0; i < va.NumField(); i++ {
+		field := va.Type().Field(i)
+		if reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+// StabilizeNodeIDs 重新生成图谱后，让与旧图谱同名（或与其别名同名）的节点沿用旧 ID，并同步更新关系两端，使版本间可按 ID 比较
+// 新出现的节点若与旧 ID 冲突则重新分配
+func StabilizeNodeIDs(previous, graph *GraphResponse) {
+	if previous == nil || graph == nil {
+		return
+	}
+	byName := make(map[string]string, len(previous.Nodes))
+	used := make(map[string]bool, len(previous.Nodes))
+	for _, node := range previous.Nodes {
+		for _, alias := range node.Aliases {
+			byName[strings.ToLower(strings.TrimSpace(alias))] = node.ID
+		}
+		used[node.ID] = true
+	}
+	for _, node := range previous.Nodes {
+		byName[strings.ToLower(strings.TrimSpace(node.Name))] = node.ID
+	}
+
+	mapping := make(map[string]string, len(graph.Nodes))
+	taken := make(map[string]bool, len(graph.Nodes))
+	var fresh []int
+	for i, node := range graph.Nodes {
+		if id, ok := byName[strings.ToLower(strings.TrimSpace(node.Name))]; ok && !taken[id] {
+			mapping[node.ID] = id
+			graph.Nodes[i].ID = id
+			taken[id] = true
+			continue
+		}
+		fresh = append(fresh, i)
+	}
+	next := 1
+	for _, i := range fresh {
+		oldID := graph.Nodes[i].ID
+		newID := oldID
+		for newID == "" || used[newID] {
+			newID = fmt.Sprintf("n%d", next)
+			next++
+		}
+		used[newID] = true
+		mapping[oldID] = newID
+		graph.Nodes[i].ID = newID
+	}
+
+	for i, link := range graph.Links {
+		if id, ok := mapping[link.Source]; ok {
+			graph.Links[i].Source = id
+		}
+		if id, ok := mapping[link.Target]; ok {
+			graph.Links[i].Target = id
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffVersions(t *testing.T) {
+	from := &TimelineVersion{RecordID: "r1", Version: 1,
+		Timeline: &TimelineResponse{Events: []Event{
+			{ID: "1", Title: "发布会", Time: "2023-08-29"},
+			{ID: "2", Title: "出口管制", Time: "2023-10-17"},
+			{ID: "3", Title: "财报", Time: "2024-03-29"},
+		}},
+		Graph: &GraphResponse{Nodes: []GraphNode{{ID: "p1", Name: "张三"}, {ID: "p2", Name: "李四"}}},
+	}
+	to := &TimelineVersion{RecordID: "r1", Version: 2,
+		Timeline: &TimelineResponse{Events: []Event{
+			{ID: "1", Title: "发布会", Time: "2023-08-29"},
+			{ID: "3", Title: "年度财报", Time: "2024-03-31"},
+			{ID: "4", Title: "新品上市", Time: "2024-04-18"},
+		}},
+	}
+
+	diff := DiffVersions(from, to)
+	if diff.RecordID != "r1" || diff.From != 1 || diff.To != 2 {
+		t.Fatalf("版本信息错误: %+v", diff)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"新增事件", ids(diff.Events.Added), []string{"4"}},
+		{"删除事件", ids(diff.Events.Removed), []string{"2"}},
+		{"修改事件", diff.Events.Modified, []Modification[Event]{{
+			ID:     "3",
+			Fields: []string{"title", "time"},
+			Before: Event{ID: "3", Title: "财报", Time: "2024-03-29"},
+			After:  Event{ID: "3", Title: "年度财报", Time: "2024-03-31"},
+		}}},
+		{"新版本没有图谱时节点全部删除", nodeIDs(diff.Nodes.Removed), []string{"p1", "p2"}},
+		{"没有新增节点时为空数组", diff.Nodes.Added, []GraphNode{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %+v; want %+v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffByIDUnchanged(t *testing.T) {
+	events := []Event{{ID: "1", Title: "发布会", People: []string{"张三"}}}
+	changes := diffByID(events, []Event{{ID: "1", Title: "发布会", People: []string{"张三"}}}, func(e Event) string { return e.ID })
+	if len(changes.Added)+len(changes.Removed)+len(changes.Modified) != 0 {
+		t.Errorf("相同内容不应有差异: %+v", changes)
+	}
+}
+
+func TestStabilizeNodeIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		previous  *GraphResponse
+		graph     *GraphResponse
+		wantNodes []string // 节点 名称=ID
+		wantLinks []string // 关系 起点->终点
+	}{
+		{
+			name:      "同名节点沿用旧 ID",
+			previous:  &GraphResponse{Nodes: []GraphNode{{ID: "p1", Name: "张三"}, {ID: "l1", Name: "北京"}}},
+			graph:     &GraphResponse{Nodes: []GraphNode{{ID: "a", Name: "北京"}, {ID: "b", Name: "张三"}}, Links: []GraphLink{{Source: "b", Target: "a"}}},
+			wantNodes: []string{"北京=l1", "张三=p1"},
+			wantLinks: []string{"p1->l1"},
+		},
+		{
+			name:      "名称忽略大小写和首尾空白，别名也可匹配",
+			previous:  &GraphResponse{Nodes: []GraphNode{{ID: "c1", Name: "华为技术有限公司", Aliases: []string{"Huawei"}}}},
+			graph:     &GraphResponse{Nodes: []GraphNode{{ID: "x", Name: " HUAWEI "}}},
+			wantNodes: []string{" HUAWEI =c1"},
+		},
+		{
+			name:      "新节点与旧 ID 冲突时重新分配",
+			previous:  &GraphResponse{Nodes: []GraphNode{{ID: "p2", Name: "张三"}}},
+			graph:     &GraphResponse{Nodes: []GraphNode{{ID: "p1", Name: "张三"}, {ID: "p2", Name: "李四"}}, Links: []GraphLink{{Source: "p1", Target: "p2"}}},
+			wantNodes: []string{"张三=p2", "李四=n1"},
+			wantLinks: []string{"p2->n1"},
+		},
+		{
+			name:      "同名节点只有第一个沿用旧 ID",
+			previous:  &GraphResponse{Nodes: []GraphNode{{ID: "p1", Name: "张三"}}},
+			graph:     &GraphResponse{Nodes: []GraphNode{{ID: "a", Name: "张三"}, {ID: "b", Name: "张三"}}},
+			wantNodes: []string{"张三=p1", "张三=b"},
+		},
+		{
+			name:      "没有旧图谱时不做修改",
+			graph:     &GraphResponse{Nodes: []GraphNode{{ID: "a", Name: "张三"}}},
+			wantNodes: []string{"张三=a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			StabilizeNodeIDs(tt.previous, tt.graph)
+			var nodes, links []string
+			for _, node := range tt.graph.Nodes {
+				nodes = append(nodes, node.Name+"="+node.ID)
+			}
+			for _, link := range tt.graph.Links {
+				links = append(links, link.Source+"->"+link.Target)
+			}
+			if !reflect.DeepEqual(nodes, tt.wantNodes) {
+				t.Errorf("nodes = %v; want %v", nodes, tt.wantNodes)
+			}
+			if !reflect.DeepEqual(links, tt.wantLinks) {
+				t.Errorf("links = %v; want %v", links, tt.wantLinks)
+			}
+		})
+	}
+}
+
+// ids 返回事件 ID 列表
+func ids(events []Event) []string {
+	var result []string
+	for _, e := range events {
+		result = append(result, e.ID)
+	}
+	return result
+}
+
+// nodeIDs 返回节点 ID 列表
+func nodeIDs(nodes []GraphNode) []string {
+	var result []string
+	for _, n := range nodes {
+		result = append(result, n.ID)
+	}
+	return result
+}
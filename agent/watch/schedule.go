@@ -0,0 +1,62 @@
+package watch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MinInterval 最小刷新间隔，避免频繁调用 Ark 和百度接口
+const MinInterval = 10 * time.Minute
+
+// Schedule 刷新计划
+type Schedule interface {
+	// Next 返回 after 之后的下一次执行时间
+	Next(after time.Time) time.Time
+}
+
+// intervalSchedule 固定间隔执行，如 30m、6h、@every 2h
+type intervalSchedule time.Duration
+
+// Next 返回 after 加上间隔
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
+
+// alignedSchedule 在整点、每天零点或每周一零点执行，如 @hourly、@daily、@weekly
+type alignedSchedule string
+
+// Next 返回 after 之后的下一个对齐时间点（本地时区）
+func (s alignedSchedule) Next(after time.Time) time.Time {
+	switch s {
+	case "@hourly":
+		return after.Truncate(time.Hour).Add(time.Hour)
+	case "@weekly":
+		y, m, d := after.Date()
+		offset := (int(after.Weekday()) + 6) % 7 // 距本周一的天数
+		return time.Date(y, m, d-offset+7, 0, 0, 0, 0, after.Location())
+	default: // @daily
+		y, m, d := after.Date()
+		return time.Date(y, m, d+1, 0, 0, 0, 0, after.Location())
+	}
+}
+
+// ParseSchedule 解析刷新计划：支持 @hourly、@daily、@weekly、@every <时长> 和 Go 时长格式（如 6h、90m）
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "@hourly", "@daily", "@weekly":
+		return alignedSchedule(spec), nil
+	case "":
+		return nil, fmt.Errorf("刷新计划不能为空")
+	}
+
+	d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every")))
+	if err != nil {
+		return nil, fmt.Errorf("无效的刷新计划: %s", spec)
+	}
+	if d < MinInterval {
+		return nil, fmt.Errorf("刷新间隔不能小于 %s", MinInterval)
+	}
+	return intervalSchedule(d), nil
+}
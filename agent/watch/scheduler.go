@@ -0,0 +1,291 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"lineNews/agent"
+	"lineNews/agent/logutil"
+	"lineNews/store"
+)
+
+// 调度参数默认值
+const (
+	DefaultConcurrency = 2                // 同时刷新的专题数
+	DefaultTick        = 30 * time.Second // 检查到期专题的间隔
+	DefaultRunTimeout  = 10 * time.Minute // 单次刷新超时
+	MaxHistory         = 20               // 每个专题保留的刷新记录数
+)
+
+// ErrRunning 专题正在刷新
+var ErrRunning = errors.New("该专题正在刷新")
+
+// Item 关注列表中的专题
+type Item struct {
+	ID        string     `json:"id"`
+	Keyword   string     `json:"keyword"`
+	Mode      string     `json:"mode"`     // 首次生成时间链的模式
+	Schedule  string     `json:"schedule"` // 刷新计划，见 ParseSchedule
+	Source    string     `json:"source"`   // 增量搜索来源：deepsearch 或 ark
+	Recency   string     `json:"recency"`  // 深度搜索时间范围，为空时自动选择
+	RecordID  string     `json:"record_id,omitempty"`
+	Enabled   bool       `json:"enabled"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	NextRunAt time.Time  `json:"next_run_at"`
+	LastError string     `json:"last_error,omitempty"`
+	History   []Run      `json:"history"` // 最近的刷新记录，新的在前
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Run 一次刷新的结果
+type Run struct {
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt time.Time  `json:"finished_at"`
+	RecordID   string     `json:"record_id,omitempty"`
+	Created    bool       `json:"created"`     // 是否首次生成时间链
+	Added      []EventRef `json:"added"`       // 新增事件
+	AddedNodes []string   `json:"added_nodes"` // 图谱中新增的实体
+	Skipped    int        `json:"skipped"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// EventRef 刷新记录中的事件摘要
+type EventRef struct {
+	ID    string `json:"id"`
+	Time  string `json:"time"`
+	Title string `json:"title"`
+}
+
+// Result 刷新函数的返回结果
+type Result struct {
+	RecordID   string
+	Created    bool
+	Added      []agent.Event
+	AddedNodes []agent.GraphNode
+	Skipped    int
+}
+
+// RefreshFunc 刷新单个专题：无记录时生成时间链，否则增量更新已保存的时间链
+type RefreshFunc func(ctx context.Context, item *Item) (*Result, error)
+
+// RunHook 刷新结束后的回调，item 为已写入本次刷新记录的专题
+type RunHook func(item *Item, run Run)
+
+// Scheduler 关注列表的后台刷新调度器，按各专题的刷新计划定期执行，并限制同时刷新的数量
+type Scheduler struct {
+	items       *store.Collection[Item]
+	refresh     RefreshFunc
+	Tick        time.Duration
+	RunTimeout  time.Duration
+	OnRun       RunHook // 刷新结束后调用，可为空
+	concurrency chan struct{}
+
+	mu      sync.Mutex
+	running map[string]bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewScheduler 创建调度器，concurrency <= 0 时使用默认并发数
+func NewScheduler(items *store.Collection[Item], refresh RefreshFunc, concurrency int) *Scheduler {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	return &Scheduler{
+		items:       items,
+		refresh:     refresh,
+		Tick:        DefaultTick,
+		RunTimeout:  DefaultRunTimeout,
+		concurrency: make(chan struct{}, concurrency),
+		running:     make(map[string]bool),
+	}
+}
+
+// NewItem 创建关注专题，首次刷新立即执行
+func NewItem(id, keyword string) *Item {
+	now := time.Now()
+	return &Item{
+		ID:        id,
+		Keyword:   keyword,
+		Mode:      "fast",
+		Schedule:  "@daily",
+		Enabled:   true,
+		NextRunAt: now,
+		History:   []Run{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Start 启动后台调度
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.ctx, s.cancel = ctx, cancel
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.Tick)
+		defer ticker.Stop()
+		for {
+			s.runDue(ctx, time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	logutil.LogInfo("关注列表调度器已启动 (并发: %d)", cap(s.concurrency))
+}
+
+// Stop 停止调度并等待正在执行的刷新结束
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+// Trigger 立即刷新指定专题，不等待完成；专题正在刷新时返回 ErrRunning
+func (s *Scheduler) Trigger(id string) error {
+	s.mu.Lock()
+	ctx := s.ctx
+	s.mu.Unlock()
+	if ctx == nil {
+		return errors.New("调度器未启动")
+	}
+	if _, err := s.items.Get(id); err != nil {
+		return err
+	}
+	if !s.launch(ctx, id) {
+		return ErrRunning
+	}
+	return nil
+}
+
+// Running 返回专题是否正在刷新或排队
+func (s *Scheduler) Running(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running[id]
+}
+
+// runDue 启动所有到期专题的刷新
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	items, err := s.items.List()
+	if err != nil {
+		logutil.LogError("读取关注列表失败: %v", err)
+		return
+	}
+	for _, item := range items {
+		if item.Enabled && !item.NextRunAt.After(now) {
+			s.launch(ctx, item.ID)
+		}
+	}
+}
+
+// launch 在后台刷新专题，超过并发数时排队等待；专题已在刷新或排队时返回 false
+func (s *Scheduler) launch(ctx context.Context, id string) bool {
+	s.mu.Lock()
+	if s.running[id] {
+		s.mu.Unlock()
+		return false
+	}
+	s.running[id] = true
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, id)
+			s.mu.Unlock()
+		}()
+
+		select {
+		case s.concurrency <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		defer func() { <-s.concurrency }()
+		s.run(ctx, id)
+	}()
+	return true
+}
+
+// run 执行一次刷新并记录结果和下一次执行时间
+func (s *Scheduler) run(ctx context.Context, id string) {
+	item, err := s.items.Get(id)
+	if err != nil {
+		logutil.LogError("读取关注专题失败: %s: %v", id, err)
+		return
+	}
+
+	logutil.LogInfo("开始刷新关注专题: %s (%s)", item.Keyword, id)
+	run := Run{StartedAt: time.Now()}
+	runCtx, cancel := context.WithTimeout(ctx, s.RunTimeout)
+	result, err := s.refresh(runCtx, item)
+	cancel()
+	run.FinishedAt = time.Now()
+
+	if err != nil {
+		run.Error = err.Error()
+		logutil.LogError("刷新关注专题失败: %s: %v", item.Keyword, err)
+	} else {
+		run.RecordID = result.RecordID
+		run.Created = result.Created
+		run.Skipped = result.Skipped
+		run.Added = make([]EventRef, 0, len(result.Added))
+		for _, e := range result.Added {
+			run.Added = append(run.Added, EventRef{ID: e.ID, Time: e.Time, Title: e.Title})
+		}
+		run.AddedNodes = make([]string, 0, len(result.AddedNodes))
+		for _, n := range result.AddedNodes {
+			run.AddedNodes = append(run.AddedNodes, n.Name)
+		}
+		logutil.LogInfo("刷新关注专题完成: %s，新增 %d 个事件", item.Keyword, len(run.Added))
+	}
+
+	updated, err := s.items.Update(id, func(item *Item) error {
+		item.LastRunAt = &run.FinishedAt
+		item.LastError = run.Error
+		if run.RecordID != "" {
+			item.RecordID = run.RecordID
+		}
+		item.History = append([]Run{run}, item.History...)
+		if len(item.History) > MaxHistory {
+			item.History = item.History[:MaxHistory]
+		}
+		item.NextRunAt = NextRun(item.Schedule, run.FinishedAt)
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			logutil.LogError("保存刷新结果失败: %s: %v", id, err)
+		}
+		return
+	}
+	if s.OnRun != nil {
+		s.OnRun(updated, run)
+	}
+}
+
+// NextRun 按刷新计划计算下一次执行时间，计划无效时按每天执行
+func NextRun(spec string, after time.Time) time.Time {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		schedule = alignedSchedule("@daily")
+	}
+	return schedule.Next(after)
+}
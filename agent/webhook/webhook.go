@@ -0,0 +1,393 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"lineNews/agent/logutil"
+	"lineNews/agent/tool"
+	"lineNews/store"
+)
+
+// 事件类型
+const (
+	EventTimelineUpdated = "timeline.updated" // 时间链新增了事件（关注列表刷新或手动增量更新）
+	EventRefreshFinished = "refresh.finished" // 关注专题的一次刷新结束，无论成功与否
+	EventPing            = "ping"             // 测试投递
+)
+
+// Events 可订阅的事件类型
+var Events = []string{EventTimelineUpdated, EventRefreshFinished}
+
+// 请求头
+const (
+	HeaderEvent     = "X-LineNews-Event"
+	HeaderDelivery  = "X-LineNews-Delivery"
+	HeaderTimestamp = "X-LineNews-Timestamp"
+	HeaderSignature = "X-LineNews-Signature"
+)
+
+// 投递状态
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// DefaultBackoff 失败后的重试间隔，重试次数为其长度
+var DefaultBackoff = []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour}
+
+// ErrPending 投递仍在等待发送或重试
+var ErrPending = errors.New("投递仍在进行中")
+
+// DeliveryRetention 投递记录保留时长
+const DeliveryRetention = 30 * 24 * time.Hour
+
+// Subscription Webhook 订阅
+type Subscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"` // HMAC-SHA256 签名密钥，只在创建时返回
+	Events    []string  `json:"events"`           // 订阅的事件类型，为空时订阅全部
+	WatchIDs  []string  `json:"watch_ids"`        // 只接收这些关注专题的事件，为空时不限
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Matches 判断订阅是否接收指定事件
+func (s *Subscription) Matches(event, watchID string) bool {
+	if !s.Enabled {
+		return false
+	}
+	if event == EventPing {
+		return true
+	}
+	if len(s.Events) > 0 && !slices.Contains(s.Events, event) {
+		return false
+	}
+	return len(s.WatchIDs) == 0 || slices.Contains(s.WatchIDs, watchID)
+}
+
+// Validate 校验订阅地址和事件类型
+func (s *Subscription) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("无效的 Webhook 地址: %s", s.URL)
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && tool.IsPrivateIP(ip) {
+		return fmt.Errorf("Webhook 地址不能是内网地址: %s", s.URL)
+	}
+	for _, event := range s.Events {
+		if !slices.Contains(Events, event) {
+			return fmt.Errorf("不支持的事件类型: %s", event)
+		}
+	}
+	return nil
+}
+
+// Payload 投递的 JSON 内容
+type Payload struct {
+	ID        string    `json:"id"` // 投递 ID，重试时不变，可用于去重
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// Delivery 一次事件投递及其所有尝试
+type Delivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       []Attempt       `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// Attempt 单次投递尝试
+type Attempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Response   string    `json:"response,omitempty"` // 响应体前 512 字节
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// Sign 计算签名：HMAC-SHA256(secret, timestamp + "." + body) 的十六进制，请求头中以 sha256= 前缀发送
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher 事件分发器，为每个匹配的订阅创建投递记录并在后台发送，失败时按 Backoff 重试
+type Dispatcher struct {
+	subscriptions *store.Collection[Subscription]
+	deliveries    *store.Collection[Delivery]
+	Client        *http.Client
+	Backoff       []time.Duration
+	AllowPrivate  bool // 是否允许投递到内网地址，默认禁止以避免 SSRF
+
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher 创建事件分发器
+func NewDispatcher(subscriptions *store.Collection[Subscription], deliveries *store.Collection[Delivery]) *Dispatcher {
+	d := &Dispatcher{
+		subscriptions: subscriptions,
+		deliveries:    deliveries,
+		Backoff:       DefaultBackoff,
+	}
+	d.Client = &http.Client{
+		Timeout:   15 * time.Second,
+		Transport: tool.NewPublicTransport(func() bool { return d.AllowPrivate }),
+	}
+	return d
+}
+
+// Start 启动分发器：清理过期投递记录，并恢复上次退出时未完成的投递
+func (d *Dispatcher) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	d.mu.Lock()
+	d.ctx, d.cancel = ctx, cancel
+	d.mu.Unlock()
+
+	deliveries, err := d.deliveries.List()
+	if err != nil {
+		logutil.LogError("读取 Webhook 投递记录失败: %v", err)
+		return
+	}
+	resumed := 0
+	for _, delivery := range deliveries {
+		switch {
+		case delivery.Status == StatusPending:
+			d.schedule(delivery.ID, delivery.NextAttemptAt)
+			resumed++
+		case time.Since(delivery.CreatedAt) > DeliveryRetention:
+			if err := d.deliveries.Delete(delivery.ID); err != nil {
+				logutil.LogError("清理 Webhook 投递记录失败: %v", err)
+			}
+		}
+	}
+	logutil.LogInfo("Webhook 分发器已启动 (恢复投递: %d)", resumed)
+}
+
+// Stop 停止分发器，未完成的投递保持 pending 状态，下次启动时继续
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	cancel := d.cancel
+	d.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	d.wg.Wait()
+}
+
+// Publish 向所有匹配的订阅发送事件，返回创建的投递记录
+func (d *Dispatcher) Publish(event, watchID string, data any) []*Delivery {
+	subscriptions, err := d.subscriptions.List()
+	if err != nil {
+		logutil.LogError("读取 Webhook 订阅失败: %v", err)
+		return nil
+	}
+	var created []*Delivery
+	for _, sub := range subscriptions {
+		if !sub.Matches(event, watchID) {
+			continue
+		}
+		delivery, err := d.Send(sub.ID, event, data)
+		if err != nil {
+			logutil.LogError("创建 Webhook 投递失败: %s: %v", sub.ID, err)
+			continue
+		}
+		created = append(created, delivery)
+	}
+	return created
+}
+
+// Send 向指定订阅发送事件
+func (d *Dispatcher) Send(subscriptionID, event string, data any) (*Delivery, error) {
+	now := time.Now()
+	delivery := &Delivery{
+		ID:             store.NewID(),
+		SubscriptionID: subscriptionID,
+		Event:          event,
+		Status:         StatusPending,
+		Attempts:       []Attempt{},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	payload, err := json.Marshal(Payload{ID: delivery.ID, Event: event, CreatedAt: now, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("序列化 Webhook 内容失败: %w", err)
+	}
+	delivery.Payload = payload
+	if err := d.deliveries.Save(delivery.ID, delivery); err != nil {
+		return nil, err
+	}
+	d.schedule(delivery.ID, nil)
+	return delivery, nil
+}
+
+// Redeliver 重新发送已结束的投递，沿用原内容和投递 ID；投递仍在重试中时返回 ErrPending
+func (d *Dispatcher) Redeliver(id string) (*Delivery, error) {
+	delivery, err := d.deliveries.Update(id, func(delivery *Delivery) error {
+		if delivery.Status == StatusPending {
+			return ErrPending
+		}
+		delivery.Status = StatusPending
+		delivery.NextAttemptAt = nil
+		delivery.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.schedule(id, nil)
+	return delivery, nil
+}
+
+// schedule 在 at 时刻（为空时立即）后台投递
+func (d *Dispatcher) schedule(id string, at *time.Time) {
+	d.mu.Lock()
+	ctx := d.ctx
+	d.mu.Unlock()
+	if ctx == nil {
+		logutil.LogError("Webhook 分发器未启动，投递保持待发送: %s", id)
+		return
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		if at != nil {
+			timer := time.NewTimer(time.Until(*at))
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+		for d.attempt(ctx, id) {
+		}
+	}()
+}
+
+// attempt 执行一次投递尝试并记录结果；失败时等待退避间隔后返回 true 以继续重试，投递结束或分发器停止时返回 false
+func (d *Dispatcher) attempt(ctx context.Context, id string) bool {
+	delivery, err := d.deliveries.Get(id)
+	if err != nil || delivery.Status != StatusPending {
+		return false
+	}
+	sub, err := d.subscriptions.Get(delivery.SubscriptionID)
+	if err != nil {
+		d.finish(id, Attempt{At: time.Now(), Error: fmt.Sprintf("订阅不可用: %v", err)}, StatusFailed, nil)
+		return false
+	}
+
+	result := d.post(ctx, sub, delivery)
+	if ctx.Err() != nil {
+		return false
+	}
+	if result.Error == "" {
+		d.finish(id, result, StatusSucceeded, nil)
+		return false
+	}
+
+	retries := len(delivery.Attempts)
+	if retries >= len(d.Backoff) {
+		d.finish(id, result, StatusFailed, nil)
+		logutil.LogError("Webhook 投递失败，已放弃: %s -> %s: %s", delivery.Event, sub.URL, result.Error)
+		return false
+	}
+	next := time.Now().Add(d.Backoff[retries])
+	d.finish(id, result, StatusPending, &next)
+	logutil.LogInfo("Webhook 投递失败，%s 后重试: %s -> %s: %s", d.Backoff[retries], delivery.Event, sub.URL, result.Error)
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// post 发送签名后的请求，非 2xx 响应视为失败
+func (d *Dispatcher) post(ctx context.Context, sub *Subscription, delivery *Delivery) (attempt Attempt) {
+	attempt = Attempt{At: time.Now()}
+	defer func() { attempt.DurationMs = time.Since(attempt.At).Milliseconds() }()
+
+	// 存储时内容可能被重新缩进，发送前压缩，保证签名与请求体一致且每次重试相同
+	var body bytes.Buffer
+	if err := json.Compact(&body, delivery.Payload); err != nil {
+		attempt.Error = fmt.Sprintf("投递内容无效: %v", err)
+		return attempt
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body.Bytes()))
+	if err != nil {
+		attempt.Error = fmt.Sprintf("创建HTTP请求失败: %v", err)
+		return attempt
+	}
+	timestamp := strconv.FormatInt(attempt.At.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LineNews-Webhook/1.0")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, body.Bytes()))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	attempt.StatusCode = resp.StatusCode
+	attempt.Response = string(respBody)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		attempt.Error = fmt.Sprintf("状态码: %d", resp.StatusCode)
+	}
+	return attempt
+}
+
+// finish 追加尝试记录并更新投递状态
+func (d *Dispatcher) finish(id string, attempt Attempt, status string, next *time.Time) {
+	_, err := d.deliveries.Update(id, func(delivery *Delivery) error {
+		delivery.Attempts = append(delivery.Attempts, attempt)
+		delivery.Status = status
+		delivery.NextAttemptAt = next
+		delivery.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		logutil.LogError("保存 Webhook 投递记录失败: %s: %v", id, err)
+	}
+}
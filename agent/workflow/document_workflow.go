@@ -0,0 +1,144 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"lineNews/agent/logutil"
+	"lineNews/agent/prompt"
+	"lineNews/agent/tool"
+)
+
+// 文档切分参数
+const (
+	DocumentChunkRunes   = 6000 // 单个片段的最大字符数
+	DocumentChunkOverlap = 200  // 相邻片段重叠的字符数
+	MaxDocumentChunks    = 40   // 单次请求最多处理的片段数
+	documentConcurrency  = 3
+)
+
+// ErrTooManyDocumentChunks 文档切分后的片段数超过 MaxDocumentChunks
+var ErrTooManyDocumentChunks = errors.New("文档内容过长")
+
+// documentChunk 待抽取的文档片段
+type documentChunk struct {
+	doc   *tool.Document
+	index int
+	total int
+	text  string
+}
+
+// GenerateFromDocuments 从用户提供的文档中抽取时间链事件
+// 长文档按段落切分后逐段抽取，每个事件附带其来源文档；结果按文档和片段顺序返回，不做去重和排序
+// 片段数超过 MaxDocumentChunks 时不做截断，直接返回 ErrTooManyDocumentChunks
+// 抽取使用时间链生成的系统提示词，文档相关的要求写在 timeline_document_user 模板中；lang 为输出语言，为空时使用中文
+func (w *TimelineWorkflow) GenerateFromDocuments(ctx context.Context, keyword, lang string, docs []*tool.Document) (*TimelineResponse, error) {
+	var chunks []documentChunk
+	for _, doc := range docs {
+		parts := tool.ChunkText(doc.Content, DocumentChunkRunes, DocumentChunkOverlap)
+		for i, part := range parts {
+			chunks = append(chunks, documentChunk{doc: doc, index: i + 1, total: len(parts), text: part})
+		}
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("文档内容为空")
+	}
+	if len(chunks) > MaxDocumentChunks {
+		return nil, fmt.Errorf("%w: 共 %d 个片段（每段约 %d 字），上限为 %d 个，请减少文档数量或长度",
+			ErrTooManyDocumentChunks, len(chunks), DocumentChunkRunes, MaxDocumentChunks)
+	}
+	prompts := prompt.FromContext(ctx)
+
+	logutil.LogInfo("开始从 %d 篇文档（%d 个片段）抽取时间链", len(docs), len(chunks))
+
+	results := make([]*TimelineResponse, len(chunks))
+	errs := make([]error, len(chunks))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, documentConcurrency)
+	for i := range chunks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], errs[i] = w.extractChunk(ctx, prompts, keyword, lang, chunks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	timeline := &TimelineResponse{Keyword: keyword, PromptVersion: prompts.Version}
+	if lang != "" && lang != prompt.LangZH {
+		timeline.Lang = lang
+	}
+	failed := 0
+	for i, result := range results {
+		if errs[i] != nil {
+			failed++
+			logutil.LogError("文档片段抽取失败: %s (%d/%d): %v", chunks[i].doc.Title, chunks[i].index, chunks[i].total, errs[i])
+			continue
+		}
+		if timeline.Keyword == "" {
+			timeline.Keyword = result.Keyword
+		}
+		timeline.Events = append(timeline.Events, result.Events...)
+	}
+	if failed == len(chunks) {
+		return nil, fmt.Errorf("所有文档片段抽取失败: %w", errs[0])
+	}
+
+	logutil.LogInfo("文档抽取完成，共 %d 个事件（失败片段 %d 个）", len(timeline.Events), failed)
+	return timeline, nil
+}
+
+// extractChunk 抽取单个文档片段中的事件，并附加来源信息
+func (w *TimelineWorkflow) extractChunk(ctx context.Context, prompts *prompt.Set, keyword, lang string, chunk documentChunk) (*TimelineResponse, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "文档标题：%s\n", chunk.doc.Title)
+	if chunk.doc.PublishTime != "" {
+		fmt.Fprintf(&b, "发布时间：%s\n", chunk.doc.PublishTime)
+	}
+	if chunk.total > 1 {
+		fmt.Fprintf(&b, "以下是文档的第 %d/%d 个片段。\n", chunk.index, chunk.total)
+	}
+	fmt.Fprintf(&b, "文档内容：\n%s", chunk.text)
+
+	vars := prompt.Vars{Keyword: keyword, Lang: lang, Input: b.String()}
+	systemPrompt, err := prompts.Render(prompt.TimelineGenerationSystem, lang, vars)
+	if err != nil {
+		return nil, err
+	}
+	userPrompt, err := prompts.Render(prompt.TimelineDocumentUser, lang, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var timeline TimelineResponse
+	err = w.llmCaller.CallAndUnmarshal(
+		ctx,
+		systemPrompt,
+		userPrompt,
+		"文档时间链抽取",
+		&timeline,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("抽取文档事件失败: %w", err)
+	}
+
+	source := EventSource{
+		Title:       chunk.doc.Title,
+		URL:         chunk.doc.URL,
+		SiteName:    chunk.doc.SiteName,
+		PublishTime: chunk.doc.PublishTime,
+	}
+	for i := range timeline.Events {
+		timeline.Events[i].Sources = []EventSource{source}
+		// 文档没有给出时间时，以发布日期作为事件时间
+		if timeline.Events[i].Time == "" && len(chunk.doc.PublishTime) >= 10 {
+			timeline.Events[i].Time = chunk.doc.PublishTime[:10]
+		}
+	}
+	return &timeline, nil
+}
@@ -12,39 +12,61 @@ import (
 
 // Event 事件数据结构（workflow包中的定义）
 type Event struct {
-	ID       string   `json:"id"`
-	Title    string   `json:"title"`
-	Time     string   `json:"time"`
-	Location string   `json:"location"`
-	People   []string `json:"people"`
-	Summary  string   `json:"summary"`
+	ID       string        `json:"id"`
+	Title    string        `json:"title"`
+	Time     string        `json:"time"`
+	Location string        `json:"location"`
+	People   []string      `json:"people"`
+	Summary  string        `json:"summary"`
+	Sources  []EventSource `json:"sources,omitempty"`
+}
+
+// EventSource 事件来源（workflow包中的定义）
+type EventSource struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	SiteName    string `json:"site_name,omitempty"`
+	PublishTime string `json:"publish_time,omitempty"`
 }
 
 // TimelineResponse 时间链响应（workflow包中的定义）
 type TimelineResponse struct {
-	Keyword string  `json:"keyword"`
-	Events  []Event `json:"events"`
+	Keyword       string  `json:"keyword"`
+	Lang          string  `json:"lang,omitempty"`
+	PromptVersion string  `json:"prompt_version,omitempty"`
+	Events        []Event `json:"events"`
 }
 
 // GraphResponse 图谱响应结构（从types.go复制）
 type GraphResponse struct {
-	Keyword string      `json:"keyword"`
-	Nodes   []GraphNode `json:"nodes"`
-	Links   []GraphLink `json:"links"`
+	Keyword       string      `json:"keyword"`
+	PromptVersion string      `json:"prompt_version,omitempty"`
+	Nodes         []GraphNode `json:"nodes"`
+	Links         []GraphLink `json:"links"`
 }
 
 // GraphNode 图谱节点（从types.go复制）
 type GraphNode struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Category string `json:"category"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Category    string            `json:"category"`
+	Description string            `json:"description,omitempty"`
+	Aliases     []string          `json:"aliases,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	FirstSeen   string            `json:"first_seen,omitempty"`
+	LastSeen    string            `json:"last_seen,omitempty"`
+	Importance  float64           `json:"importance,omitempty"`
 }
 
 // GraphLink 图谱连接（从types.go复制）
 type GraphLink struct {
-	Source   string `json:"source"`
-	Target   string `json:"target"`
-	Relation string `json:"relation"`
+	Source   string   `json:"source"`
+	Target   string   `json:"target"`
+	Relation string   `json:"relation"`
+	Weight   float64  `json:"weight,omitempty"`
+	Time     string   `json:"time,omitempty"`
+	Directed bool     `json:"directed,omitempty"`
+	EventIDs []string `json:"event_ids,omitempty"`
 }
 
 // GraphWorkflow 知识图谱生成工作流
@@ -65,8 +87,11 @@ func (w *GraphWorkflow) Generate(ctx context.Context, timeline *TimelineResponse
 		return nil, fmt.Errorf("时间链为空")
 	}
 
+	// 整个生成过程使用同一版本的提示词模板
+	prompts := prompt.FromContext(ctx)
+
 	// 第一步：初次生成
-	graph, err := w.generateInitial(ctx, timeline)
+	graph, err := w.generateInitial(ctx, prompts, timeline)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +101,7 @@ func (w *GraphWorkflow) Generate(ctx context.Context, timeline *TimelineResponse
 	for i := 0; i < maxGraphRefineRounds; i++ {
 		logutil.LogInfo("第 %d 轮反思优化开始，当前节点数: %d，边数: %d", i+1, len(graph.Nodes), len(graph.Links))
 
-		refinedGraph, err := w.refine(ctx, timeline.Keyword, graph)
+		refinedGraph, accepted, err := w.refine(ctx, prompts, timeline.Keyword, timeline.Lang, graph)
 		if err != nil {
 			logutil.LogError("第 %d 轮反思优化失败: %v", i+1, err)
 			break
@@ -88,6 +113,9 @@ func (w *GraphWorkflow) Generate(ctx context.Context, timeline *TimelineResponse
 
 		logutil.LogInfo("第 %d 轮反思优化后节点数: %d，边数: %d", i+1, len(refinedGraph.Nodes), len(refinedGraph.Links))
 		graph = refinedGraph
+		if accepted {
+			tool.CallStatsFrom(ctx).AddRefineRound()
+		}
 
 		// 如果节点数量已经在理想范围内，则提前结束循环
 		if len(graph.Nodes) >= 20 && len(graph.Nodes) <= 100 {
@@ -96,24 +124,33 @@ func (w *GraphWorkflow) Generate(ctx context.Context, timeline *TimelineResponse
 		}
 	}
 
+	graph.PromptVersion = prompts.Version
 	logutil.LogInfo("最终知识图谱生成完成，包含 %d 个节点和 %d 条边", len(graph.Nodes), len(graph.Links))
 	return graph, nil
 }
 
-// generateInitial 初次生成知识图谱
-func (w *GraphWorkflow) generateInitial(ctx context.Context, timeline *TimelineResponse) (*GraphResponse, error) {
+// generateInitial 初次生成知识图谱，提示词语言与时间链一致
+func (w *GraphWorkflow) generateInitial(ctx context.Context, prompts *prompt.Set, timeline *TimelineResponse) (*GraphResponse, error) {
 	// 将时间链转换为 JSON 字符串
 	timelineJSON, err := json.Marshal(timeline)
 	if err != nil {
 		return nil, fmt.Errorf("序列化时间链失败: %w", err)
 	}
 
-	userPrompt := fmt.Sprintf("请根据以下时间链构建知识图谱：\n%s", string(timelineJSON))
+	vars := prompt.Vars{Keyword: timeline.Keyword, Lang: timeline.Lang, Input: string(timelineJSON)}
+	systemPrompt, err := prompts.Render(prompt.GraphGenerationSystem, timeline.Lang, vars)
+	if err != nil {
+		return nil, err
+	}
+	userPrompt, err := prompts.Render(prompt.GraphGenerationUser, timeline.Lang, vars)
+	if err != nil {
+		return nil, err
+	}
 
 	var graph GraphResponse
 	err = w.llmCaller.CallAndUnmarshal(
 		ctx,
-		prompt.GraphGenerationSystemPrompt,
+		systemPrompt,
 		userPrompt,
 		"知识图谱初次生成",
 		&graph,
@@ -131,34 +168,38 @@ func (w *GraphWorkflow) generateInitial(ctx context.Context, timeline *TimelineR
 	return &graph, nil
 }
 
-// refine 反思优化知识图谱
-func (w *GraphWorkflow) refine(ctx context.Context, keyword string, original *GraphResponse) (*GraphResponse, error) {
+// refine 反思优化知识图谱，优化结果的节点数过少时返回原图谱，第二个返回值为 false
+func (w *GraphWorkflow) refine(ctx context.Context, prompts *prompt.Set, keyword, lang string, original *GraphResponse) (*GraphResponse, bool, error) {
 	if original == nil {
-		return nil, fmt.Errorf("原始知识图谱为空")
+		return nil, false, fmt.Errorf("原始知识图谱为空")
 	}
 
 	// 将原始知识图谱转换为 JSON 字符串
 	originalJSON, err := json.Marshal(original)
 	if err != nil {
-		return nil, fmt.Errorf("序列化原始知识图谱失败: %w", err)
+		return nil, false, fmt.Errorf("序列化原始知识图谱失败: %w", err)
 	}
 
-	userPrompt := fmt.Sprintf(
-		"下面是模型第一次为关键词「%s」生成的知识图谱 JSON：\n%s\n\n请在内部使用 ReAct 模式进行反思和推理，检查节点数量和内容是否满足上述要求，并在此基础上进行补充、合并和优化，生成最终的高质量知识图谱。请直接返回最终的 JSON，不要输出任何解释性文字。",
-		keyword,
-		string(originalJSON),
-	)
+	vars := prompt.Vars{Keyword: keyword, Lang: lang, Input: string(originalJSON)}
+	systemPrompt, err := prompts.Render(prompt.GraphRefinementSystem, lang, vars)
+	if err != nil {
+		return nil, false, err
+	}
+	userPrompt, err := prompts.Render(prompt.GraphRefinementUser, lang, vars)
+	if err != nil {
+		return nil, false, err
+	}
 
 	var refined GraphResponse
 	err = w.llmCaller.CallAndUnmarshal(
 		ctx,
-		prompt.GraphRefinementSystemPrompt,
+		systemPrompt,
 		userPrompt,
 		"知识图谱反思优化",
 		&refined,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("反思优化知识图谱失败: %w", err)
+		return nil, false, fmt.Errorf("反思优化知识图谱失败: %w", err)
 	}
 
 	if refined.Keyword == "" {
@@ -168,8 +209,8 @@ func (w *GraphWorkflow) refine(ctx context.Context, keyword string, original *Gr
 	// 再次做数量上的兜底校验，如果仍然过少，则保留原结果
 	if len(refined.Nodes) < 10 {
 		logutil.LogInfo("反思后节点数过少(%d)，保留原始知识图谱(%d)", len(refined.Nodes), len(original.Nodes))
-		return original, nil
+		return original, false, nil
 	}
 
-	return &refined, nil
+	return &refined, true, nil
 }
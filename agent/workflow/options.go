@@ -0,0 +1,78 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+
+	"lineNews/agent/prompt"
+)
+
+// GenerateOptions 时间链生成约束，写入初次生成和反思优化的提示词
+type GenerateOptions struct {
+	MustInclude []string // 必须包含的事件，每项为一行事件描述，如 "2023-03-01 某某发布会"
+	Exclude     []string // 排除的子话题
+	From        string   // 时间窗口起点，为空表示不限
+	To          string   // 时间窗口终点，为空表示不限
+	Language    string   // 输出语言代码或名称，如 zh、en
+}
+
+// IsZero 判断是否没有任何约束
+func (o *GenerateOptions) IsZero() bool {
+	return o == nil || len(o.MustInclude) == 0 && len(o.Exclude) == 0 && o.From == "" && o.To == "" && o.Language == ""
+}
+
+// lang 返回选择提示词译本的语言，Language 不是有译本的语言时使用中文提示词并通过约束说明指定输出语言
+func (o *GenerateOptions) lang() string {
+	if o == nil {
+		return prompt.LangZH
+	}
+	lang, err := prompt.NormalizeLang(o.Language)
+	if err != nil {
+		return prompt.LangZH
+	}
+	return lang
+}
+
+// outputLang 返回输出语言代码，记录在生成的时间链中
+func (o *GenerateOptions) outputLang() string {
+	if o == nil || strings.TrimSpace(o.Language) == "" {
+		return prompt.LangZH
+	}
+	if lang, err := prompt.NormalizeLang(o.Language); err == nil {
+		return lang
+	}
+	return strings.ToLower(strings.TrimSpace(o.Language))
+}
+
+// promptSection 生成追加在用户提示词之后的约束说明，没有约束时返回空字符串
+func (o *GenerateOptions) promptSection() string {
+	if o.IsZero() {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(prompt.TimelineConstraintsHeader)
+	if len(o.MustInclude) > 0 {
+		lines := make([]string, len(o.MustInclude))
+		for i, item := range o.MustInclude {
+			lines[i] = "  " + item
+		}
+		fmt.Fprintf(&b, prompt.TimelineMustIncludeTemplate, strings.Join(lines, "\n"))
+	}
+	if len(o.Exclude) > 0 {
+		fmt.Fprintf(&b, prompt.TimelineExcludeTemplate, strings.Join(o.Exclude, "、"))
+	}
+	if o.From != "" || o.To != "" {
+		from, to := o.From, o.To
+		if from == "" {
+			from = "最早"
+		}
+		if to == "" {
+			to = "现在"
+		}
+		fmt.Fprintf(&b, prompt.TimelineWindowTemplate, from, to)
+	}
+	if o.Language != "" {
+		fmt.Fprintf(&b, prompt.TimelineLanguageTemplate, prompt.LangName(strings.ToLower(strings.TrimSpace(o.Language))))
+	}
+	return b.String()
+}
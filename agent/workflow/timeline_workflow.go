@@ -24,8 +24,17 @@ func NewTimelineWorkflow(llmCaller *tool.LLMCaller) *TimelineWorkflow {
 
 // Generate 生成时间链（包含初次生成和反思优化）
 func (w *TimelineWorkflow) Generate(ctx context.Context, keyword string) (*TimelineResponse, error) {
+	return w.GenerateWithOptions(ctx, keyword, nil)
+}
+
+// GenerateWithOptions 按约束生成时间链，约束会写入初次生成和每一轮反思优化的提示词
+// 这里只负责引导模型，时间窗口等约束的强制过滤由调用方完成；opts 为 nil 时等同于 Generate
+// 整个生成过程使用同一版本的提示词模板，版本记录在结果的 PromptVersion 中
+func (w *TimelineWorkflow) GenerateWithOptions(ctx context.Context, keyword string, opts *GenerateOptions) (*TimelineResponse, error) {
+	prompts := prompt.FromContext(ctx)
+
 	// 第一步：初次生成
-	timeline, err := w.generateInitial(ctx, keyword)
+	timeline, err := w.generateInitial(ctx, prompts, keyword, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +44,7 @@ func (w *TimelineWorkflow) Generate(ctx context.Context, keyword string) (*Timel
 	for i := 0; i < maxRefineRounds; i++ {
 		logutil.LogInfo("第 %d 轮反思优化开始，当前事件数: %d", i+1, len(timeline.Events))
 
-		refinedTimeline, err := w.refine(ctx, keyword, timeline)
+		refinedTimeline, accepted, err := w.refine(ctx, prompts, keyword, timeline, opts)
 		if err != nil {
 			logutil.LogError("第 %d 轮反思优化失败: %v", i+1, err)
 			break
@@ -47,6 +56,9 @@ func (w *TimelineWorkflow) Generate(ctx context.Context, keyword string) (*Timel
 
 		logutil.LogInfo("第 %d 轮反思优化后事件数: %d", i+1, len(refinedTimeline.Events))
 		timeline = refinedTimeline
+		if accepted {
+			tool.CallStatsFrom(ctx).AddRefineRound()
+		}
 
 		// 如果事件数量已经在理想范围内，则提前结束循环
 		if len(timeline.Events) >= 15 && len(timeline.Events) <= 100 {
@@ -55,18 +67,32 @@ func (w *TimelineWorkflow) Generate(ctx context.Context, keyword string) (*Timel
 		}
 	}
 
+	if lang := opts.outputLang(); lang != prompt.LangZH {
+		timeline.Lang = lang
+	}
+	timeline.PromptVersion = prompts.Version
 	logutil.LogInfo("最终时间链生成完成，包含 %d 个事件", len(timeline.Events))
 	return timeline, nil
 }
 
 // generateInitial 初次生成时间链
-func (w *TimelineWorkflow) generateInitial(ctx context.Context, keyword string) (*TimelineResponse, error) {
-	userPrompt := fmt.Sprintf("请为关键词「%s」生成新闻时间链", keyword)
+func (w *TimelineWorkflow) generateInitial(ctx context.Context, prompts *prompt.Set, keyword string, opts *GenerateOptions) (*TimelineResponse, error) {
+	lang := opts.lang()
+	vars := prompt.Vars{Keyword: keyword, Lang: lang}
+	systemPrompt, err := prompts.Render(prompt.TimelineGenerationSystem, lang, vars)
+	if err != nil {
+		return nil, err
+	}
+	userPrompt, err := prompts.Render(prompt.TimelineGenerationUser, lang, vars)
+	if err != nil {
+		return nil, err
+	}
+	userPrompt += opts.promptSection()
 
 	var timeline TimelineResponse
-	err := w.llmCaller.CallAndUnmarshal(
+	err = w.llmCaller.CallAndUnmarshal(
 		ctx,
-		prompt.TimelineGenerationSystemPrompt,
+		systemPrompt,
 		userPrompt,
 		"时间链初次生成",
 		&timeline,
@@ -84,34 +110,40 @@ func (w *TimelineWorkflow) generateInitial(ctx context.Context, keyword string)
 	return &timeline, nil
 }
 
-// refine 反思优化时间链
-func (w *TimelineWorkflow) refine(ctx context.Context, keyword string, original *TimelineResponse) (*TimelineResponse, error) {
+// refine 反思优化时间链，优化结果的事件数过少时返回原时间链，第二个返回值为 false
+func (w *TimelineWorkflow) refine(ctx context.Context, prompts *prompt.Set, keyword string, original *TimelineResponse, opts *GenerateOptions) (*TimelineResponse, bool, error) {
 	if original == nil {
-		return nil, fmt.Errorf("原始时间链为空")
+		return nil, false, fmt.Errorf("原始时间链为空")
 	}
 
 	// 将原始时间链转换为 JSON 字符串
 	originalJSON, err := json.Marshal(original)
 	if err != nil {
-		return nil, fmt.Errorf("序列化原始时间链失败: %w", err)
+		return nil, false, fmt.Errorf("序列化原始时间链失败: %w", err)
 	}
 
-	userPrompt := fmt.Sprintf(
-		"下面是模型第一次为关键词「%s」生成的时间链 JSON：\n%s\n\n请在内部使用 ReAct 模式进行反思和推理，检查事件数量和内容是否满足上述要求，并在此基础上进行补充、合并和优化，生成最终的高质量时间链。请直接返回最终的 JSON，不要输出任何解释性文字。",
-		keyword,
-		string(originalJSON),
-	)
+	lang := opts.lang()
+	vars := prompt.Vars{Keyword: keyword, Lang: lang, Input: string(originalJSON)}
+	systemPrompt, err := prompts.Render(prompt.TimelineRefinementSystem, lang, vars)
+	if err != nil {
+		return nil, false, err
+	}
+	userPrompt, err := prompts.Render(prompt.TimelineRefinementUser, lang, vars)
+	if err != nil {
+		return nil, false, err
+	}
+	userPrompt += opts.promptSection()
 
 	var refined TimelineResponse
 	err = w.llmCaller.CallAndUnmarshal(
 		ctx,
-		prompt.TimelineRefinementSystemPrompt,
+		systemPrompt,
 		userPrompt,
 		"时间链反思优化",
 		&refined,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("反思优化时间链失败: %w", err)
+		return nil, false, fmt.Errorf("反思优化时间链失败: %w", err)
 	}
 
 	if refined.Keyword == "" {
@@ -121,8 +153,8 @@ func (w *TimelineWorkflow) refine(ctx context.Context, keyword string, original
 	// 再次做数量上的兜底校验，如果仍然远少于 15 条，则保留原结果
 	if len(refined.Events) < 5 {
 		logutil.LogInfo("反思后事件数过少(%d)，保留原始时间链(%d)", len(refined.Events), len(original.Events))
-		return original, nil
+		return original, false, nil
 	}
 
-	return &refined, nil
+	return &refined, true, nil
 }
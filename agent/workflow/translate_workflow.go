@@ -0,0 +1,139 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"lineNews/agent/logutil"
+	"lineNews/agent/prompt"
+	"lineNews/agent/tool"
+)
+
+// 每次翻译调用的条目数上限，避免单次输出过长被截断
+const (
+	translateEventBatch    = 20
+	translateNodeBatch     = 40
+	translateRelationBatch = 80
+)
+
+// TranslationEvent 待翻译的事件文本
+type TranslationEvent struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Location string   `json:"location"`
+	People   []string `json:"people"`
+	Summary  string   `json:"summary"`
+}
+
+// TranslationNode 待翻译的图谱节点文本
+type TranslationNode struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Category    string `json:"category"`
+	Description string `json:"description,omitempty"`
+}
+
+// TranslationBatch 待翻译的文本，翻译结果使用相同的结构
+type TranslationBatch struct {
+	Events    []TranslationEvent `json:"events"`
+	Nodes     []TranslationNode  `json:"nodes"`
+	Relations []string           `json:"relations"`
+}
+
+// TranslateWorkflow 时间链和图谱翻译工作流
+type TranslateWorkflow struct {
+	llmCaller *tool.LLMCaller
+}
+
+// NewTranslateWorkflow 创建翻译工作流
+func NewTranslateWorkflow(llmCaller *tool.LLMCaller) *TranslateWorkflow {
+	return &TranslateWorkflow{
+		llmCaller: llmCaller,
+	}
+}
+
+// Translate 将事件、节点和关系名称翻译为 lang 对应的语言，按条目数分批调用模型
+// 事件和节点按 ID 对应，关系按下标对应；某一批翻译失败时返回错误，模型漏掉的条目保留原文
+func (w *TranslateWorkflow) Translate(ctx context.Context, batch *TranslationBatch, lang string) (*TranslationBatch, error) {
+	systemPrompt, err := prompt.FromContext(ctx).Render(prompt.TranslationSystem, lang, prompt.Vars{Lang: lang})
+	if err != nil {
+		return nil, err
+	}
+	result := &TranslationBatch{
+		Events:    append([]TranslationEvent(nil), batch.Events...),
+		Nodes:     append([]TranslationNode(nil), batch.Nodes...),
+		Relations: append([]string(nil), batch.Relations...),
+	}
+
+	for start := 0; start < len(batch.Events); start += translateEventBatch {
+		end := min(start+translateEventBatch, len(batch.Events))
+		translated, err := w.call(ctx, systemPrompt, &TranslationBatch{Events: batch.Events[start:end]}, "事件翻译")
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]TranslationEvent, len(translated.Events))
+		for _, e := range translated.Events {
+			byID[e.ID] = e
+		}
+		for i := start; i < end; i++ {
+			e, ok := byID[result.Events[i].ID]
+			if !ok {
+				logutil.LogInfo("事件 %s 未返回译文，保留原文", result.Events[i].ID)
+				continue
+			}
+			if len(e.People) != len(result.Events[i].People) {
+				e.People = result.Events[i].People
+			}
+			result.Events[i] = e
+		}
+	}
+
+	for start := 0; start < len(batch.Nodes); start += translateNodeBatch {
+		end := min(start+translateNodeBatch, len(batch.Nodes))
+		translated, err := w.call(ctx, systemPrompt, &TranslationBatch{Nodes: batch.Nodes[start:end]}, "图谱节点翻译")
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]TranslationNode, len(translated.Nodes))
+		for _, n := range translated.Nodes {
+			byID[n.ID] = n
+		}
+		for i := start; i < end; i++ {
+			if n, ok := byID[result.Nodes[i].ID]; ok {
+				result.Nodes[i] = n
+			} else {
+				logutil.LogInfo("节点 %s 未返回译文，保留原文", result.Nodes[i].ID)
+			}
+		}
+	}
+
+	for start := 0; start < len(batch.Relations); start += translateRelationBatch {
+		end := min(start+translateRelationBatch, len(batch.Relations))
+		translated, err := w.call(ctx, systemPrompt, &TranslationBatch{Relations: batch.Relations[start:end]}, "关系翻译")
+		if err != nil {
+			return nil, err
+		}
+		if len(translated.Relations) != end-start {
+			logutil.LogInfo("关系译文数量不匹配（%d/%d），保留原文", len(translated.Relations), end-start)
+			continue
+		}
+		copy(result.Relations[start:end], translated.Relations)
+	}
+
+	logutil.LogInfo("翻译完成，事件 %d 个，节点 %d 个，关系 %d 种", len(result.Events), len(result.Nodes), len(result.Relations))
+	return result, nil
+}
+
+// call 翻译一批文本
+func (w *TranslateWorkflow) call(ctx context.Context, systemPrompt string, batch *TranslationBatch, stage string) (*TranslationBatch, error) {
+	batchJSON, err := json.Marshal(batch)
+	if err != nil {
+		return nil, fmt.Errorf("序列化待翻译文本失败: %w", err)
+	}
+	var translated TranslationBatch
+	if err := w.llmCaller.CallAndUnmarshal(ctx, systemPrompt, string(batchJSON), stage, &translated); err != nil {
+		return nil, fmt.Errorf("%s失败: %w", stage, err)
+	}
+	return &translated, nil
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 
 	"lineNews/agent/logutil"
 
@@ -20,6 +21,12 @@ type Config struct {
 	ArkModel              string
 	ArkBaseURL            string
 	ServerPort            string
+	ReportTemplateDir     string
+	ReportBrand           string
+	DataDir               string
+	WatchConcurrency      int
+	PromptTemplateDir     string
+	PromptReloadInterval  int // 提示词模板目录的检查间隔（秒），0 表示不热加载
 }
 
 // LoadConfig 从环境变量加载配置
@@ -39,6 +46,12 @@ func LoadConfig() *Config {
 		BaiduBaikeAPIKey:      getEnv("BAIDU_BAIKE_API_KEY", ""),
 		BaiduDeepSearchAPIKey: getEnv("BAIDU_DEEPSEARCH_API_KEY", ""),
 		ServerPort:            getEnv("SERVER_PORT", "8080"),
+		ReportTemplateDir:     getEnv("REPORT_TEMPLATE_DIR", ""),
+		ReportBrand:           getEnv("REPORT_BRAND", "LineNews"),
+		DataDir:               getEnv("DATA_DIR", "data"),
+		WatchConcurrency:      getEnvInt("WATCH_CONCURRENCY", 2),
+		PromptTemplateDir:     getEnv("PROMPT_TEMPLATE_DIR", "prompts"),
+		PromptReloadInterval:  getEnvInt("PROMPT_RELOAD_INTERVAL", 5),
 	}
 
 	return config
@@ -51,3 +64,17 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt 获取整数环境变量，不存在或无法解析时返回默认值
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		logutil.LogInfo("警告: 环境变量 %s 不是有效整数: %s", key, value)
+		return defaultValue
+	}
+	return n
+}
@@ -6,6 +6,7 @@ require (
 	github.com/cloudwego/eino v0.7.17
 	github.com/cloudwego/eino-ext/components/model/deepseek v0.1.1
 	github.com/gin-gonic/gin v1.11.0
+	github.com/joho/godotenv v1.5.1
 )
 
 require (
@@ -29,7 +30,6 @@ require (
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/goph/emperror v0.17.2 // indirect
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/klauspost/cpuid/v2 v2.3.0 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
@@ -9,6 +9,7 @@ import (
 	"os"
 
 	"lineNews/agent/logutil"
+	"lineNews/model"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,9 +30,9 @@ type Message struct {
 
 // ContentItem 消息内容项
 type ContentItem struct {
-	Type     string   `json:"type"`
-	Text     string   `json:"text,omitempty"`
-	ImageUrl ImageURL `json:"image_url,omitempty"`
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageUrl *ImageURL `json:"image_url,omitempty"`
 }
 
 // ImageURL 图像URL
@@ -75,6 +76,12 @@ func HandleArkChat(c *gin.Context) {
 
 	logutil.LogInfo("Ark Chat 请求: %s", message)
 
+	respondArkChat(c, message, "", []ContentItem{{Type: "text", Text: message}}, nil)
+}
+
+// respondArkChat 调用 Ark chat/completions 并返回结果，content 可包含文本和图片，stream=true 时以 SSE 返回
+// extra 不为空时，其返回的字段会合并到非流式响应的 data 中
+func respondArkChat(c *gin.Context, message string, systemPrompt string, content []ContentItem, extra func(responseText string) gin.H) {
 	// 从环境变量获取配置
 	apiKey := os.Getenv("ARK_API_KEY")
 	if apiKey == "" {
@@ -84,24 +91,34 @@ func HandleArkChat(c *gin.Context) {
 		return
 	}
 
+	// 流式响应
+	if isTrue(c.Query("stream")) {
+		var messages []model.MessageModel
+		for _, m := range arkMessages(systemPrompt, content) {
+			messages = append(messages, model.MessageModel(m))
+		}
+		streamChatResponse(c, model.ArkFlashModel, message, func(onDelta func(string) error) (string, int, int, int, error) {
+			resp, err := model.StreamArkChatCompletion(c.Request.Context(), &model.ArkChatCompletionRequest{
+				Model:               model.ArkFlashModel,
+				MaxCompletionTokens: 65535,
+				Messages:            messages,
+			}, onDelta)
+			if err != nil {
+				return "", 0, 0, 0, err
+			}
+			return resp.Content, resp.PromptTokens, resp.CompletionTokens, resp.TotalTokens, nil
+		})
+		return
+	}
+
 	var arkFlashModel = "doubao-seed-1-6-flash-250828" //Doubao-Seed-1.6-flash推理速度极致的多模态深度思考模型，TPOT低至10ms； 同时支持文本和视觉理解，文本理解能力超过上一代lite，视觉理解比肩友商pro系列模型。支持 256k 上下文窗口，输出长度支持最大 16k tokens。
 
-	// 构建请求体 - 纯文本消息
+	// 构建请求体 - 文本与图片
 	requestBody := ArkRequest{
 		Model:               arkFlashModel,
 		MaxCompletionTokens: 65535,
 		// ReasoningEffort:     "medium",
-		Messages: []Message{
-			{
-				Role: "user",
-				Content: []ContentItem{
-					{
-						Type: "text",
-						Text: message,
-					},
-				},
-			},
-		},
+		Messages: arkMessages(systemPrompt, content),
 	}
 
 	// 序列化请求体
@@ -198,19 +215,34 @@ func HandleArkChat(c *gin.Context) {
 	}
 
 	// 构建响应
+	data := gin.H{
+		"response": responseText,
+		"model":    arkFlashModel,
+		"usage": gin.H{
+			"prompt_tokens":     apiResp.Usage.PromptTokens,
+			"completion_tokens": apiResp.Usage.CompletionTokens,
+			"total_tokens":      apiResp.Usage.TotalTokens,
+		},
+	}
+	if extra != nil {
+		for k, v := range extra(responseText) {
+			data[k] = v
+		}
+	}
 	response := gin.H{
 		"success": true,
 		"message": message,
-		"data": gin.H{
-			"response": responseText,
-			"model":    arkFlashModel,
-			"usage": gin.H{
-				"prompt_tokens":     apiResp.Usage.PromptTokens,
-				"completion_tokens": apiResp.Usage.CompletionTokens,
-				"total_tokens":      apiResp.Usage.TotalTokens,
-			},
-		},
+		"data":    data,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// arkMessages 构建消息列表，有系统提示词时放在最前面
+func arkMessages(systemPrompt string, content []ContentItem) []Message {
+	var messages []Message
+	if systemPrompt != "" {
+		messages = append(messages, Message{Role: "system", Content: systemPrompt})
+	}
+	return append(messages, Message{Role: "user", Content: content})
+}
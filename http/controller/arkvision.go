@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"lineNews/agent/logutil"
+	"lineNews/agent/prompt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 多模态请求限制
+const (
+	maxChatImages     = 5
+	maxChatImageBytes = 10 << 20 // 单张图片 10MB
+)
+
+// 多模态对话任务
+const (
+	visionTaskChat         = "chat"          // 自由对话，直接使用用户消息
+	visionTaskSummary      = "summary"       // 概括图片中的新闻
+	visionTaskTimelineSeed = "timeline_seed" // 提取时间链种子关键词
+)
+
+// ArkVisionRequest 多模态对话 JSON 请求
+type ArkVisionRequest struct {
+	Message string   `json:"message"` // 文字说明或问题
+	Images  []string `json:"images"`  // 图片 URL 或 data URL
+	Task    string   `json:"task"`    // chat、summary 或 timeline_seed，默认 chat
+}
+
+// TimelineSeed 从图片中提取的时间链种子
+type TimelineSeed struct {
+	Keyword  string   `json:"keyword"`
+	Summary  string   `json:"summary"`
+	Time     string   `json:"time"`
+	Location string   `json:"location"`
+	People   []string `json:"people"`
+}
+
+// HandleArkChatMultimodal 处理带图片的 Ark Chat 请求
+// 支持 JSON（images 为 URL 或 data URL）和 multipart（images 为上传文件，image_urls 为图片 URL）两种格式
+func HandleArkChatMultimodal(c *gin.Context) {
+	req, err := bindArkVisionRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if req.Message == "" && len(req.Images) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "message 和 images 不能同时为空",
+		})
+		return
+	}
+	if len(req.Images) > maxChatImages {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("图片数量不能超过 %d 张", maxChatImages),
+		})
+		return
+	}
+
+	var systemPrompt string
+	var extra func(string) gin.H
+	message := req.Message
+	switch req.Task {
+	case "", visionTaskChat:
+		if message == "" {
+			message = "请描述这张图片的内容。"
+		}
+	case visionTaskSummary:
+		systemPrompt = prompt.ImageSummarySystemPrompt
+		if message == "" {
+			message = "请概括图片中的新闻内容。"
+		}
+	case visionTaskTimelineSeed:
+		systemPrompt = prompt.ImageTimelineSeedSystemPrompt
+		if message == "" {
+			message = "请从图片中提取新闻时间链的种子信息。"
+		}
+		extra = timelineSeedData
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("不支持的任务类型: %s", req.Task),
+		})
+		return
+	}
+
+	content := []ContentItem{{Type: "text", Text: message}}
+	for _, image := range req.Images {
+		content = append(content, ContentItem{Type: "image_url", ImageUrl: &ImageURL{URL: image}})
+	}
+
+	logutil.LogInfo("Ark 多模态请求: %s (任务: %s, 图片: %d 张)", message, req.Task, len(req.Images))
+
+	respondArkChat(c, message, systemPrompt, content, extra)
+}
+
+// bindArkVisionRequest 解析 JSON 或 multipart 请求，上传的图片转换为 data URL
+func bindArkVisionRequest(c *gin.Context) (*ArkVisionRequest, error) {
+	req := &ArkVisionRequest{}
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		req.Message = c.PostForm("message")
+		req.Task = c.PostForm("task")
+		req.Images = append(req.Images, c.PostFormArray("image_urls")...)
+
+		form, err := c.MultipartForm()
+		if err != nil {
+			return nil, fmt.Errorf("解析上传表单失败: %w", err)
+		}
+		for _, file := range form.File["images"] {
+			dataURL, err := imageToDataURL(file)
+			if err != nil {
+				return nil, err
+			}
+			req.Images = append(req.Images, dataURL)
+		}
+	} else if err := c.ShouldBindJSON(req); err != nil {
+		return nil, fmt.Errorf("请求参数错误: %w", err)
+	}
+
+	for _, image := range req.Images {
+		if err := validateImageURL(image); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// imageToDataURL 读取上传的图片并转换为 base64 data URL
+func imageToDataURL(file *multipart.FileHeader) (string, error) {
+	if file.Size > maxChatImageBytes {
+		return "", fmt.Errorf("图片 %s 超过 %dMB 限制", file.Filename, maxChatImageBytes>>20)
+	}
+	f, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("读取图片失败: %w", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxChatImageBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("读取图片失败: %w", err)
+	}
+	if len(data) > maxChatImageBytes {
+		return "", fmt.Errorf("图片 %s 超过 %dMB 限制", file.Filename, maxChatImageBytes>>20)
+	}
+
+	// 以文件内容判断类型，不信任客户端声明的 Content-Type
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return "", fmt.Errorf("文件 %s 不是支持的图片格式: %s", file.Filename, contentType)
+	}
+	return fmt.Sprintf("data:%s;base64,%s", contentType, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// validateImageURL 校验图片地址，只允许 http(s) URL 和图片 data URL
+func validateImageURL(image string) error {
+	if strings.HasPrefix(image, "data:image/") {
+		return nil
+	}
+	u, err := url.Parse(image)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("无效的图片地址: %s", image)
+	}
+	return nil
+}
+
+// timelineSeedData 解析模型返回的时间链种子，并给出生成时间链的接口地址
+func timelineSeedData(responseText string) gin.H {
+	content := strings.TrimSpace(responseText)
+	if start := findJSONStart(content); start != -1 {
+		content = content[start:]
+		if end := findJSONEnd(content); end != -1 {
+			content = content[:end+1]
+		}
+	}
+
+	var seed TimelineSeed
+	if err := json.Unmarshal([]byte(content), &seed); err != nil {
+		logutil.LogError("解析时间链种子失败: %v", err)
+		return gin.H{"seed": nil}
+	}
+
+	data := gin.H{"seed": seed}
+	if seed.Keyword != "" {
+		data["timeline_url"] = "/api/timeline?keyword=" + url.QueryEscape(seed.Keyword)
+	}
+	return data
+}
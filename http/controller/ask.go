@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"lineNews/agent/logutil"
+	"lineNews/agent/qa"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AskRequest 专题问答请求
+type AskRequest struct {
+	ID        string `json:"id" binding:"required"`       // 时间链/图谱记录 ID
+	Question  string `json:"question" binding:"required"` // 用户问题
+	Provider  string `json:"provider"`                    // ark 或 deepseek，默认 ark
+	MaxEvents int    `json:"max_events"`                  // 作为上下文的事件数上限，默认 12
+}
+
+// HandleAsk 基于已保存的时间链与知识图谱回答问题，回答中以 [事件ID] 标注引用
+func HandleAsk(c *gin.Context) {
+	var req AskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("请求参数错误: %v", err),
+		})
+		return
+	}
+
+	record, err := timelineRecords.Get(req.ID)
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+
+	logutil.LogInfo("专题问答请求: %s (记录: %s, 服务: %s)", req.Question, req.ID, req.Provider)
+
+	answer, err := qa.Ask(c.Request.Context(), req.Provider, record, req.Question, req.MaxEvents)
+	if err != nil {
+		logutil.LogError("专题问答失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    answer,
+	})
+}
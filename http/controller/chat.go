@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"lineNews/agent/chat"
+	"lineNews/agent/logutil"
+	"lineNews/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// chatSessions 对话会话存储
+var chatSessions = store.NewCollection[chat.Session]("chat_sessions")
+
+// CreateChatSessionRequest 创建对话会话请求
+type CreateChatSessionRequest struct {
+	Provider         string `json:"provider"`           // ark 或 deepseek，默认 ark
+	SystemPrompt     string `json:"system_prompt"`      // 系统提示词，可选
+	MaxContextTokens int    `json:"max_context_tokens"` // 发送给模型的历史 token 预算，可选
+}
+
+// ChatMessageRequest 发送对话消息请求
+type ChatMessageRequest struct {
+	Message string `json:"message" binding:"required"`
+}
+
+// HandleCreateChatSession 创建对话会话
+func HandleCreateChatSession(c *gin.Context) {
+	var req CreateChatSessionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("请求参数错误: %v", err),
+			})
+			return
+		}
+	}
+
+	session, err := chat.NewSession(store.NewID(), req.Provider, req.SystemPrompt, req.MaxContextTokens)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := chatSessions.Save(session.ID, session); err != nil {
+		logutil.LogError("保存对话会话失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	logutil.LogInfo("创建对话会话: %s (服务: %s)", session.ID, session.Provider)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    session,
+	})
+}
+
+// HandleGetChatSession 获取对话会话及完整历史
+func HandleGetChatSession(c *gin.Context) {
+	session, err := chatSessions.Get(c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    session,
+	})
+}
+
+// HandleDeleteChatSession 删除对话会话
+func HandleDeleteChatSession(c *gin.Context) {
+	if err := chatSessions.Delete(c.Param("id")); err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// HandleChatSessionMessage 在会话中发送消息，携带历史调用模型并保存本轮问答
+func HandleChatSessionMessage(c *gin.Context) {
+	id := c.Param("id")
+	var req ChatMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("请求参数错误: %v", err),
+		})
+		return
+	}
+
+	session, err := chatSessions.Get(id)
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+
+	logutil.LogInfo("会话消息请求: %s (服务: %s, 历史: %d 条)", id, session.Provider, len(session.Messages))
+
+	reply, err := chat.Send(c.Request.Context(), session, req.Message)
+	if err != nil {
+		logutil.LogError("发送会话消息失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("发送消息失败: %v", err),
+		})
+		return
+	}
+
+	// 在锁内追加，避免并发请求互相覆盖历史
+	_, err = chatSessions.Update(id, func(s *chat.Session) error {
+		s.Messages = append(s.Messages, chat.NewMessage("user", req.Message), chat.NewMessage("assistant", reply.Content))
+		s.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		logutil.LogError("保存会话历史失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": req.Message,
+		"data": gin.H{
+			"session_id":       id,
+			"response":         reply.Content,
+			"model":            reply.Provider,
+			"context_messages": reply.ContextMessages,
+			"trimmed_messages": reply.TrimmedMessages,
+			"usage": gin.H{
+				"prompt_tokens":     reply.PromptTokens,
+				"completion_tokens": reply.CompletionTokens,
+				"total_tokens":      reply.TotalTokens,
+			},
+		},
+	})
+}
+
+// respondStoreError 将存储错误转换为 HTTP 响应
+func respondStoreError(c *gin.Context, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, store.ErrInvalidID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	logutil.LogError("读取存储失败: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"lineNews/agent"
+	"lineNews/agent/compare"
+	"lineNews/agent/logutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxCompareTopics 单次对比的最大专题数
+const MaxCompareTopics = 5
+
+// HandleTimelineCompare 将多个专题的时间链对齐到同一时间轴上对比
+// keywords 以逗号分隔，重复的关键词只保留一个，使用各关键词最近保存的时间链，没有或 refresh=true 时生成并保存；
+// period 为时段粒度 auto（默认）、year、month、day，支持 from、to、location、person 过滤各专题的事件
+func HandleTimelineCompare(c *gin.Context) {
+	var keywords []string
+	seen := make(map[string]bool)
+	for _, keyword := range strings.Split(c.Query("keywords"), ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" && !seen[keyword] {
+			seen[keyword] = true
+			keywords = append(keywords, keyword)
+		}
+	}
+	if len(keywords) < 2 || len(keywords) > MaxCompareTopics {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("keywords 需要 2-%d 个以逗号分隔的关键词", MaxCompareTopics),
+		})
+		return
+	}
+	filter, ok := parseEventFilter(c)
+	if !ok {
+		return
+	}
+	period := c.DefaultQuery("period", compare.GranularityAuto)
+	if err := compare.ValidateGranularity(period); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	mode := c.Query("mode")
+	if mode == "" {
+		mode = "fast" // 默认模式
+	}
+	refresh := isTrue(c.Query("refresh"))
+
+	topics := make([]compare.Topic, 0, len(keywords))
+	for _, keyword := range keywords {
+		var record *agent.TimelineRecord
+		if !refresh {
+			var err error
+			record, err = latestRecord(keyword, func(r *agent.TimelineRecord) bool { return r.Timeline != nil })
+			if err != nil {
+				respondStoreError(c, err)
+				return
+			}
+		}
+		if record == nil {
+			// 生成失败时返回错误，避免 mock 数据之间互相比对
+			timeline, ok := loadTimeline(c, keyword, mode)
+			if !ok {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": fmt.Sprintf("生成关键词 %s 的时间链失败", keyword),
+				})
+				return
+			}
+			if record = saveTimelineRecord(keyword, mode, timeline, nil); record == nil {
+				record = agent.NewTimelineRecord("", keyword, mode, timeline, nil)
+			}
+		}
+		topics = append(topics, compare.Topic{
+			RecordID: record.ID,
+			Keyword:  keyword,
+			Timeline: filter.FilterTimeline(record.Timeline),
+			Graph:    record.Graph,
+		})
+	}
+
+	result, err := compare.Compare(topics, period)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	logutil.LogInfo("时间链对比: %s (时段: %d, 共同实体: %d)", strings.Join(keywords, ","), len(result.Periods), len(result.SharedEntities))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
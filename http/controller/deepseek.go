@@ -4,12 +4,17 @@ import (
 	"fmt"
 	"net/http"
 
+	"lineNews/agent/chat"
 	"lineNews/agent/logutil"
 	"lineNews/model"
 
+	"github.com/cloudwego/eino/schema"
 	"github.com/gin-gonic/gin"
 )
 
+// deepSeekSystemPrompt DeepSeek 对话的系统提示词
+const deepSeekSystemPrompt = "你是一个有用的AI助手，请回答用户的问题。"
+
 // HandleDeepSeekChat 处理 DeepSeek Chat 请求
 func HandleDeepSeekChat(c *gin.Context) {
 	message := c.Query("message")
@@ -22,10 +27,9 @@ func HandleDeepSeekChat(c *gin.Context) {
 
 	logutil.LogInfo("DeepSeek Chat 请求: %s", message)
 
-	// 创建 DeepSeek 聊天模型
+	// 获取共享的 DeepSeek 聊天模型
 	ctx := c.Request.Context()
-	config := model.NewDSModelConfig()
-	chatModel, err := model.CreateDSChatModel(ctx, config)
+	chatModel, err := model.SharedDSChatModel()
 	if err != nil {
 		logutil.LogError("创建 DeepSeek 模型失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -34,8 +38,32 @@ func HandleDeepSeekChat(c *gin.Context) {
 		return
 	}
 
+	// 流式响应
+	if isTrue(c.Query("stream")) {
+		messages := []*schema.Message{
+			schema.SystemMessage(deepSeekSystemPrompt),
+			schema.UserMessage(message),
+		}
+		streamChatResponse(c, "deepseek", message, func(onDelta func(string) error) (string, int, int, int, error) {
+			resp, err := model.StreamMessageWithHistory(ctx, chatModel, messages, onDelta)
+			if err != nil {
+				return "", 0, 0, 0, err
+			}
+			// DeepSeek 流式响应不返回使用统计，按对话上下文的方式估算
+			if resp.TotalTokens == 0 {
+				for _, msg := range messages {
+					resp.PromptTokens += chat.EstimateTokens(msg.Content)
+				}
+				resp.CompletionTokens = chat.EstimateTokens(resp.Content)
+				resp.TotalTokens = resp.PromptTokens + resp.CompletionTokens
+			}
+			return resp.Content, resp.PromptTokens, resp.CompletionTokens, resp.TotalTokens, nil
+		})
+		return
+	}
+
 	// 发送消息到 DeepSeek 模型
-	response, err := model.SendMessage(ctx, chatModel, message, "你是一个有用的AI助手，请回答用户的问题。")
+	response, err := model.SendMessage(ctx, chatModel, message, deepSeekSystemPrompt)
 	if err != nil {
 		logutil.LogError("发送消息到 DeepSeek 失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"lineNews/agent"
+	"lineNews/agent/logutil"
+	"lineNews/agent/tool"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTimelineDocuments 单次请求最多接受的文档数（含 URL）
+const maxTimelineDocuments = 20
+
+// documentFetcher 文档抓取器，可替换为其他实现
+var documentFetcher tool.DocumentFetcher = tool.NewHTTPDocumentFetcher()
+
+// SetDocumentFetcher 替换文档抓取器
+func SetDocumentFetcher(fetcher tool.DocumentFetcher) {
+	documentFetcher = fetcher
+}
+
+// TimelineDocumentInput 用户直接提交的文档
+type TimelineDocumentInput struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Format      string `json:"format"` // text 或 html，默认 text
+	Content     string `json:"content"`
+	PublishTime string `json:"publish_time"`
+}
+
+// TimelineFromDocumentsRequest 基于文档生成时间链请求
+type TimelineFromDocumentsRequest struct {
+	Keyword   string                  `json:"keyword"`   // 专题关键词，可选
+	Documents []TimelineDocumentInput `json:"documents"` // 原始文本或 HTML
+	URLs      []string                `json:"urls"`      // 需要抓取的文章地址
+}
+
+// generateTimelineFromDocuments 从文档生成时间链，lang 为输出语言
+func (am *AgentManager) generateTimelineFromDocuments(ctx context.Context, keyword, lang string, docs []*tool.Document) (*agent.TimelineResponse, error) {
+	logutil.LogInfo("开始从文档生成时间链: %s (文档数: %d)", keyword, len(docs))
+	if am == nil || am.agent == nil {
+		return nil, fmt.Errorf("Agent 未初始化")
+	}
+	return am.agent.GenerateTimelineFromDocuments(ctx, keyword, lang, docs)
+}
+
+// HandleTimelineFromDocuments 基于用户提供的文章或 URL 列表生成时间链，每个事件附带来源文档
+func HandleTimelineFromDocuments(c *gin.Context) {
+	var req TimelineFromDocumentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("请求参数错误: %v", err),
+		})
+		return
+	}
+	lang, ok := parseLang(c)
+	if !ok {
+		return
+	}
+	total := len(req.Documents) + len(req.URLs)
+	if total == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "documents 和 urls 不能同时为空",
+		})
+		return
+	}
+	if total > maxTimelineDocuments {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("文档数量不能超过 %d 篇", maxTimelineDocuments),
+		})
+		return
+	}
+
+	logutil.LogInfo("文档时间链请求: %s (文档: %d, URL: %d)", req.Keyword, len(req.Documents), len(req.URLs))
+
+	ctx := c.Request.Context()
+	var docs []*tool.Document
+	for i, input := range req.Documents {
+		doc, err := documentFromInput(input, i)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		docs = append(docs, doc)
+	}
+
+	if len(req.URLs) > 0 {
+		fetched, err := tool.FetchDocuments(ctx, documentFetcher, req.URLs, 4)
+		if err != nil {
+			logutil.LogError("抓取文档失败: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		docs = append(docs, fetched...)
+	}
+
+	timeline, err := agentManager.generateTimelineFromDocuments(ctx, req.Keyword, lang, docs)
+	if errors.Is(err, agent.ErrDocumentsTooLong) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		logutil.LogError("从文档生成时间链失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	saveTimelineRecord(timeline.Keyword, "documents", timeline, nil)
+	c.JSON(http.StatusOK, timeline)
+}
+
+// documentFromInput 将用户提交的文档转换为纯文本文档
+func documentFromInput(input TimelineDocumentInput, index int) (*tool.Document, error) {
+	doc := &tool.Document{
+		Title:       input.Title,
+		URL:         input.URL,
+		PublishTime: input.PublishTime,
+	}
+	switch input.Format {
+	case "", "text":
+		doc.Content = strings.TrimSpace(input.Content)
+	case "html":
+		parsed := tool.ParseHTMLDocument(input.Content)
+		doc.Content = parsed.Content
+		if doc.Title == "" {
+			doc.Title = parsed.Title
+		}
+		if doc.PublishTime == "" {
+			doc.PublishTime = parsed.PublishTime
+		}
+		doc.SiteName = parsed.SiteName
+	default:
+		return nil, fmt.Errorf("不支持的文档格式: %s", input.Format)
+	}
+
+	if doc.Content == "" {
+		return nil, fmt.Errorf("第 %d 篇文档内容为空", index+1)
+	}
+	if doc.Title == "" {
+		doc.Title = fmt.Sprintf("文档 %d", index+1)
+	}
+	return doc, nil
+}
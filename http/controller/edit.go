@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"lineNews/agent"
+	"lineNews/agent/logutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventCreateRequest 人工添加事件请求
+type EventCreateRequest struct {
+	agent.Event
+	Author string `json:"author"`
+}
+
+// EventUpdateRequest 人工修改事件请求，只修改提供的字段
+type EventUpdateRequest struct {
+	agent.EventPatch
+	Author string `json:"author"`
+}
+
+// NodeCreateRequest 人工添加图谱节点请求
+type NodeCreateRequest struct {
+	agent.GraphNode
+	Author string `json:"author"`
+}
+
+// NodeUpdateRequest 人工修改图谱节点请求，只修改提供的字段
+type NodeUpdateRequest struct {
+	agent.NodePatch
+	Author string `json:"author"`
+}
+
+// LinkCreateRequest 人工添加图谱关系请求
+type LinkCreateRequest struct {
+	agent.GraphLink
+	Author string `json:"author"`
+}
+
+// LinkUpdateRequest 人工修改图谱关系请求，只修改提供的字段
+type LinkUpdateRequest struct {
+	agent.LinkPatch
+	Author string `json:"author"`
+}
+
+// HandleCreateEvent 在已保存的时间链中添加事件
+func HandleCreateEvent(c *gin.Context) {
+	var req EventCreateRequest
+	if !bindEditRequest(c, &req, &req.Author) {
+		return
+	}
+	editTimelineRecord(c, req.Author, func(r *agent.TimelineRecord) (any, error) {
+		return r.AddEvent(req.Event, req.Author)
+	})
+}
+
+// HandleUpdateEvent 修改已保存时间链中的事件
+func HandleUpdateEvent(c *gin.Context) {
+	var req EventUpdateRequest
+	if !bindEditRequest(c, &req, &req.Author) {
+		return
+	}
+	editTimelineRecord(c, req.Author, func(r *agent.TimelineRecord) (any, error) {
+		return r.UpdateEvent(c.Param("event"), req.EventPatch, req.Author)
+	})
+}
+
+// HandleDeleteEvent 删除已保存时间链中的事件
+func HandleDeleteEvent(c *gin.Context) {
+	author, ok := editAuthor(c, "")
+	if !ok {
+		return
+	}
+	editTimelineRecord(c, author, func(r *agent.TimelineRecord) (any, error) {
+		return nil, r.DeleteEvent(c.Param("event"), author)
+	})
+}
+
+// HandleCreateNode 在已保存的图谱中添加节点
+func HandleCreateNode(c *gin.Context) {
+	var req NodeCreateRequest
+	if !bindEditRequest(c, &req, &req.Author) {
+		return
+	}
+	editTimelineRecord(c, req.Author, func(r *agent.TimelineRecord) (any, error) {
+		return r.AddNode(req.GraphNode, req.Author)
+	})
+}
+
+// HandleUpdateNode 修改已保存图谱中的节点
+func HandleUpdateNode(c *gin.Context) {
+	var req NodeUpdateRequest
+	if !bindEditRequest(c, &req, &req.Author) {
+		return
+	}
+	editTimelineRecord(c, req.Author, func(r *agent.TimelineRecord) (any, error) {
+		return r.UpdateNode(c.Param("node"), req.NodePatch, req.Author)
+	})
+}
+
+// HandleDeleteNode 删除已保存图谱中的节点及其关系
+func HandleDeleteNode(c *gin.Context) {
+	author, ok := editAuthor(c, "")
+	if !ok {
+		return
+	}
+	editTimelineRecord(c, author, func(r *agent.TimelineRecord) (any, error) {
+		return nil, r.DeleteNode(c.Param("node"), author)
+	})
+}
+
+// HandleCreateLink 在已保存的图谱中添加关系
+func HandleCreateLink(c *gin.Context) {
+	var req LinkCreateRequest
+	if !bindEditRequest(c, &req, &req.Author) {
+		return
+	}
+	editTimelineRecord(c, req.Author, func(r *agent.TimelineRecord) (any, error) {
+		return r.AddLink(req.GraphLink, req.Author)
+	})
+}
+
+// HandleUpdateLink 修改已保存图谱中的关系
+func HandleUpdateLink(c *gin.Context) {
+	var req LinkUpdateRequest
+	if !bindEditRequest(c, &req, &req.Author) {
+		return
+	}
+	editTimelineRecord(c, req.Author, func(r *agent.TimelineRecord) (any, error) {
+		return r.UpdateLink(c.Param("link"), req.LinkPatch, req.Author)
+	})
+}
+
+// HandleDeleteLink 删除已保存图谱中的关系
+func HandleDeleteLink(c *gin.Context) {
+	author, ok := editAuthor(c, "")
+	if !ok {
+		return
+	}
+	editTimelineRecord(c, author, func(r *agent.TimelineRecord) (any, error) {
+		return nil, r.DeleteLink(c.Param("link"), author)
+	})
+}
+
+// HandleListEdits 获取专题记录的人工编辑记录和已删除条目，支持 kind、author 过滤
+func HandleListEdits(c *gin.Context) {
+	record, err := timelineRecords.Get(c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	kind, author := c.Query("kind"), c.Query("author")
+	edits := make([]agent.Edit, 0, len(record.Edits))
+	for _, edit := range record.Edits {
+		if (kind == "" || edit.Kind == kind) && (author == "" || edit.Author == author) {
+			edits = append(edits, edit)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"record_id":  record.ID,
+			"edits":      edits,
+			"tombstones": record.Tombstones,
+		},
+	})
+}
+
+// bindEditRequest 解析编辑请求并确定编辑者，失败时写入 400 响应
+func bindEditRequest(c *gin.Context, req any, author *string) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("请求参数错误: %v", err),
+		})
+		return false
+	}
+	var ok bool
+	*author, ok = editAuthor(c, *author)
+	return ok
+}
+
+// editAuthor 确定编辑者：请求体 author、请求头 X-Author、查询参数 author 依次取第一个非空值，均为空时写入 400 响应
+func editAuthor(c *gin.Context, author string) (string, bool) {
+	for _, value := range []string{author, c.GetHeader("X-Author"), c.Query("author")} {
+		if value = strings.TrimSpace(value); value != "" {
+			return value, true
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": "author 不能为空",
+	})
+	return "", false
+}
+
+// editTimelineRecord 在锁内执行人工编辑并保存为新版本，编辑对象不存在时返回 404，校验失败时返回 400
+func editTimelineRecord(c *gin.Context, author string, edit func(r *agent.TimelineRecord) (any, error)) {
+	id := c.Param("id")
+	var result any
+	var editErr error
+	record, err := updateTimelineRecord(id, "edit", func(r *agent.TimelineRecord) error {
+		result, editErr = edit(r)
+		return editErr
+	})
+	switch {
+	case errors.Is(editErr, agent.ErrItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": editErr.Error(),
+		})
+		return
+	case editErr != nil:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": editErr.Error(),
+		})
+		return
+	case err != nil:
+		respondStoreError(c, err)
+		return
+	}
+
+	logutil.LogInfo("人工编辑专题记录: %s (%s %s, 编辑者: %s, 版本: %d)", id, c.Request.Method, c.FullPath(), author, record.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+		"version": record.Version,
+	})
+}
@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"lineNews/agent/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// entityRegistry 所有已保存专题记录中的跨专题实体
+var entityRegistry = entity.NewRegistry()
+
+// HandleListEntities 列出跨专题实体，q 按名称或别名过滤，category 按类别过滤，limit 限制数量（默认 50）
+func HandleListEntities(c *gin.Context) {
+	limit := 50
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("无效的 limit: %s", value),
+			})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    entityRegistry.List(c.Query("q"), c.Query("category"), limit),
+	})
+}
+
+// HandleGetEntity 获取实体及其出现的所有专题、事件和关系，:id 也可以是唯一对应一个实体的名称或别名
+func HandleGetEntity(c *gin.Context) {
+	e, ok := entityRegistry.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("实体不存在: %s", c.Param("id")),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    e,
+	})
+}
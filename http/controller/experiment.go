@@ -0,0 +1,437 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"lineNews/agent"
+	"lineNews/agent/experiment"
+	"lineNews/agent/logutil"
+	"lineNews/agent/prompt"
+	"lineNews/agent/tool"
+	"lineNews/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// experiments 提示词和模型对比实验
+var experiments = store.NewCollection[experiment.Experiment]("experiments")
+
+// errExperimentConflict 同一对象已有其他运行中的实验
+var errExperimentConflict = errors.New("实验对象已有运行中的实验")
+
+// runningByTarget 各对象正在运行的实验，首次使用时从存储加载，创建、修改或删除实验后清除
+// experimentsMu 保护 runningByTarget，并串行化实验的创建、修改和删除，保证同一对象只有一个运行中的实验
+var (
+	experimentsMu   sync.Mutex
+	runningByTarget map[string]*experiment.Experiment
+)
+
+// variantPrompts 已加载的变体提示词模板，键为 实验ID/变体名称；实验修改、删除或重新加载提示词时清除
+var (
+	variantPromptsMu sync.Mutex
+	variantPrompts   = make(map[string]variantPromptSet)
+)
+
+// variantPromptSet 变体提示词目录及其模板集合
+type variantPromptSet struct {
+	dir string
+	set *prompt.Set
+}
+
+// ExperimentRequest 创建或修改实验请求，修改时只更新提供的字段
+type ExperimentRequest struct {
+	Name        *string              `json:"name"`
+	Description *string              `json:"description"`
+	Target      *string              `json:"target"` // timeline 或 graph，创建后不能修改
+	Status      *string              `json:"status"` // running 或 stopped，创建时默认 running
+	Variants    []experiment.Variant `json:"variants"`
+	Weights     map[string]int       `json:"weights"` // 按变体名称调整分流权重
+}
+
+// apply 将请求中的字段写入实验并校验，已有运行记录的实验只能调整权重，不能修改变体
+func (r *ExperimentRequest) apply(e *experiment.Experiment) error {
+	if r.Name != nil {
+		e.Name = *r.Name
+	}
+	if r.Description != nil {
+		e.Description = *r.Description
+	}
+	if r.Target != nil {
+		if e.Target != "" && *r.Target != e.Target {
+			return fmt.Errorf("实验对象创建后不能修改")
+		}
+		e.Target = *r.Target
+	}
+	if r.Status != nil {
+		e.Status = *r.Status
+	}
+	if r.Variants != nil {
+		if len(e.Metrics) > 0 {
+			return fmt.Errorf("已有运行记录的实验不能修改变体，只能调整权重")
+		}
+		e.Variants = r.Variants
+	}
+	for name, weight := range r.Weights {
+		found := false
+		for i := range e.Variants {
+			if e.Variants[i].Name == name {
+				e.Variants[i].Weight = weight
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("变体不存在: %s", name)
+		}
+	}
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	for _, v := range e.Variants {
+		if v.PromptDir == "" {
+			continue
+		}
+		dir, err := variantPromptDir(v.PromptDir)
+		if err != nil {
+			return fmt.Errorf("变体 %s 的提示词目录无效: %w", v.Name, err)
+		}
+		if _, err := prompt.Load(dir); err != nil {
+			return fmt.Errorf("变体 %s 的提示词模板无效: %w", v.Name, err)
+		}
+	}
+	e.UpdatedAt = time.Now()
+	return nil
+}
+
+// variantPromptDir 返回变体提示词目录的路径，prompt_dir 必须是 PROMPT_TEMPLATE_DIR 下的相对路径，解析符号链接后也不能指向该目录之外
+func variantPromptDir(dir string) (string, error) {
+	if promptTemplateDir == "" {
+		return "", fmt.Errorf("未配置 PROMPT_TEMPLATE_DIR")
+	}
+	if !filepath.IsLocal(dir) {
+		return "", fmt.Errorf("必须是 PROMPT_TEMPLATE_DIR 下的相对路径: %s", dir)
+	}
+	root, err := filepath.EvalSymlinks(promptTemplateDir)
+	if err != nil {
+		return "", fmt.Errorf("读取提示词模板目录失败: %w", err)
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(root, dir))
+	if err != nil {
+		return "", fmt.Errorf("读取提示词目录失败: %w", err)
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("不能指向 PROMPT_TEMPLATE_DIR 之外: %s", dir)
+	}
+	return path, nil
+}
+
+// loadVariantPrompts 返回变体的提示词模板，同一变体只在首次使用或目录变化时加载
+func loadVariantPrompts(experimentID string, variant *experiment.Variant) (*prompt.Set, error) {
+	key := experimentID + "/" + variant.Name
+	variantPromptsMu.Lock()
+	defer variantPromptsMu.Unlock()
+	if cached, ok := variantPrompts[key]; ok && cached.dir == variant.PromptDir {
+		return cached.set, nil
+	}
+	dir, err := variantPromptDir(variant.PromptDir)
+	if err != nil {
+		return nil, err
+	}
+	set, err := prompt.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	variantPrompts[key] = variantPromptSet{dir: variant.PromptDir, set: set}
+	return set, nil
+}
+
+// forgetVariantPrompts 清除已加载的变体提示词，experimentID 为空时清除所有实验的
+func forgetVariantPrompts(experimentID string) {
+	variantPromptsMu.Lock()
+	defer variantPromptsMu.Unlock()
+	for key := range variantPrompts {
+		if experimentID == "" || strings.HasPrefix(key, experimentID+"/") {
+			delete(variantPrompts, key)
+		}
+	}
+}
+
+// experimentView 实验详情，累计指标以各变体的汇总值返回
+type experimentView struct {
+	*experiment.Experiment
+	Metrics    map[string]*experiment.Metrics `json:"metrics,omitempty"`
+	Aggregates []experiment.Aggregate         `json:"aggregates"`
+}
+
+// viewExperiment 生成实验详情
+func viewExperiment(e *experiment.Experiment) experimentView {
+	return experimentView{Experiment: e, Aggregates: e.Aggregates()}
+}
+
+// runningExperimentsLocked 返回各对象正在运行的实验，调用方需持有 experimentsMu
+func runningExperimentsLocked() (map[string]*experiment.Experiment, error) {
+	if runningByTarget != nil {
+		return runningByTarget, nil
+	}
+	list, err := experiments.List()
+	if err != nil {
+		return nil, err
+	}
+	running := make(map[string]*experiment.Experiment)
+	for _, e := range list {
+		if e.Status == experiment.StatusRunning {
+			running[e.Target] = e
+		}
+	}
+	runningByTarget = running
+	return running, nil
+}
+
+// runningExperiment 返回指定对象正在运行的实验，没有时返回 nil
+func runningExperiment(target string) (*experiment.Experiment, error) {
+	experimentsMu.Lock()
+	defer experimentsMu.Unlock()
+	running, err := runningExperimentsLocked()
+	if err != nil {
+		return nil, err
+	}
+	return running[target], nil
+}
+
+// experimentConflict 检查运行中的实验 e 的对象是否已有其他运行中的实验
+func experimentConflict(running map[string]*experiment.Experiment, e *experiment.Experiment) error {
+	if e.Status != experiment.StatusRunning {
+		return nil
+	}
+	if other := running[e.Target]; other != nil && other.ID != e.ID {
+		return fmt.Errorf("%w: 对象 %s, 实验 %s", errExperimentConflict, e.Target, other.ID)
+	}
+	return nil
+}
+
+// respondExperimentError 写入实验校验失败的响应，与运行中的实验冲突时返回 409，其余返回 400
+func respondExperimentError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, errExperimentConflict) {
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{
+		"error": err.Error(),
+	})
+}
+
+// experimentRun 一次参与实验的生成，方法在 nil 上调用时不做任何事
+type experimentRun struct {
+	experimentID string
+	variant      experiment.Variant
+	stats        *tool.CallStats
+	start        time.Time
+}
+
+// startExperimentRun 为生成请求分配运行中实验的变体，返回携带变体提示词和调用统计的 context
+// 没有运行中的实验或变体提示词加载失败时返回原 context 和 nil，按默认配置生成
+func startExperimentRun(ctx context.Context, target string) (context.Context, *experimentRun) {
+	e, err := runningExperiment(target)
+	if err != nil {
+		logutil.LogError("读取实验失败: %v", err)
+		return ctx, nil
+	}
+	if e == nil {
+		return ctx, nil
+	}
+	variant := e.Assign(rand.Float64())
+	if variant == nil {
+		return ctx, nil
+	}
+	if variant.PromptDir != "" {
+		set, err := loadVariantPrompts(e.ID, variant)
+		if err != nil {
+			logutil.LogError("加载实验 %s 变体 %s 的提示词失败，按默认配置生成: %v", e.ID, variant.Name, err)
+			return ctx, nil
+		}
+		ctx = prompt.WithSet(ctx, set)
+	}
+	run := &experimentRun{experimentID: e.ID, variant: *variant, stats: &tool.CallStats{}, start: time.Now()}
+	logutil.LogInfo("请求分配到实验 %s 的变体 %s", e.ID, variant.Name)
+	return tool.WithCallStats(ctx, run.stats), run
+}
+
+// model 返回变体指定的模型，为空时使用默认模型
+func (r *experimentRun) model() string {
+	if r == nil {
+		return ""
+	}
+	return r.variant.Model
+}
+
+// tag 返回写入生成结果的实验标记
+func (r *experimentRun) tag() *agent.ExperimentTag {
+	if r == nil {
+		return nil
+	}
+	return &agent.ExperimentTag{ID: r.experimentID, Variant: r.variant.Name}
+}
+
+// finish 将本次生成的产出数、调用统计和耗时计入实验指标
+func (r *experimentRun) finish(outputs int, failed bool) {
+	if r == nil {
+		return
+	}
+	run := experiment.Run{
+		Variant:          r.variant.Name,
+		Failed:           failed,
+		Outputs:          outputs,
+		Calls:            r.stats.Calls,
+		ParseFailures:    r.stats.ParseFailures,
+		RefineRounds:     r.stats.RefineRounds,
+		PromptTokens:     r.stats.PromptTokens,
+		CompletionTokens: r.stats.CompletionTokens,
+		Latency:          time.Since(r.start),
+	}
+	if _, err := experiments.Update(r.experimentID, func(e *experiment.Experiment) error {
+		e.Record(run)
+		return nil
+	}); err != nil {
+		logutil.LogError("记录实验 %s 指标失败: %v", r.experimentID, err)
+	}
+}
+
+// HandleListExperiments 列出所有实验及各变体的汇总指标
+func HandleListExperiments(c *gin.Context) {
+	list, err := experiments.List()
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	views := make([]experimentView, 0, len(list))
+	for _, e := range list {
+		views = append(views, viewExperiment(e))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    views,
+	})
+}
+
+// HandleCreateExperiment 创建实验，同一对象同时只能有一个运行中的实验
+func HandleCreateExperiment(c *gin.Context) {
+	var req ExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("请求参数错误: %v", err),
+		})
+		return
+	}
+
+	now := time.Now()
+	e := &experiment.Experiment{
+		ID:        store.NewID(),
+		Status:    experiment.StatusRunning,
+		Metrics:   map[string]*experiment.Metrics{},
+		CreatedAt: now,
+	}
+	if err := req.apply(e); err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+
+	experimentsMu.Lock()
+	defer experimentsMu.Unlock()
+	running, err := runningExperimentsLocked()
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	if err := experimentConflict(running, e); err != nil {
+		respondExperimentError(c, err)
+		return
+	}
+	if err := experiments.Save(e.ID, e); err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	runningByTarget = nil
+	logutil.LogInfo("创建实验: %s (%s, 对象: %s, 变体数: %d)", e.ID, e.Name, e.Target, len(e.Variants))
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    viewExperiment(e),
+	})
+}
+
+// HandleGetExperiment 获取实验配置和各变体的汇总指标：运行次数、失败率、解析失败率、平均事件数、平均反思轮数、平均 Token 用量和耗时分位数
+func HandleGetExperiment(c *gin.Context) {
+	e, err := experiments.Get(c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    viewExperiment(e),
+	})
+}
+
+// HandleUpdateExperiment 修改实验名称、状态、变体或分流权重
+func HandleUpdateExperiment(c *gin.Context) {
+	var req ExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("请求参数错误: %v", err),
+		})
+		return
+	}
+
+	// 在 Update 中对修改后的实验检查冲突，experimentsMu 保证检查期间没有其他实验被修改
+	experimentsMu.Lock()
+	defer experimentsMu.Unlock()
+	running, err := runningExperimentsLocked()
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	var invalid error
+	e, err := experiments.Update(c.Param("id"), func(e *experiment.Experiment) error {
+		if invalid = req.apply(e); invalid == nil {
+			invalid = experimentConflict(running, e)
+		}
+		return invalid
+	})
+	if invalid != nil {
+		respondExperimentError(c, invalid)
+		return
+	}
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	runningByTarget = nil
+	forgetVariantPrompts(e.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    viewExperiment(e),
+	})
+}
+
+// HandleDeleteExperiment 删除实验及其指标
+func HandleDeleteExperiment(c *gin.Context) {
+	experimentsMu.Lock()
+	defer experimentsMu.Unlock()
+	if err := experiments.Delete(c.Param("id")); err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	runningByTarget = nil
+	forgetVariantPrompts(c.Param("id"))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"lineNews/agent"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseEventFilter 解析 from、to、location、person、limit 过滤参数，无效时写入 400 响应
+func parseEventFilter(c *gin.Context) (*agent.EventFilter, bool) {
+	filter := &agent.EventFilter{
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		Location: c.Query("location"),
+		Person:   c.Query("person"),
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("无效的 limit: %s", value),
+			})
+			return nil, false
+		}
+		filter.Limit = limit
+	}
+	if err := filter.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+	return filter, true
+}
@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"lineNews/agent"
+	"lineNews/agent/analysis"
+	"lineNews/agent/export"
+	"lineNews/agent/logutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleGraphAnalyze 处理知识图谱分析请求
+// 计算度中心性、介数中心性和社区划分；传入 node 时返回 k 跳邻域，传入 source 和 target 时返回最短路径
+// 传入 id 时分析已保存记录的图谱，节点 ID 与 /api/graph 返回的一致；否则按 keyword 重新生成图谱后分析
+func HandleGraphAnalyze(c *gin.Context) {
+	id := c.Query("id")
+	keyword := c.Query("keyword")
+	if id == "" && keyword == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "id 和 keyword 参数不能同时为空",
+		})
+		return
+	}
+	mode := c.Query("mode")
+	if mode == "" {
+		mode = "fast" // 默认模式
+	}
+	top, _ := strconv.Atoi(c.DefaultQuery("top", "10"))
+	hops, _ := strconv.Atoi(c.DefaultQuery("hops", "1"))
+	if hops < 1 {
+		hops = 1
+	}
+	nodeID := c.Query("node")
+	source := c.Query("source")
+	target := c.Query("target")
+
+	logutil.LogInfo("知识图谱分析请求: %s (记录: %s, 模式: %s)", keyword, id, mode)
+
+	var graph *agent.GraphResponse
+	if id != "" {
+		var ok bool
+		if graph, ok = loadRecordGraph(c, id); !ok {
+			return
+		}
+	} else {
+		// 生成失败时不对 mock 数据做分析
+		var ok bool
+		if graph, ok = loadGraph(c, keyword, mode); !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "生成知识图谱失败",
+			})
+			return
+		}
+	}
+	g := analysis.NewGraph(graph)
+
+	data := gin.H{
+		"node_count":  g.NodeCount(),
+		"link_count":  g.LinkCount(),
+		"degree":      analysis.TopScores(g.DegreeCentrality(), top),
+		"betweenness": analysis.TopScores(g.BetweennessCentrality(), top),
+		"communities": g.Communities(),
+	}
+
+	if nodeID != "" {
+		neighborhood, err := g.Neighborhood(nodeID, hops)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		neighborhood.Keyword = graph.Keyword
+		data["neighborhood"] = gin.H{
+			"center": nodeID,
+			"hops":   hops,
+			"graph":  neighborhood,
+		}
+	}
+
+	if source != "" || target != "" {
+		if source == "" || target == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "source 和 target 参数需要同时提供",
+			})
+			return
+		}
+		path, err := g.ShortestPath(source, target)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		data["path"] = path
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"keyword": graph.Keyword,
+		"data":    data,
+	})
+}
+
+// HandleGraphExport 处理知识图谱导出请求，format 支持 graphml、gexf、cypher、turtle
+// 传入 id 时导出已保存记录的图谱（包括编辑后的图谱），否则按 keyword 重新生成图谱后导出
+func HandleGraphExport(c *gin.Context) {
+	id := c.Query("id")
+	keyword := c.Query("keyword")
+	if id == "" && keyword == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "id 和 keyword 参数不能同时为空",
+		})
+		return
+	}
+	mode := c.Query("mode")
+	if mode == "" {
+		mode = "fast" // 默认模式
+	}
+	// 先校验格式，避免生成完成后才发现格式不支持
+	format := c.DefaultQuery("format", "graphml")
+	if _, err := export.GraphFormat(format); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	logutil.LogInfo("知识图谱导出请求: %s (记录: %s, 格式: %s)", keyword, id, format)
+
+	var graph *agent.GraphResponse
+	if id != "" {
+		var ok bool
+		if graph, ok = loadRecordGraph(c, id); !ok {
+			return
+		}
+		keyword = graph.Keyword
+	} else {
+		var ok bool
+		if graph, ok = loadGraph(c, keyword, mode); !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "生成知识图谱失败",
+			})
+			return
+		}
+	}
+	data, f, err := export.ExportGraph(graph, format)
+	if err != nil {
+		logutil.LogError("导出知识图谱失败: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	writeExport(c, keyword, data, f)
+}
+
+// loadRecordGraph 读取已保存记录的图谱，记录不存在或没有图谱时写入错误响应并返回 false
+func loadRecordGraph(c *gin.Context, id string) (*agent.GraphResponse, bool) {
+	record, err := timelineRecords.Get(id)
+	if err != nil {
+		respondStoreError(c, err)
+		return nil, false
+	}
+	if record.Graph == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "该记录没有图谱"})
+		return nil, false
+	}
+	if record.Graph.Keyword == "" {
+		record.Graph.Keyword = record.Keyword
+	}
+	return record.Graph, true
+}
+
+// writeExport 以附件形式返回导出内容
+func writeExport(c *gin.Context, name string, data []byte, f export.Format) {
+	filename := fmt.Sprintf("%s.%s", name, f.Extension)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", url.PathEscape(filename)))
+	c.Data(http.StatusOK, f.ContentType, data)
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"lineNews/agent"
+	"lineNews/agent/entity"
+	"lineNews/agent/logutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxMergeSources 单次合并的最大图谱数
+const MaxMergeSources = 10
+
+// GraphMergeRequest 合并多个专题图谱的请求
+type GraphMergeRequest struct {
+	IDs      []string `json:"ids"`      // 已保存的专题记录 ID
+	Keywords []string `json:"keywords"` // 关键词，使用该关键词最近保存的带图谱记录，没有时生成并保存
+	Mode     string   `json:"mode"`     // 生成新图谱时使用的模式，默认 fast
+}
+
+// MergedSource 合并结果中的来源说明
+type MergedSource struct {
+	RecordID  string `json:"record_id,omitempty"`
+	Keyword   string `json:"keyword"`
+	Generated bool   `json:"generated,omitempty"` // 是否为本次请求新生成的图谱
+	NodeCount int    `json:"node_count"`
+	LinkCount int    `json:"link_count"`
+}
+
+// HandleGraphMerge 将多个已保存的专题图谱或关键词对应的图谱合并为一个图谱
+// 节点通过百科词条和名称归并为跨专题实体，每个节点和关系的 topics 为其出现的专题，provenance 为其在各来源中的原始 ID
+func HandleGraphMerge(c *gin.Context) {
+	var req GraphMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("请求参数错误: %v", err),
+		})
+		return
+	}
+	if total := len(req.IDs) + len(req.Keywords); total < 2 || total > MaxMergeSources {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("ids 和 keywords 合计需要 2-%d 个", MaxMergeSources),
+		})
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = "fast"
+	}
+
+	var sources []entity.MergeSource
+	var info []MergedSource
+	seen := make(map[string]bool)
+	add := func(recordID, keyword string, graph *agent.GraphResponse, generated bool) {
+		if recordID != "" {
+			if seen[recordID] {
+				return
+			}
+			seen[recordID] = true
+		}
+		sources = append(sources, entity.MergeSource{RecordID: recordID, Keyword: keyword, Graph: graph})
+		info = append(info, MergedSource{RecordID: recordID, Keyword: keyword, Generated: generated, NodeCount: len(graph.Nodes), LinkCount: len(graph.Links)})
+	}
+
+	for _, id := range req.IDs {
+		record, err := timelineRecords.Get(id)
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		if record.Graph == nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("记录 %s 没有图谱", id),
+			})
+			return
+		}
+		add(record.ID, record.Keyword, record.Graph, false)
+	}
+	for _, keyword := range req.Keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "keywords 中不能有空关键词",
+			})
+			return
+		}
+		record, err := latestRecord(keyword, func(r *agent.TimelineRecord) bool { return r.Graph != nil })
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		if record != nil {
+			add(record.ID, record.Keyword, record.Graph, false)
+			continue
+		}
+		// 生成失败时整个合并失败，不把 mock 图谱当作真实来源
+		timeline, graph, ok := loadTopic(c, keyword, req.Mode)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("生成关键词 %s 的图谱失败", keyword),
+			})
+			return
+		}
+		recordID := ""
+		if saved := saveTimelineRecord(keyword, req.Mode, timeline, graph); saved != nil {
+			recordID = saved.ID
+		}
+		add(recordID, keyword, graph, true)
+	}
+
+	merged := entity.MergeGraphs(sources)
+	logutil.LogInfo("合并 %d 个专题图谱: %s (节点: %d, 关系: %d)", len(sources), merged.Keyword, len(merged.Nodes), len(merged.Links))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    merged,
+		"sources": info,
+	})
+}
+
+// latestRecord 返回关键词最近更新且满足条件的记录，没有时返回 nil
+func latestRecord(keyword string, match func(r *agent.TimelineRecord) bool) (*agent.TimelineRecord, error) {
+	records, err := timelineRecords.List()
+	if err != nil {
+		return nil, err
+	}
+	var latest *agent.TimelineRecord
+	for _, record := range records {
+		if record.Keyword == keyword && match(record) && (latest == nil || record.UpdatedAt.After(latest.UpdatedAt)) {
+			latest = record
+		}
+	}
+	return latest, nil
+}
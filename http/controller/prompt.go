@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"lineNews/agent/logutil"
+	"lineNews/agent/prompt"
+	"lineNews/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// promptTemplateDir 提示词模板目录，为空时只使用内置提示词
+var promptTemplateDir string
+
+// InitPrompts 加载 PROMPT_TEMPLATE_DIR（默认 prompts）下的提示词模板，并每隔 PROMPT_RELOAD_INTERVAL 秒检查一次目录变化
+// 目录不存在或加载失败时使用内置提示词，之后创建目录也会被热加载；热加载失败时继续使用之前的模板
+func InitPrompts(ctx context.Context, cfg *config.Config) {
+	promptTemplateDir = cfg.PromptTemplateDir
+	if promptTemplateDir == "" {
+		return
+	}
+	if _, err := os.Stat(promptTemplateDir); errors.Is(err, os.ErrNotExist) {
+		logutil.LogInfo("提示词模板目录 %s 不存在，使用内置提示词", promptTemplateDir)
+	} else if _, err := prompt.Reload(promptTemplateDir); err != nil {
+		logutil.LogError("加载提示词模板失败，使用内置提示词: %v", err)
+	}
+	if cfg.PromptReloadInterval > 0 {
+		go prompt.Watch(ctx, promptTemplateDir, time.Duration(cfg.PromptReloadInterval)*time.Second)
+	}
+}
+
+// HandleListPrompts 获取当前提示词模板的版本和每个模板的来源
+func HandleListPrompts(c *gin.Context) {
+	set := prompt.Current()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"version":   set.Version,
+			"dir":       set.Dir,
+			"loaded_at": set.LoadedAt,
+			"templates": set.Templates(),
+		},
+	})
+}
+
+// HandleReloadPrompts 立即重新加载提示词模板目录，模板有错误时返回 400 并继续使用之前的模板
+// 实验变体的提示词在下次使用时重新加载
+func HandleReloadPrompts(c *gin.Context) {
+	if promptTemplateDir == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "未配置 PROMPT_TEMPLATE_DIR",
+		})
+		return
+	}
+	forgetVariantPrompts("")
+	set, err := prompt.Reload(promptTemplateDir)
+	if err != nil {
+		logutil.LogError("重新加载提示词模板失败: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("重新加载失败，继续使用版本 %s: %v", prompt.Current().Version, err),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"version":   set.Version,
+			"loaded_at": set.LoadedAt,
+		},
+	})
+}
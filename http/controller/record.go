@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"lineNews/agent"
+	"lineNews/agent/logutil"
+	"lineNews/agent/update"
+	"lineNews/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timelineRecords 已生成的时间链与知识图谱记录
+var timelineRecords = store.NewCollection[agent.TimelineRecord]("timelines")
+
+// saveTimelineRecord 保存新生成的时间链与图谱，并将记录 ID 写入二者；保存失败只记录日志
+func saveTimelineRecord(keyword, mode string, timeline *agent.TimelineResponse, graph *agent.GraphResponse) *agent.TimelineRecord {
+	record := agent.NewTimelineRecord(store.NewID(), keyword, mode, timeline, graph)
+	err := saveTimelineVersion(record.Snapshot("create"))
+	if err == nil {
+		err = timelineRecords.Save(record.ID, record)
+	}
+	if err != nil {
+		logutil.LogError("保存时间链记录失败: %v", err)
+		if timeline != nil {
+			timeline.ID = ""
+		}
+		if graph != nil {
+			graph.ID = ""
+		}
+		return nil
+	}
+	indexTimelineRecord(record)
+	return record
+}
+
+// HandleGetTimelineRecord 获取已保存的时间链与知识图谱
+// 支持 from、to、location、person、limit 过滤参数，图谱只保留满足条件的事件构成的子图；
+// lang 与原文语言不同时返回该语言的译文，并说明译文是否落后于记录的当前版本
+func HandleGetTimelineRecord(c *gin.Context) {
+	filter, ok := parseEventFilter(c)
+	if !ok {
+		return
+	}
+	lang, ok := parseLang(c)
+	if !ok {
+		return
+	}
+	record, err := timelineRecords.Get(c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+
+	response := gin.H{"success": true}
+	if c.Query("lang") != "" && lang != record.Lang() {
+		translation, ok := record.Translations[lang]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": fmt.Sprintf("该记录没有 %s 译文，请先调用翻译接口", lang),
+			})
+			return
+		}
+		record.Timeline, record.Graph = translation.Timeline, translation.Graph
+		response["translation"] = gin.H{
+			"lang":       translation.Lang,
+			"version":    translation.Version,
+			"stale":      translation.Stale(record),
+			"created_at": translation.CreatedAt,
+		}
+	}
+	record.Translations = nil
+	record.Graph = filter.FilterGraph(record.Graph, record.Timeline)
+	record.Timeline = filter.FilterTimeline(record.Timeline)
+	response["data"] = record
+	c.JSON(http.StatusOK, response)
+}
+
+// HandleUpdateTimelineRecord 增量更新已保存的时间链：只搜索最新事件之后的新闻并合并，返回新增事件的差异
+// 有新增事件且记录中已有图谱时同时重新生成图谱，并向 Webhook 订阅发送 timeline.updated 事件
+// source 为 deepsearch（默认）或 ark，recency 为深度搜索的时间范围 week、month、semiyear、year
+func HandleUpdateTimelineRecord(c *gin.Context) {
+	id := c.Param("id")
+	record, err := timelineRecords.Get(id)
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	if record.Timeline == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "该记录没有时间链",
+		})
+		return
+	}
+
+	opts := update.Options{
+		Source:  c.Query("source"),
+		Recency: c.Query("recency"),
+	}
+	logutil.LogInfo("时间链增量更新请求: %s (来源: %s)", id, opts.Source)
+
+	diff, err := update.Search(c.Request.Context(), record.Timeline, opts)
+	if err != nil {
+		logutil.LogError("时间链增量更新失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("增量更新失败: %v", err),
+		})
+		return
+	}
+
+	record, err = mergeTimelineUpdate(id, diff)
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	var addedNodes []agent.GraphNode
+	if len(diff.Added) > 0 {
+		record, addedNodes = refreshRecordGraph(c.Request.Context(), record)
+		publishTimelineUpdated(record, diff, addedNodes, "manual", "")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"diff":        diff,
+			"added_nodes": addedNodes,
+			"timeline":    record.Timeline,
+		},
+	})
+}
+
+// mergeTimelineUpdate 在锁内将增量更新结果合并进已保存的时间链
+// 没有新增事件时不产生新版本
+func mergeTimelineUpdate(id string, diff *update.Diff) (*agent.TimelineRecord, error) {
+	if len(diff.Added) == 0 {
+		return timelineRecords.Get(id)
+	}
+	return updateTimelineRecord(id, "update", func(r *agent.TimelineRecord) error {
+		if r.Timeline == nil {
+			return fmt.Errorf("该记录没有时间链")
+		}
+		// 人工删除过的事件不再加回
+		var removed int
+		diff.Added, removed = r.FilterDeletedEvents(diff.Added)
+		diff.Skipped += removed
+		update.Apply(r.Timeline, diff)
+		r.SetTimeline(r.Timeline)
+		return nil
+	})
+}
+
+// refreshRecordGraph 记录中已有图谱时基于最新时间链重新生成并写回，返回新增的实体节点
+// 同名节点沿用原有的 ID 和百科信息；生成失败时保留原图谱，只记录日志
+func refreshRecordGraph(ctx context.Context, record *agent.TimelineRecord) (*agent.TimelineRecord, []agent.GraphNode) {
+	if record.Graph == nil || record.Timeline == nil {
+		return record, nil
+	}
+	graph, err := agentManager.generateGraph(ctx, record.Keyword, record.Timeline, record.Mode)
+	if err != nil {
+		logutil.LogError("重新生成图谱失败: %v", err)
+		return record, nil
+	}
+
+	var added []agent.GraphNode
+	updated, err := updateTimelineRecord(record.ID, "graph", func(r *agent.TimelineRecord) error {
+		added = replaceGraph(r, graph)
+		return nil
+	})
+	if err != nil {
+		logutil.LogError("保存图谱失败: %v", err)
+		return record, nil
+	}
+	return updated, added
+}
+
+// replaceGraph 用新图谱替换记录中的图谱：同名节点沿用旧 ID 和百科信息，保留人工编辑，返回新增的节点
+func replaceGraph(r *agent.TimelineRecord, graph *agent.GraphResponse) []agent.GraphNode {
+	var added []agent.GraphNode
+	agent.StabilizeNodeIDs(r.Graph, graph)
+	r.PreserveGraphEdits(graph)
+	if r.Graph != nil {
+		previous := make(map[string]agent.GraphNode, len(r.Graph.Nodes))
+		for _, node := range r.Graph.Nodes {
+			previous[node.ID] = node
+		}
+		for i, node := range graph.Nodes {
+			old, ok := previous[node.ID]
+			if !ok {
+				added = append(added, node)
+				continue
+			}
+			if graph.Nodes[i].Baike == nil {
+				graph.Nodes[i].Baike = old.Baike
+			}
+		}
+	}
+	r.SetGraph(graph)
+	return added
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"lineNews/agent"
+	"lineNews/agent/experiment"
+	"lineNews/agent/logutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegenerateRequest 按约束重新生成时间链的请求
+type RegenerateRequest struct {
+	agent.GenerateOptions
+	PinnedIDs []string `json:"pinned_ids"` // 需要置顶的已有事件 ID，人工编辑过的事件始终置顶
+}
+
+// regenerateTimeline 按约束重新生成时间链
+// 参与运行中的时间链实验时只使用变体的提示词，变体指定的 Ark 模型不用于 DeepSeek 工作流
+func (am *AgentManager) regenerateTimeline(ctx context.Context, keyword string, opts *agent.GenerateOptions) (*agent.TimelineResponse, error) {
+	logutil.LogInfo("开始按约束重新生成时间链: %s", keyword)
+	if am == nil || am.agent == nil {
+		return nil, fmt.Errorf("Agent 未初始化")
+	}
+	ctx, run := startExperimentRun(ctx, experiment.TargetTimeline)
+	timeline, err := am.agent.GenerateTimelineWithOptions(ctx, keyword, opts)
+	if err != nil {
+		run.finish(0, true)
+		return nil, err
+	}
+	timeline.Experiment = run.tag()
+	run.finish(len(timeline.Events), false)
+	return timeline, nil
+}
+
+// HandleRegenerateTimelineRecord 按约束重新生成已保存的时间链，并保存为新版本
+// 约束包括必须包含的事件、置顶事件、排除的子话题、时间窗口和输出语言；人工编辑过的事件始终保留，人工删除过的事件不再加回
+// 记录中已有图谱时同时基于新的时间链重新生成图谱
+func HandleRegenerateTimelineRecord(c *gin.Context) {
+	id := c.Param("id")
+	var req RegenerateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("请求参数错误: %v", err),
+			})
+			return
+		}
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	record, err := timelineRecords.Get(id)
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	opts := req.GenerateOptions
+	if record.Timeline != nil {
+		pinned := make(map[string]bool, len(req.PinnedIDs))
+		for _, eventID := range req.PinnedIDs {
+			if _, ok := record.Timeline.FindEvent(eventID); !ok {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fmt.Sprintf("置顶事件不存在: %s", eventID),
+				})
+				return
+			}
+			pinned[eventID] = true
+		}
+		for _, event := range record.Timeline.Events {
+			if event.Edited || pinned[event.ID] {
+				opts.Pinned = append(opts.Pinned, event)
+			}
+		}
+	} else if len(req.PinnedIDs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "该记录没有时间链",
+		})
+		return
+	}
+
+	timeline, err := agentManager.regenerateTimeline(c.Request.Context(), record.Keyword, &opts)
+	if err != nil {
+		logutil.LogError("按约束重新生成时间链失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("重新生成失败: %v", err),
+		})
+		return
+	}
+
+	var removed int
+	record, err = updateTimelineRecord(id, "regenerate", func(r *agent.TimelineRecord) error {
+		removed = r.ReplaceEvents(timeline.Events)
+		r.Timeline.Lang, r.Timeline.PromptVersion = timeline.Lang, timeline.PromptVersion
+		r.Timeline.Experiment = timeline.Experiment
+		return nil
+	})
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	record, addedNodes := refreshRecordGraph(c.Request.Context(), record)
+
+	logutil.LogInfo("时间链重新生成完成: %s (事件数: %d, 置顶: %d, 版本: %d)", id, len(record.Timeline.Events), len(opts.Pinned), record.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"timeline":    record.Timeline,
+			"pinned":      len(opts.Pinned),
+			"removed":     removed,
+			"added_nodes": addedNodes,
+			"version":     record.Version,
+		},
+	})
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"lineNews/agent"
+	"lineNews/agent/logutil"
+	"lineNews/agent/report"
+	"lineNews/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	reportRenderer = report.NewRenderer("")
+	reportBrand    = "LineNews"
+)
+
+// InitReport 初始化报告渲染器，REPORT_TEMPLATE_DIR 下的 report.html 会覆盖内置模板
+func InitReport(cfg *config.Config) {
+	reportRenderer = report.NewRenderer(cfg.ReportTemplateDir)
+	if cfg.ReportBrand != "" {
+		reportBrand = cfg.ReportBrand
+	}
+}
+
+// HandleReport 处理专题简报请求，format 支持 html（默认）和 pdf
+// 报告包含时间线、知识图谱 SVG、关键实体百科摘要和来源列表，baike=false 可关闭百科增强
+func HandleReport(c *gin.Context) {
+	keyword := c.Query("keyword")
+	if keyword == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "keyword 参数不能为空",
+		})
+		return
+	}
+	mode := c.Query("mode")
+	if mode == "" {
+		mode = "fast" // 默认模式
+	}
+	format := c.DefaultQuery("format", "html")
+	if format != "html" && format != "pdf" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("不支持的报告格式: %s", format),
+		})
+		return
+	}
+	maxEntities, _ := strconv.Atoi(c.DefaultQuery("entities", "8"))
+
+	logutil.LogInfo("专题简报请求: %s (格式: %s)", keyword, format)
+
+	// 图谱基于同一份时间链生成，保证报告内容一致
+	// 生成失败时返回错误，不把 mock 数据渲染成报告
+	timeline, ok := loadTimeline(c, keyword, mode)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "生成时间链失败",
+		})
+		return
+	}
+	graph, ok := graphFromTimeline(c, keyword, timeline, mode)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "生成知识图谱失败",
+		})
+		return
+	}
+
+	// 报告默认补充百科信息，baike=false 时关闭；enrich=true 时 graphFromTimeline 已经补充过，不再重复
+	needBaike := c.Query("baike") != "false" && !isTrue(c.Query("enrich"))
+	if needBaike {
+		if _, err := agent.EnrichGraphWithBaike(c.Request.Context(), graph, agent.NewBaikeEnrichOptions()); err != nil {
+			logutil.LogError("百科增强失败: %v", err)
+		}
+	}
+
+	data := report.NewData(reportBrand, timeline, graph, maxEntities)
+
+	var buf bytes.Buffer
+	if format == "pdf" {
+		if err := reportRenderer.RenderPDF(&buf, data); err != nil {
+			logutil.LogError("生成 PDF 报告失败: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		filename := fmt.Sprintf("%s.pdf", keyword)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", url.PathEscape(filename)))
+		c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+		return
+	}
+
+	if err := reportRenderer.RenderHTML(&buf, data); err != nil {
+		logutil.LogError("生成 HTML 报告失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"lineNews/agent"
+	"lineNews/agent/logutil"
+	"lineNews/agent/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventIndex 所有已保存专题记录中事件的全文索引
+var eventIndex = search.NewIndex()
+
+// InitIndexes 从已保存的专题记录构建事件全文索引和实体注册表
+func InitIndexes() {
+	records, err := timelineRecords.List()
+	if err != nil {
+		logutil.LogError("加载专题记录失败，事件检索和实体索引不可用: %v", err)
+		return
+	}
+	for _, record := range records {
+		indexTimelineRecord(record)
+	}
+	logutil.LogInfo("事件全文索引构建完成: %d 条记录，%d 个事件", len(records), eventIndex.Size())
+}
+
+// indexTimelineRecord 保存专题记录后更新全文索引和实体注册表
+func indexTimelineRecord(record *agent.TimelineRecord) {
+	if record != nil {
+		eventIndex.Put(record)
+		entityRegistry.Put(record)
+	}
+}
+
+// HandleSearchEvents 在所有已保存的时间链中全文检索事件
+// q 为检索词，多个词以空白分隔，要求全部命中；支持 from、to、location、person 过滤，record_id、topic 限定专题，limit、offset 分页
+func HandleSearchEvents(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "q 参数不能为空",
+		})
+		return
+	}
+	filter, ok := parseEventFilter(c)
+	if !ok {
+		return
+	}
+	offset := 0
+	if value := c.Query("offset"); value != "" {
+		var err error
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("无效的 offset: %s", value),
+			})
+			return
+		}
+	}
+
+	result := eventIndex.Search(search.Query{
+		Text:     q,
+		Filter:   *filter,
+		RecordID: c.Query("record_id"),
+		Topic:    c.Query("topic"),
+		Limit:    filter.Limit,
+		Offset:   offset,
+	})
+	logutil.LogInfo("事件检索: %s (命中: %d)", q, result.Total)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"fmt"
+
+	"lineNews/agent/logutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// chatStreamFunc 以流式方式调用模型，每段增量内容交给 onDelta，结束后返回完整回答和 token 统计
+type chatStreamFunc func(onDelta func(delta string) error) (content string, promptTokens, completionTokens, totalTokens int, err error)
+
+// streamChatResponse 以 SSE 返回模型的增量输出
+// 事件依次为 start、若干 delta、最后的 done（携带完整回答和 usage）；出错时发送 error
+func streamChatResponse(c *gin.Context, modelName string, message string, call chatStreamFunc) {
+	// 设置 SSE 响应头
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	ctx := c.Request.Context()
+
+	c.SSEvent("start", gin.H{"message": message, "model": modelName})
+	c.Writer.Flush()
+
+	content, promptTokens, completionTokens, totalTokens, err := call(func(delta string) error {
+		// 客户端断开后停止接收上游输出
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c.SSEvent("delta", gin.H{"content": delta})
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		logutil.LogError("流式对话失败: %v", err)
+		c.SSEvent("error", gin.H{"error": fmt.Sprintf("发送消息失败: %v", err)})
+		c.Writer.Flush()
+		return
+	}
+
+	c.SSEvent("done", gin.H{
+		"response": content,
+		"model":    modelName,
+		"usage": gin.H{
+			"prompt_tokens":     promptTokens,
+			"completion_tokens": completionTokens,
+			"total_tokens":      totalTokens,
+		},
+	})
+	c.Writer.Flush()
+}
@@ -9,7 +9,11 @@ import (
 	"strings"
 
 	"lineNews/agent"
+	"lineNews/agent/experiment"
+	"lineNews/agent/export"
 	"lineNews/agent/logutil"
+	"lineNews/agent/prompt"
+	"lineNews/agent/tool"
 	"lineNews/config"
 	"lineNews/model"
 
@@ -44,10 +48,10 @@ func InitAgent(ctx context.Context, cfg *config.Config) error {
 	return nil
 }
 
-// generateTimeline 生成时间链
-func (am *AgentManager) generateTimeline(ctx context.Context, keyword string, mode string) (*agent.TimelineResponse, error) {
+// generateTimeline 生成时间链，lang 为输出语言，为空时使用中文
+func (am *AgentManager) generateTimeline(ctx context.Context, keyword string, mode string, lang string) (*agent.TimelineResponse, error) {
 	// 直接调用Ark模型生成时间链
-	logutil.LogInfo("开始从 Ark 模型生成时间链: %s (模式: %s)", keyword, mode)
+	logutil.LogInfo("开始从 Ark 模型生成时间链: %s (模式: %s, 语言: %s)", keyword, mode, lang)
 
 	// 从环境变量获取配置
 	arkAPIKey := os.Getenv("ARK_API_KEY")
@@ -60,87 +64,145 @@ func (am *AgentManager) generateTimeline(ctx context.Context, keyword string, mo
 		arkModelID = model.DefaultArkModel
 	}
 
+	// 运行中的实验按分流比例选择提示词或模型变体
+	ctx, run := startExperimentRun(ctx, experiment.TargetTimeline)
+	if run.model() != "" {
+		arkModelID = run.model()
+	}
+
 	// 构建用户请求
-	userPrompt := fmt.Sprintf("请为关键词 '%s' 生成新闻时间线，返回有效的JSON格式结果，包含Keyword和Events字段。Events数组应包含至少5-10个独立的新闻事件，每个事件必须包含以下字段：ID（字符串类型，如\"1\", \"2\", \"3\"等）、Title（字符串，事件标题）、Time（字符串，具体时间如\"2024-01-15\"）、Location（字符串，地点）、People（字符串数组，涉及人物）、Summary（字符串，事件摘要）。确保时间线覆盖不同时间段，从早期到近期，每个事件都应有明确的时间、地点、人物和内容。", keyword)
+	prompts := prompt.FromContext(ctx)
+	systemPrompt, userPrompt, err := arkTimelinePrompts(prompts, keyword, lang)
+	if err != nil {
+		run.finish(0, true)
+		return nil, err
+	}
 
 	// 调用Ark模型
-	response, err := model.SendArkMessage(ctx, arkModelID, userPrompt, "你是一个专业的新闻时间线生成助手。")
+	response, err := model.SendArkMessage(ctx, arkModelID, userPrompt, systemPrompt)
 	if err != nil {
+		run.finish(0, true)
 		return nil, fmt.Errorf("调用Ark模型失败: %w", err)
 	}
+	stats := tool.CallStatsFrom(ctx)
+	stats.AddCall(response.PromptTokens, response.CompletionTokens)
 
 	// 打印模型原始输出日志
 	logutil.LogInfo("Ark模型原始输出: %s", response.Content)
 
-	// 解析返回的JSON
+	// 解析返回的JSON，无法解析时计为一次解析失败并返回错误，由调用方使用后备数据且不保存
+	timeline, err := parseArkTimeline(response.Content)
+	if err != nil {
+		stats.AddParseFailure()
+		run.finish(0, true)
+		return nil, err
+	}
+	attachSources(&timeline, response.Annotations)
+	if lang != "" && lang != prompt.LangZH {
+		timeline.Lang = lang
+	}
+	timeline.PromptVersion = prompts.Version
+	timeline.Experiment = run.tag()
+	run.finish(len(timeline.Events), false)
+
+	return &timeline, nil
+}
+
+// parseArkTimeline 解析 Ark 模型返回的时间链 JSON，兼容外层包含说明文字或代码块的情况
+func parseArkTimeline(content string) (agent.TimelineResponse, error) {
 	var timeline agent.TimelineResponse
-	// 检查响应内容是否为空
-	trimmedContent := strings.TrimSpace(response.Content)
+	trimmedContent := strings.TrimSpace(content)
 	if trimmedContent == "" {
-		logutil.LogInfo("Ark模型返回空响应")
-		// 返回一个基本的响应
-		timeline = agent.TimelineResponse{
-			Keyword: keyword,
-			Events: []agent.Event{
-				{
-					ID:       "1",
-					Title:    fmt.Sprintf("%s 相关事件", keyword),
-					Time:     "2024-01-01",
-					Location: "未知地点",
-					People:   []string{"未知人物"},
-					Summary:  "模型未返回有效内容",
-				},
-			},
+		return timeline, fmt.Errorf("Ark模型返回空响应")
+	}
+	err := json.Unmarshal([]byte(trimmedContent), &timeline)
+	if err == nil {
+		return timeline, nil
+	}
+
+	// 如果直接解析失败，尝试从响应中提取JSON部分
+	logutil.LogInfo("直接解析JSON失败，尝试提取: %v", err)
+	jsonStart := findJSONStart(trimmedContent)
+	if jsonStart == -1 {
+		return timeline, fmt.Errorf("Ark模型响应中未找到JSON内容")
+	}
+	jsonContent := trimmedContent[jsonStart:]
+	if jsonEnd := findJSONEnd(jsonContent); jsonEnd != -1 {
+		jsonContent = jsonContent[:jsonEnd+1]
+	}
+	timeline = agent.TimelineResponse{}
+	if err := json.Unmarshal([]byte(jsonContent), &timeline); err != nil {
+		return timeline, fmt.Errorf("解析Ark模型返回的JSON失败: %w", err)
+	}
+	logutil.LogInfo("成功提取并解析JSON")
+	return timeline, nil
+}
+
+// attachSources 整理模型给出的事件来源：去掉没有链接的来源，并用联网搜索的引用补全标题、站点和发布时间
+func attachSources(timeline *agent.TimelineResponse, annotations []model.Annotation) {
+	cited := make(map[string]model.Annotation, len(annotations))
+	for _, annotation := range annotations {
+		if annotation.URL != "" {
+			cited[annotation.URL] = annotation
 		}
-	} else if err := json.Unmarshal([]byte(trimmedContent), &timeline); err != nil {
-		// 如果直接解析失败，尝试从响应中提取JSON部分
-		logutil.LogInfo("直接解析JSON失败，尝试提取: %v", err)
-		jsonStart := findJSONStart(trimmedContent)
-		if jsonStart != -1 {
-			jsonContent := trimmedContent[jsonStart:]
-			// 尝试找到JSON的结束位置
-			jsonEnd := findJSONEnd(jsonContent)
-			if jsonEnd != -1 {
-				jsonContent = jsonContent[:jsonEnd+1]
+	}
+	for i := range timeline.Events {
+		var sources []agent.EventSource
+		for _, source := range timeline.Events[i].Sources {
+			if source.URL == "" {
+				continue
 			}
-			if err := json.Unmarshal([]byte(jsonContent), &timeline); err == nil {
-				logutil.LogInfo("成功提取并解析JSON")
-			} else {
-				logutil.LogInfo("提取JSON后仍解析失败: %v", err)
-				// 如果JSON解析失败，创建一个基本的响应
-				timeline = agent.TimelineResponse{
-					Keyword: keyword,
-					Events: []agent.Event{
-						{
-							ID:       "1",
-							Title:    fmt.Sprintf("%s 相关事件", keyword),
-							Time:     "2024-01-01",
-							Location: "未知地点",
-							People:   []string{"未知人物"},
-							Summary:  response.Content,
-						},
-					},
+			if annotation, ok := cited[source.URL]; ok {
+				if source.Title == "" {
+					source.Title = annotation.Title
+				}
+				if source.SiteName == "" {
+					source.SiteName = annotation.SiteName
+				}
+				if source.PublishTime == "" {
+					source.PublishTime = annotation.PublishTime
 				}
 			}
+			sources = append(sources, source)
 		}
+		timeline.Events[i].Sources = sources
 	}
+}
 
-	return &timeline, nil
+// arkTimelinePrompts 渲染联网生成时间链的系统提示词和用户提示词
+func arkTimelinePrompts(prompts *prompt.Set, keyword, lang string) (string, string, error) {
+	vars := prompt.Vars{Keyword: keyword, Lang: lang}
+	systemPrompt, err := prompts.Render(prompt.ArkTimelineSystem, lang, vars)
+	if err != nil {
+		return "", "", err
+	}
+	userPrompt, err := prompts.Render(prompt.ArkTimelineUser, lang, vars)
+	if err != nil {
+		return "", "", err
+	}
+	return systemPrompt, userPrompt, nil
 }
 
 // generateGraph 生成知识图谱
 func (am *AgentManager) generateGraph(ctx context.Context, keyword string, timeline *agent.TimelineResponse, mode string) (*agent.GraphResponse, error) {
 	// 生成图谱
 	logutil.LogInfo("开始从 Agent 生成图谱: %s (模式: %s)", keyword, mode)
-	graph, err := am.agent.GenerateGraph(ctx, timeline)
+	if am == nil || am.agent == nil {
+		return nil, fmt.Errorf("Agent 未初始化")
+	}
+	ctx, run := startExperimentRun(ctx, experiment.TargetGraph)
+	graph, err := am.agent.GenerateGraphWithModel(ctx, timeline, run.model())
 	if err != nil {
+		run.finish(0, true)
 		return nil, err
 	}
+	graph.Experiment = run.tag()
+	run.finish(len(graph.Nodes), false)
 
 	return graph, nil
 }
 
-// HandleTimeline 处理时间链请求
+// HandleTimeline 处理时间链请求，支持 from、to、location、person、limit 过滤参数和 lang 输出语言参数
 func HandleTimeline(c *gin.Context) {
 	keyword := c.Query("keyword")
 	if keyword == "" {
@@ -159,20 +221,92 @@ func HandleTimeline(c *gin.Context) {
 		HandleTimelineStream(c)
 		return
 	}
+	filter, ok := parseEventFilter(c)
+	if !ok {
+		return
+	}
+	if _, ok := parseLang(c); !ok {
+		return
+	}
 
-	ctx := c.Request.Context()
+	// 保存完整的时间链，只在响应中应用过滤条件；mock 后备数据只返回不保存
+	timeline, ok := loadTimeline(c, keyword, mode)
+	if ok {
+		saveTimelineRecord(keyword, mode, timeline, nil)
+	}
+	c.JSON(http.StatusOK, filter.FilterTimeline(timeline))
+}
 
+// loadTimeline 生成时间链，输出语言取自 lang 参数，失败时使用 mock 数据作为后备并返回 false
+func loadTimeline(c *gin.Context, keyword string, mode string) (*agent.TimelineResponse, bool) {
 	// 使用 Agent 生成时间链
-	timeline, err := agentManager.generateTimeline(ctx, keyword, mode)
+	timeline, err := agentManager.generateTimeline(c.Request.Context(), keyword, mode, requestLang(c))
 	if err != nil {
 		logutil.LogError("生成时间链失败: %v", err)
 		// 失败时使用 mock 数据作为后备
 		data := mockTimeline(keyword)
-		c.JSON(http.StatusOK, data)
+		return &data, false
+	}
+	return timeline, true
+}
+
+// HandleTimelineExport 处理时间链导出请求，format 支持 ics、csv、md、timelinejs
+// 传入 id 时导出已保存记录的时间链（包括编辑和增量更新后的时间链），否则按 keyword 重新生成时间链后导出
+func HandleTimelineExport(c *gin.Context) {
+	id := c.Query("id")
+	keyword := c.Query("keyword")
+	if id == "" && keyword == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "id 和 keyword 参数不能同时为空",
+		})
+		return
+	}
+	mode := c.Query("mode")
+	if mode == "" {
+		mode = "fast" // 默认模式
+	}
+	// 先校验格式，避免生成完成后才发现格式不支持
+	format := c.DefaultQuery("format", "md")
+	if _, err := export.TimelineFormat(format); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	logutil.LogInfo("时间链导出请求: %s (记录: %s, 格式: %s)", keyword, id, format)
+
+	var timeline *agent.TimelineResponse
+	if id != "" {
+		record, err := timelineRecords.Get(id)
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		if record.Timeline == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "该记录没有时间链"})
+			return
+		}
+		timeline, keyword = record.Timeline, record.Keyword
+	} else {
+		var ok bool
+		if timeline, ok = loadTimeline(c, keyword, mode); !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "生成时间链失败",
+			})
+			return
+		}
+	}
+	data, f, err := export.ExportTimeline(timeline, format)
+	if err != nil {
+		logutil.LogError("导出时间链失败: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
 		return
 	}
 
-	c.JSON(http.StatusOK, timeline)
+	writeExport(c, keyword, data, f)
 }
 
 // HandleTimelineStream 处理时间链流式请求
@@ -186,6 +320,14 @@ func HandleTimelineStream(c *gin.Context) {
 	if mode == "" {
 		mode = "fast" // 默认模式
 	}
+	filter, ok := parseEventFilter(c)
+	if !ok {
+		return
+	}
+	lang, ok := parseLang(c)
+	if !ok {
+		return
+	}
 
 	// 设置 SSE 响应头
 	c.Header("Content-Type", "text/event-stream")
@@ -216,16 +358,31 @@ func HandleTimelineStream(c *gin.Context) {
 		arkModelID = model.ArkFlashModel
 	}
 
+	// 运行中的实验按分流比例选择提示词或模型变体
+	ctx, run := startExperimentRun(ctx, experiment.TargetTimeline)
+	if run.model() != "" {
+		arkModelID = run.model()
+	}
+
 	// 构建用户请求
-	userPrompt := fmt.Sprintf("请为关键词 '%s' 生成新闻时间线，返回有效的JSON格式结果，包含Keyword和Events字段。Events数组应包含至少5-10个独立的新闻事件，每个事件必须包含以下字段：ID（字符串类型，如\"1\", \"2\", \"3\"等）、Title（字符串，事件标题）、Time（字符串，具体时间如\"2024-01-15\"）、Location（字符串，地点）、People（字符串数组，涉及人物）、Summary（字符串，事件摘要）。确保时间线覆盖不同时间段，从早期到近期，每个事件都应有明确的时间、地点、人物和内容。", keyword)
+	prompts := prompt.FromContext(ctx)
+	systemPrompt, userPrompt, err := arkTimelinePrompts(prompts, keyword, lang)
+	if err != nil {
+		run.finish(0, true)
+		logutil.LogError("渲染提示词失败: %v", err)
+		c.SSEvent("error", gin.H{"error": err.Error()})
+		c.Writer.Flush()
+		return
+	}
 
 	// 发送思考过程
 	c.SSEvent("thinking", gin.H{"message": "正在分析关键词并规划时间线生成"})
 	c.Writer.Flush()
 
 	// 调用Ark模型
-	response, err := model.SendArkMessage(ctx, arkModelID, userPrompt, "你是一个专业的新闻时间线生成助手。")
+	response, err := model.SendArkMessage(ctx, arkModelID, userPrompt, systemPrompt)
 	if err != nil {
+		run.finish(0, true)
 		logutil.LogError("调用Ark模型失败: %v", err)
 		c.SSEvent("error", gin.H{"error": fmt.Sprintf("调用Ark模型失败: %v", err)})
 		c.Writer.Flush()
@@ -236,6 +393,9 @@ func HandleTimelineStream(c *gin.Context) {
 		return
 	}
 
+	stats := tool.CallStatsFrom(ctx)
+	stats.AddCall(response.PromptTokens, response.CompletionTokens)
+
 	// 打印模型原始输出日志
 	logutil.LogInfo("Ark模型原始输出: %s", response.Content)
 
@@ -243,61 +403,27 @@ func HandleTimelineStream(c *gin.Context) {
 	c.SSEvent("processing", gin.H{"message": "正在解析模型响应"})
 	c.Writer.Flush()
 
-	// 解析返回的JSON
-	var timeline agent.TimelineResponse
-	// 检查响应内容是否为空
-	trimmedContent := strings.TrimSpace(response.Content)
-	if trimmedContent == "" {
-		logutil.LogInfo("Ark模型返回空响应")
-		// 返回一个基本的响应
-		timeline = agent.TimelineResponse{
-			Keyword: keyword,
-			Events: []agent.Event{
-				{
-					ID:       "1",
-					Title:    fmt.Sprintf("%s 相关事件", keyword),
-					Time:     "2024-01-01",
-					Location: "未知地点",
-					People:   []string{"未知人物"},
-					Summary:  "模型未返回有效内容",
-				},
-			},
-		}
-	} else if err := json.Unmarshal([]byte(trimmedContent), &timeline); err != nil {
-		// 如果直接解析失败，尝试从响应中提取JSON部分
-		logutil.LogInfo("直接解析JSON失败，尝试提取: %v", err)
-		jsonStart := findJSONStart(trimmedContent)
-		if jsonStart != -1 {
-			jsonContent := trimmedContent[jsonStart:]
-			// 尝试找到JSON的结束位置
-			jsonEnd := findJSONEnd(jsonContent)
-			if jsonEnd != -1 {
-				jsonContent = jsonContent[:jsonEnd+1]
-			}
-			if err := json.Unmarshal([]byte(jsonContent), &timeline); err == nil {
-				logutil.LogInfo("成功提取并解析JSON")
-			} else {
-				logutil.LogInfo("提取JSON后仍解析失败: %v", err)
-				// 如果JSON解析失败，创建一个基本的响应
-				timeline = agent.TimelineResponse{
-					Keyword: keyword,
-					Events: []agent.Event{
-						{
-							ID:       "1",
-							Title:    fmt.Sprintf("%s 相关事件", keyword),
-							Time:     "2024-01-01",
-							Location: "未知地点",
-							People:   []string{"未知人物"},
-							Summary:  response.Content,
-						},
-					},
-				}
-			}
-		}
+	// 解析返回的JSON，无法解析时计为一次解析失败并发送错误事件
+	timeline, err := parseArkTimeline(response.Content)
+	if err != nil {
+		stats.AddParseFailure()
+		run.finish(0, true)
+		logutil.LogError("解析时间链失败: %v", err)
+		c.SSEvent("error", gin.H{"error": err.Error()})
+		c.Writer.Flush()
+		return
+	}
+
+	attachSources(&timeline, response.Annotations)
+	if lang != prompt.LangZH {
+		timeline.Lang = lang
 	}
+	timeline.PromptVersion = prompts.Version
+	timeline.Experiment = run.tag()
+	run.finish(len(timeline.Events), false)
 
 	// 发送最终数据
-	c.SSEvent("data", timeline)
+	c.SSEvent("data", filter.FilterTimeline(&timeline))
 	c.Writer.Flush()
 
 	// 发送完成事件
@@ -306,6 +432,9 @@ func HandleTimelineStream(c *gin.Context) {
 }
 
 // HandleGraph 处理知识图谱请求
+// 传入 id 时基于已保存的时间链生成图谱并写回该记录，否则重新生成时间链和图谱并保存为新记录
+// 支持 from、to、location、person、limit 过滤参数，保存完整图谱，响应中只返回满足条件的事件构成的子图；
+// 新生成时按 lang 参数选择输出语言，基于已保存的时间链生成时与时间链语言一致
 func HandleGraph(c *gin.Context) {
 	keyword := c.Query("keyword")
 	if keyword == "" {
@@ -315,28 +444,100 @@ func HandleGraph(c *gin.Context) {
 	if mode == "" {
 		mode = "fast" // 默认模式
 	}
+	filter, ok := parseEventFilter(c)
+	if !ok {
+		return
+	}
+	if _, ok := parseLang(c); !ok {
+		return
+	}
 
-	ctx := c.Request.Context()
+	if id := c.Query("id"); id != "" {
+		record, err := timelineRecords.Get(id)
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		if record.Timeline == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "该记录没有时间链"})
+			return
+		}
+		// 生成失败时不能用 mock 数据覆盖已保存的图谱
+		graph, ok := graphFromTimeline(c, record.Keyword, record.Timeline, mode)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "生成图谱失败"})
+			return
+		}
+		if _, err := updateTimelineRecord(id, "graph", func(r *agent.TimelineRecord) error {
+			replaceGraph(r, graph)
+			return nil
+		}); err != nil {
+			logutil.LogError("保存图谱失败: %v", err)
+		}
+		c.JSON(http.StatusOK, filter.FilterGraph(graph, record.Timeline))
+		return
+	}
 
+	timeline, graph, ok := loadTopic(c, keyword, mode)
+	if ok {
+		saveTimelineRecord(keyword, mode, timeline, graph)
+	}
+	c.JSON(http.StatusOK, filter.FilterGraph(graph, timeline))
+}
+
+// loadGraph 生成时间链和知识图谱，失败时使用 mock 数据作为后备并返回 false
+func loadGraph(c *gin.Context, keyword string, mode string) (*agent.GraphResponse, bool) {
+	_, graph, ok := loadTopic(c, keyword, mode)
+	return graph, ok
+}
+
+// loadTopic 生成时间链和知识图谱，输出语言取自 lang 参数
+// 时间链生成失败时返回 nil 时间链和 mock 图谱，图谱生成失败时返回 mock 图谱，两种情况都返回 false
+func loadTopic(c *gin.Context, keyword string, mode string) (*agent.TimelineResponse, *agent.GraphResponse, bool) {
 	// 先获取时间链
-	timeline, err := agentManager.generateTimeline(ctx, keyword, mode)
+	timeline, err := agentManager.generateTimeline(c.Request.Context(), keyword, mode, requestLang(c))
 	if err != nil {
 		logutil.LogError("获取时间链失败: %v", err)
 		data := mockGraph(keyword)
-		c.JSON(http.StatusOK, data)
-		return
+		enrichGraph(c, &data)
+		return nil, &data, false
 	}
 
-	// 再生成图谱
-	graph, err := agentManager.generateGraph(ctx, keyword, timeline, mode)
+	graph, ok := graphFromTimeline(c, keyword, timeline, mode)
+	return timeline, graph, ok
+}
+
+// graphFromTimeline 基于已有时间链生成图谱，失败时使用 mock 数据作为后备并返回 false
+func graphFromTimeline(c *gin.Context, keyword string, timeline *agent.TimelineResponse, mode string) (*agent.GraphResponse, bool) {
+	graph, err := agentManager.generateGraph(c.Request.Context(), keyword, timeline, mode)
 	if err != nil {
 		logutil.LogError("生成图谱失败: %v", err)
 		data := mockGraph(keyword)
-		c.JSON(http.StatusOK, data)
+		enrichGraph(c, &data)
+		return &data, false
+	}
+
+	enrichGraph(c, graph)
+	return graph, true
+}
+
+// enrichGraph 根据请求参数使用百度百科补充图谱节点
+// enrich=true 开启百科增强，relations=true 同时补充百科关系边
+func enrichGraph(c *gin.Context, graph *agent.GraphResponse) {
+	if !isTrue(c.Query("enrich")) {
 		return
 	}
 
-	c.JSON(http.StatusOK, graph)
+	opts := agent.NewBaikeEnrichOptions()
+	opts.WithRelations = isTrue(c.Query("relations"))
+	if _, err := agent.EnrichGraphWithBaike(c.Request.Context(), graph, opts); err != nil {
+		logutil.LogError("百科增强失败: %v", err)
+	}
+}
+
+// isTrue 判断查询参数是否为真值
+func isTrue(value string) bool {
+	return value == "true" || value == "1"
 }
 
 // mockTimeline 生成 mock 时间链数据
@@ -383,23 +584,23 @@ func mockTimeline(keyword string) agent.TimelineResponse {
 // mockGraph 生成 mock 知识图谱数据
 func mockGraph(keyword string) agent.GraphResponse {
 	nodes := []agent.GraphNode{
-		{ID: "e1", Name: fmt.Sprintf("%s 核心事件", keyword), Category: "事件"},
-		{ID: "e2", Name: fmt.Sprintf("%s 延伸事件", keyword), Category: "事件"},
-		{ID: "p1", Name: "张三", Category: "人物"},
-		{ID: "p2", Name: "李四", Category: "人物"},
-		{ID: "l1", Name: "北京", Category: "地点"},
-		{ID: "l2", Name: "上海", Category: "地点"},
-		{ID: "t1", Name: fmt.Sprintf("%s 政策", keyword), Category: "主题"},
+		{ID: "e1", Name: fmt.Sprintf("%s 核心事件", keyword), Category: "事件", Description: fmt.Sprintf("围绕 %s 的最初报道", keyword), FirstSeen: "2023-01-10", LastSeen: "2023-01-10", Importance: 0.9},
+		{ID: "e2", Name: fmt.Sprintf("%s 延伸事件", keyword), Category: "事件", Description: "事件在区域内的进一步发酵", FirstSeen: "2023-03-05", LastSeen: "2023-03-05", Importance: 0.7},
+		{ID: "p1", Name: "张三", Category: "人物", Aliases: []string{"老张"}, Attributes: map[string]string{"身份": "当事人"}, FirstSeen: "2023-01-10", LastSeen: "2023-01-10", Importance: 0.6},
+		{ID: "p2", Name: "李四", Category: "人物", Attributes: map[string]string{"身份": "目击者"}, FirstSeen: "2023-01-10", LastSeen: "2023-03-05", Importance: 0.5},
+		{ID: "l1", Name: "北京", Category: "地点", FirstSeen: "2023-01-10", LastSeen: "2023-01-10", Importance: 0.4},
+		{ID: "l2", Name: "上海", Category: "地点", FirstSeen: "2023-03-05", LastSeen: "2023-03-05", Importance: 0.4},
+		{ID: "t1", Name: fmt.Sprintf("%s 政策", keyword), Category: "主题", Description: "有关部门发布的官方说明与政策", FirstSeen: "2023-05-20", LastSeen: "2023-08-01", Importance: 0.8},
 	}
 
 	links := []agent.GraphLink{
-		{Source: "e1", Target: "p1", Relation: "相关人物"},
-		{Source: "e1", Target: "l1", Relation: "发生地点"},
-		{Source: "e1", Target: "t1", Relation: "涉及主题"},
-		{Source: "e2", Target: "p2", Relation: "相关人物"},
-		{Source: "e2", Target: "l2", Relation: "发生地点"},
-		{Source: "e2", Target: "t1", Relation: "政策影响"},
-		{Source: "e1", Target: "e2", Relation: "事件演化"},
+		{Source: "e1", Target: "p1", Relation: "相关人物", Weight: 0.8, Time: "2023-01-10", EventIDs: []string{"1"}},
+		{Source: "e1", Target: "l1", Relation: "发生地点", Weight: 0.6, Time: "2023-01-10", Directed: true, EventIDs: []string{"1"}},
+		{Source: "e1", Target: "t1", Relation: "涉及主题", Weight: 0.7, Time: "2023-05-20", Directed: true, EventIDs: []string{"1", "3"}},
+		{Source: "e2", Target: "p2", Relation: "相关人物", Weight: 0.6, Time: "2023-03-05", EventIDs: []string{"2"}},
+		{Source: "e2", Target: "l2", Relation: "发生地点", Weight: 0.6, Time: "2023-03-05", Directed: true, EventIDs: []string{"2"}},
+		{Source: "e2", Target: "t1", Relation: "政策影响", Weight: 0.7, Time: "2023-05-20", Directed: true, EventIDs: []string{"2", "3"}},
+		{Source: "e1", Target: "e2", Relation: "事件演化", Weight: 0.9, Time: "2023-03-05", Directed: true, EventIDs: []string{"1", "2"}},
 	}
 
 	return agent.GraphResponse{
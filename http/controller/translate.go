@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"lineNews/agent"
+	"lineNews/agent/logutil"
+	"lineNews/agent/prompt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseLang 解析 lang 参数，为空时为中文，不支持的语言写入 400 响应
+func parseLang(c *gin.Context) (string, bool) {
+	lang, err := prompt.NormalizeLang(c.Query("lang"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return "", false
+	}
+	return lang, true
+}
+
+// requestLang 返回请求的输出语言，lang 参数无效时使用中文；需要校验的接口应先调用 parseLang
+func requestLang(c *gin.Context) string {
+	lang, err := prompt.NormalizeLang(c.Query("lang"))
+	if err != nil {
+		return prompt.LangZH
+	}
+	return lang
+}
+
+// translate 翻译时间链和图谱
+func (am *AgentManager) translate(ctx context.Context, timeline *agent.TimelineResponse, graph *agent.GraphResponse, lang string) (*agent.TimelineResponse, *agent.GraphResponse, error) {
+	if am == nil || am.agent == nil {
+		return nil, nil, fmt.Errorf("Agent 未初始化")
+	}
+	return am.agent.Translate(ctx, timeline, graph, lang)
+}
+
+// HandleTranslateTimelineRecord 将已保存的时间链和图谱翻译为 lang 指定的语言，译文保存在记录中
+// 译文不生成新版本，记录更新后已有译文标记为过期，需要重新翻译
+func HandleTranslateTimelineRecord(c *gin.Context) {
+	id := c.Param("id")
+	if c.Query("lang") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "lang 参数不能为空",
+		})
+		return
+	}
+	lang, ok := parseLang(c)
+	if !ok {
+		return
+	}
+
+	record, err := timelineRecords.Get(id)
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	if record.Timeline == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "该记录没有时间链",
+		})
+		return
+	}
+	if lang == record.Lang() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("时间链已经是该语言: %s", lang),
+		})
+		return
+	}
+
+	logutil.LogInfo("时间链翻译请求: %s (语言: %s)", id, lang)
+	timeline, graph, err := agentManager.translate(c.Request.Context(), record.Timeline, record.Graph, lang)
+	if err != nil {
+		logutil.LogError("翻译时间链失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("翻译失败: %v", err),
+		})
+		return
+	}
+
+	translation := &agent.Translation{Lang: lang, Version: record.Version, Timeline: timeline, Graph: graph, CreatedAt: time.Now()}
+	record, err = timelineRecords.Update(id, func(r *agent.TimelineRecord) error {
+		r.SetTranslation(translation)
+		return nil
+	})
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	indexTimelineRecord(record)
+
+	logutil.LogInfo("时间链翻译完成: %s (语言: %s, 事件数: %d)", id, lang, len(timeline.Events))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    translation,
+		"stale":   translation.Stale(record),
+	})
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"lineNews/agent"
+	"lineNews/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timelineVersions 专题记录的历史版本，每条记录一个子目录
+func timelineVersions(recordID string) *store.Collection[agent.TimelineVersion] {
+	return store.NewCollection[agent.TimelineVersion]("timeline_versions/" + recordID)
+}
+
+// updateTimelineRecord 在锁内修改专题记录，并将修改后的时间链和图谱保存为新版本
+// 没有版本的旧记录会先把修改前的内容保存为第 1 个版本；保存成功后同步更新全文索引和实体注册表
+func updateTimelineRecord(id, reason string, fn func(r *agent.TimelineRecord) error) (*agent.TimelineRecord, error) {
+	record, err := timelineRecords.Update(id, func(r *agent.TimelineRecord) error {
+		if r.Version == 0 {
+			if err := saveTimelineVersion(r.Snapshot("initial")); err != nil {
+				return err
+			}
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+		return saveTimelineVersion(r.Snapshot(reason))
+	})
+	if err != nil {
+		return nil, err
+	}
+	indexTimelineRecord(record)
+	return record, nil
+}
+
+// saveTimelineVersion 保存专题记录的版本
+func saveTimelineVersion(v *agent.TimelineVersion) error {
+	return timelineVersions(v.RecordID).Save(agent.VersionID(v.Version), v)
+}
+
+// HandleListTimelineVersions 列出专题记录的所有版本
+func HandleListTimelineVersions(c *gin.Context) {
+	id := c.Param("id")
+	record, err := timelineRecords.Get(id)
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	versions, err := timelineVersions(id).List()
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+
+	summaries := make([]agent.VersionSummary, 0, len(versions))
+	for _, v := range versions {
+		summaries = append(summaries, v.Summary())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"record_id": id,
+			"current":   record.Version,
+			"versions":  summaries,
+		},
+	})
+}
+
+// HandleGetTimelineVersion 获取指定版本的时间链和图谱，支持与 HandleGetTimelineRecord 相同的过滤参数
+func HandleGetTimelineVersion(c *gin.Context) {
+	version, ok := parseVersion(c, c.Param("version"))
+	if !ok {
+		return
+	}
+	filter, ok := parseEventFilter(c)
+	if !ok {
+		return
+	}
+	v, err := timelineVersions(c.Param("id")).Get(agent.VersionID(version))
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	v.Graph = filter.FilterGraph(v.Graph, v.Timeline)
+	v.Timeline = filter.FilterTimeline(v.Timeline)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    v,
+	})
+}
+
+// HandleTimelineDiff 比较两个版本的事件和图谱节点，from 默认为 to 的上一个版本，to 默认为当前版本
+func HandleTimelineDiff(c *gin.Context) {
+	id := c.Param("id")
+	record, err := timelineRecords.Get(id)
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+
+	ok := true
+	to := record.Version
+	if value := c.Query("to"); value != "" {
+		if to, ok = parseVersion(c, value); !ok {
+			return
+		}
+	}
+	from := to - 1
+	if value := c.Query("from"); value != "" {
+		if from, ok = parseVersion(c, value); !ok {
+			return
+		}
+	}
+	if from < 1 || to < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "该记录只有一个版本，请指定 from 和 to",
+		})
+		return
+	}
+
+	versions := timelineVersions(id)
+	fromVersion, err := versions.Get(agent.VersionID(from))
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	toVersion, err := versions.Get(agent.VersionID(to))
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    agent.DiffVersions(fromVersion, toVersion),
+	})
+}
+
+// parseVersion 解析版本号，无效时写入 400 响应
+func parseVersion(c *gin.Context, value string) (int, bool) {
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("无效的版本号: %s", value),
+		})
+		return 0, false
+	}
+	return version, true
+}
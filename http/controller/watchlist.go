@@ -0,0 +1,257 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"lineNews/agent"
+	"lineNews/agent/logutil"
+	"lineNews/agent/update"
+	"lineNews/agent/watch"
+	"lineNews/config"
+	"lineNews/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// watchlist 关注的专题列表
+var watchlist = store.NewCollection[watch.Item]("watchlist")
+
+// watchScheduler 关注列表后台刷新调度器
+var watchScheduler *watch.Scheduler
+
+// InitWatchlist 启动关注列表后台刷新，WATCH_CONCURRENCY 限制同时刷新的专题数
+func InitWatchlist(ctx context.Context, cfg *config.Config) {
+	watchScheduler = watch.NewScheduler(watchlist, refreshWatchItem, cfg.WatchConcurrency)
+	watchScheduler.OnRun = publishWatchRun
+	watchScheduler.Start(ctx)
+}
+
+// WatchItemRequest 创建或修改关注专题请求，修改时只更新提供的字段
+type WatchItemRequest struct {
+	Keyword  *string `json:"keyword"`
+	Mode     *string `json:"mode"`     // 首次生成时间链的模式，默认 fast
+	Schedule *string `json:"schedule"` // @hourly、@daily、@weekly、@every 6h 或 6h，默认 @daily
+	Source   *string `json:"source"`   // deepsearch 或 ark，默认 deepsearch
+	Recency  *string `json:"recency"`  // week、month、semiyear、year，为空时自动选择
+	RecordID *string `json:"record_id"`
+	Enabled  *bool   `json:"enabled"`
+}
+
+// apply 将请求中的字段写入关注专题并校验
+func (r *WatchItemRequest) apply(item *watch.Item) error {
+	if r.Keyword != nil {
+		item.Keyword = strings.TrimSpace(*r.Keyword)
+	}
+	if r.Mode != nil {
+		item.Mode = *r.Mode
+	}
+	if r.Source != nil {
+		item.Source = *r.Source
+	}
+	if r.Recency != nil {
+		item.Recency = *r.Recency
+	}
+	if r.RecordID != nil {
+		item.RecordID = *r.RecordID
+	}
+	if r.Enabled != nil {
+		item.Enabled = *r.Enabled
+	}
+	if r.Schedule != nil && *r.Schedule != item.Schedule {
+		item.Schedule = *r.Schedule
+		base := item.CreatedAt
+		if item.LastRunAt != nil {
+			base = *item.LastRunAt
+		}
+		item.NextRunAt = watch.NextRun(item.Schedule, base)
+	}
+
+	if item.Keyword == "" {
+		return fmt.Errorf("keyword 不能为空")
+	}
+	if _, err := watch.ParseSchedule(item.Schedule); err != nil {
+		return err
+	}
+	switch item.Source {
+	case "", update.SourceDeepSearch, update.SourceArk:
+	default:
+		return fmt.Errorf("不支持的搜索来源: %s", item.Source)
+	}
+	item.UpdatedAt = time.Now()
+	return nil
+}
+
+// HandleListWatchlist 列出关注的专题
+func HandleListWatchlist(c *gin.Context) {
+	items, err := watchlist.List()
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    items,
+	})
+}
+
+// HandleCreateWatchItem 添加关注专题，创建后立即进行首次刷新
+func HandleCreateWatchItem(c *gin.Context) {
+	var req WatchItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("请求参数错误: %v", err),
+		})
+		return
+	}
+
+	item := watch.NewItem(store.NewID(), "")
+	if err := req.apply(item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if item.RecordID != "" {
+		if _, err := timelineRecords.Get(item.RecordID); err != nil {
+			respondStoreError(c, err)
+			return
+		}
+	}
+
+	if err := watchlist.Save(item.ID, item); err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	logutil.LogInfo("添加关注专题: %s (%s, 计划: %s)", item.Keyword, item.ID, item.Schedule)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    item,
+	})
+}
+
+// HandleGetWatchItem 获取关注专题及其刷新记录
+func HandleGetWatchItem(c *gin.Context) {
+	item, err := watchlist.Get(c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    item,
+		"running": watchScheduler != nil && watchScheduler.Running(item.ID),
+	})
+}
+
+// HandleUpdateWatchItem 修改关注专题的关键词、刷新计划、搜索来源或启用状态
+func HandleUpdateWatchItem(c *gin.Context) {
+	var req WatchItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("请求参数错误: %v", err),
+		})
+		return
+	}
+
+	var invalid error
+	item, err := watchlist.Update(c.Param("id"), func(item *watch.Item) error {
+		invalid = req.apply(item)
+		return invalid
+	})
+	if invalid != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": invalid.Error(),
+		})
+		return
+	}
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    item,
+	})
+}
+
+// HandleDeleteWatchItem 取消关注专题，已保存的时间链记录保留
+func HandleDeleteWatchItem(c *gin.Context) {
+	if err := watchlist.Delete(c.Param("id")); err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// HandleRefreshWatchItem 立即在后台刷新关注专题，结果写入刷新记录
+func HandleRefreshWatchItem(c *gin.Context) {
+	if watchScheduler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "关注列表调度器未启动",
+		})
+		return
+	}
+
+	err := watchScheduler.Trigger(c.Param("id"))
+	switch {
+	case errors.Is(err, watch.ErrRunning):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	case err != nil:
+		respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+	})
+}
+
+// refreshWatchItem 刷新关注专题：尚无时间链记录时生成并保存，否则只搜索最新事件之后的新闻并合并
+func refreshWatchItem(ctx context.Context, item *watch.Item) (*watch.Result, error) {
+	if item.RecordID != "" {
+		record, err := timelineRecords.Get(item.RecordID)
+		switch {
+		case err == nil && record.Timeline != nil:
+			diff, err := update.Search(ctx, record.Timeline, update.Options{Source: item.Source, Recency: item.Recency})
+			if err != nil {
+				return nil, err
+			}
+			record, err = mergeTimelineUpdate(record.ID, diff)
+			if err != nil {
+				return nil, fmt.Errorf("保存时间链失败: %w", err)
+			}
+			var addedNodes []agent.GraphNode
+			if len(diff.Added) > 0 {
+				record, addedNodes = refreshRecordGraph(ctx, record)
+				publishTimelineUpdated(record, diff, addedNodes, "watch", item.ID)
+			}
+			return &watch.Result{RecordID: record.ID, Added: diff.Added, AddedNodes: addedNodes, Skipped: diff.Skipped}, nil
+		case err != nil && !errors.Is(err, store.ErrNotFound):
+			return nil, err
+		}
+		logutil.LogInfo("关注专题的时间链记录不可用，重新生成: %s", item.Keyword)
+	}
+
+	timeline, err := agentManager.generateTimeline(ctx, item.Keyword, item.Mode, "")
+	if err != nil {
+		return nil, err
+	}
+	agent.SortEventsByTime(timeline.Events)
+	record := saveTimelineRecord(item.Keyword, item.Mode, timeline, nil)
+	if record == nil {
+		return nil, fmt.Errorf("保存时间链记录失败")
+	}
+	return &watch.Result{RecordID: record.ID, Created: true, Added: timeline.Events}, nil
+}
@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"lineNews/agent"
+	"lineNews/agent/logutil"
+	"lineNews/agent/update"
+	"lineNews/agent/watch"
+	"lineNews/agent/webhook"
+	"lineNews/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// webhookSubscriptions Webhook 订阅
+	webhookSubscriptions = store.NewCollection[webhook.Subscription]("webhooks")
+	// webhookDeliveries Webhook 投递记录
+	webhookDeliveries = store.NewCollection[webhook.Delivery]("webhook_deliveries")
+	// webhookDispatcher Webhook 事件分发器
+	webhookDispatcher *webhook.Dispatcher
+)
+
+// InitWebhooks 启动 Webhook 分发器，并恢复未完成的投递
+func InitWebhooks(ctx context.Context) {
+	webhookDispatcher = webhook.NewDispatcher(webhookSubscriptions, webhookDeliveries)
+	webhookDispatcher.Start(ctx)
+}
+
+// WebhookRequest 创建或修改 Webhook 订阅请求，修改时只更新提供的字段
+type WebhookRequest struct {
+	URL      *string   `json:"url"`
+	Secret   *string   `json:"secret"` // 为空时自动生成
+	Events   *[]string `json:"events"` // timeline.updated、refresh.finished，为空时订阅全部
+	WatchIDs *[]string `json:"watch_ids"`
+	Enabled  *bool     `json:"enabled"`
+}
+
+// apply 将请求中的字段写入订阅并校验
+func (r *WebhookRequest) apply(sub *webhook.Subscription) error {
+	if r.URL != nil {
+		sub.URL = *r.URL
+	}
+	if r.Secret != nil && *r.Secret != "" {
+		sub.Secret = *r.Secret
+	}
+	if r.Events != nil {
+		sub.Events = *r.Events
+	}
+	if r.WatchIDs != nil {
+		sub.WatchIDs = *r.WatchIDs
+	}
+	if r.Enabled != nil {
+		sub.Enabled = *r.Enabled
+	}
+	sub.UpdatedAt = time.Now()
+	return sub.Validate()
+}
+
+// redactSubscription 隐藏签名密钥
+func redactSubscription(sub *webhook.Subscription) *webhook.Subscription {
+	redacted := *sub
+	redacted.Secret = ""
+	return &redacted
+}
+
+// HandleListWebhooks 列出 Webhook 订阅
+func HandleListWebhooks(c *gin.Context) {
+	subs, err := webhookSubscriptions.List()
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	for i, sub := range subs {
+		subs[i] = redactSubscription(sub)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    subs,
+	})
+}
+
+// HandleCreateWebhook 创建 Webhook 订阅，响应中返回签名密钥，之后不再返回
+func HandleCreateWebhook(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("请求参数错误: %v", err),
+		})
+		return
+	}
+
+	now := time.Now()
+	sub := &webhook.Subscription{
+		ID:        store.NewID(),
+		Secret:    store.NewID() + store.NewID(),
+		Events:    []string{},
+		WatchIDs:  []string{},
+		Enabled:   true,
+		CreatedAt: now,
+	}
+	if err := req.apply(sub); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := webhookSubscriptions.Save(sub.ID, sub); err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	logutil.LogInfo("创建 Webhook 订阅: %s -> %s", sub.ID, sub.URL)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+// HandleGetWebhook 获取 Webhook 订阅
+func HandleGetWebhook(c *gin.Context) {
+	sub, err := webhookSubscriptions.Get(c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    redactSubscription(sub),
+	})
+}
+
+// HandleUpdateWebhook 修改 Webhook 订阅
+func HandleUpdateWebhook(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("请求参数错误: %v", err),
+		})
+		return
+	}
+
+	var invalid error
+	sub, err := webhookSubscriptions.Update(c.Param("id"), func(sub *webhook.Subscription) error {
+		invalid = req.apply(sub)
+		return invalid
+	})
+	if invalid != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": invalid.Error(),
+		})
+		return
+	}
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    redactSubscription(sub),
+	})
+}
+
+// HandleDeleteWebhook 删除 Webhook 订阅，未完成的投递不再重试
+func HandleDeleteWebhook(c *gin.Context) {
+	if err := webhookSubscriptions.Delete(c.Param("id")); err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// HandleTestWebhook 向订阅发送 ping 事件，用于验证地址和签名
+func HandleTestWebhook(c *gin.Context) {
+	sub, err := webhookSubscriptions.Get(c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	delivery, err := webhookDispatcher.Send(sub.ID, webhook.EventPing, gin.H{
+		"subscription_id": sub.ID,
+		"message":         "LineNews Webhook 测试",
+	})
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    delivery,
+	})
+}
+
+// HandleListWebhookDeliveries 查询订阅的投递记录，按创建时间倒序
+// 支持 status=pending|succeeded|failed、event 过滤和 limit（默认 50，最大 200）
+func HandleListWebhookDeliveries(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := webhookSubscriptions.Get(id); err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit 必须为 1-200 之间的整数",
+		})
+		return
+	}
+
+	all, err := webhookDeliveries.List()
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	status, event := c.Query("status"), c.Query("event")
+	deliveries := make([]*webhook.Delivery, 0, len(all))
+	for _, d := range all {
+		if d.SubscriptionID != id || (status != "" && d.Status != status) || (event != "" && d.Event != event) {
+			continue
+		}
+		deliveries = append(deliveries, d)
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	total := len(deliveries)
+	if total > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"total":   total,
+		"data":    deliveries,
+	})
+}
+
+// HandleRedeliverWebhook 重新发送一条已结束的投递
+func HandleRedeliverWebhook(c *gin.Context) {
+	delivery, err := webhookDeliveries.Get(c.Param("delivery"))
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	if delivery.SubscriptionID != c.Param("id") {
+		respondStoreError(c, store.ErrNotFound)
+		return
+	}
+
+	delivery, err = webhookDispatcher.Redeliver(delivery.ID)
+	if errors.Is(err, webhook.ErrPending) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    delivery,
+	})
+}
+
+// publishTimelineUpdated 时间链新增事件后通知订阅方，trigger 为 watch 或 manual
+func publishTimelineUpdated(record *agent.TimelineRecord, diff *update.Diff, addedNodes []agent.GraphNode, trigger, watchID string) {
+	if webhookDispatcher == nil {
+		return
+	}
+	webhookDispatcher.Publish(webhook.EventTimelineUpdated, watchID, gin.H{
+		"record_id":    record.ID,
+		"keyword":      record.Keyword,
+		"trigger":      trigger,
+		"watch_id":     watchID,
+		"since":        diff.Since,
+		"source":       diff.Source,
+		"added_events": diff.Added,
+		"added_nodes":  addedNodes,
+		"total_events": len(record.Timeline.Events),
+	})
+}
+
+// publishWatchRun 关注专题刷新结束后通知订阅方
+func publishWatchRun(item *watch.Item, run watch.Run) {
+	if webhookDispatcher == nil {
+		return
+	}
+	webhookDispatcher.Publish(webhook.EventRefreshFinished, item.ID, gin.H{
+		"watch_id":    item.ID,
+		"keyword":     item.Keyword,
+		"record_id":   item.RecordID,
+		"run":         run,
+		"next_run_at": item.NextRunAt,
+	})
+}
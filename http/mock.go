@@ -49,23 +49,23 @@ func MockTimeline(keyword string) agent.TimelineResponse {
 // MockGraph 生成 mock 知识图谱数据
 func MockGraph(keyword string) agent.GraphResponse {
 	nodes := []agent.GraphNode{
-		{ID: "e1", Name: fmt.Sprintf("%s 核心事件", keyword), Category: "事件"},
-		{ID: "e2", Name: fmt.Sprintf("%s 延伸事件", keyword), Category: "事件"},
-		{ID: "p1", Name: "张三", Category: "人物"},
-		{ID: "p2", Name: "李四", Category: "人物"},
-		{ID: "l1", Name: "北京", Category: "地点"},
-		{ID: "l2", Name: "上海", Category: "地点"},
-		{ID: "t1", Name: fmt.Sprintf("%s 政策", keyword), Category: "主题"},
+		{ID: "e1", Name: fmt.Sprintf("%s 核心事件", keyword), Category: "事件", Description: fmt.Sprintf("围绕 %s 的最初报道", keyword), FirstSeen: "2023-01-10", LastSeen: "2023-01-10", Importance: 0.9},
+		{ID: "e2", Name: fmt.Sprintf("%s 延伸事件", keyword), Category: "事件", Description: "事件在区域内的进一步发酵", FirstSeen: "2023-03-05", LastSeen: "2023-03-05", Importance: 0.7},
+		{ID: "p1", Name: "张三", Category: "人物", Aliases: []string{"老张"}, Attributes: map[string]string{"身份": "当事人"}, FirstSeen: "2023-01-10", LastSeen: "2023-01-10", Importance: 0.6},
+		{ID: "p2", Name: "李四", Category: "人物", Attributes: map[string]string{"身份": "目击者"}, FirstSeen: "2023-01-10", LastSeen: "2023-03-05", Importance: 0.5},
+		{ID: "l1", Name: "北京", Category: "地点", FirstSeen: "2023-01-10", LastSeen: "2023-01-10", Importance: 0.4},
+		{ID: "l2", Name: "上海", Category: "地点", FirstSeen: "2023-03-05", LastSeen: "2023-03-05", Importance: 0.4},
+		{ID: "t1", Name: fmt.Sprintf("%s 政策", keyword), Category: "主题", Description: "有关部门发布的官方说明与政策", FirstSeen: "2023-05-20", LastSeen: "2023-08-01", Importance: 0.8},
 	}
 
 	links := []agent.GraphLink{
-		{Source: "e1", Target: "p1", Relation: "相关人物"},
-		{Source: "e1", Target: "l1", Relation: "发生地点"},
-		{Source: "e1", Target: "t1", Relation: "涉及主题"},
-		{Source: "e2", Target: "p2", Relation: "相关人物"},
-		{Source: "e2", Target: "l2", Relation: "发生地点"},
-		{Source: "e2", Target: "t1", Relation: "政策影响"},
-		{Source: "e1", Target: "e2", Relation: "事件演化"},
+		{Source: "e1", Target: "p1", Relation: "相关人物", Weight: 0.8, Time: "2023-01-10", EventIDs: []string{"1"}},
+		{Source: "e1", Target: "l1", Relation: "发生地点", Weight: 0.6, Time: "2023-01-10", Directed: true, EventIDs: []string{"1"}},
+		{Source: "e1", Target: "t1", Relation: "涉及主题", Weight: 0.7, Time: "2023-05-20", Directed: true, EventIDs: []string{"1", "3"}},
+		{Source: "e2", Target: "p2", Relation: "相关人物", Weight: 0.6, Time: "2023-03-05", EventIDs: []string{"2"}},
+		{Source: "e2", Target: "l2", Relation: "发生地点", Weight: 0.6, Time: "2023-03-05", Directed: true, EventIDs: []string{"2"}},
+		{Source: "e2", Target: "t1", Relation: "政策影响", Weight: 0.7, Time: "2023-05-20", Directed: true, EventIDs: []string{"2", "3"}},
+		{Source: "e1", Target: "e2", Relation: "事件演化", Weight: 0.9, Time: "2023-03-05", Directed: true, EventIDs: []string{"1", "2"}},
 	}
 
 	return agent.GraphResponse{
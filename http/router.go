@@ -29,6 +29,89 @@ func SetupRouter() *gin.Engine {
 		api.GET("/timeline", controller.HandleTimeline)  // 时间链
 		api.GET("/graph", controller.HandleGraph)        // 知识图谱
 
+		// 时间链导出路由
+		api.GET("/timeline/export", controller.HandleTimelineExport) // GET /api/timeline/export?id=xxx|keyword=xxx&format=ics|csv|md|timelinejs
+
+		// 时间链对比路由
+		api.GET("/timeline/compare", controller.HandleTimelineCompare) // GET /api/timeline/compare?keywords=a,b&period=auto|year|month|day
+
+		// 基于文档生成时间链路由
+		api.POST("/timeline/from-documents", controller.HandleTimelineFromDocuments) // POST /api/timeline/from-documents
+
+		// 知识图谱分析路由
+		api.GET("/graph/analyze", controller.HandleGraphAnalyze) // GET /api/graph/analyze?id=xxx|keyword=xxx&node=xxx&hops=2&source=xxx&target=xxx
+		api.GET("/graph/export", controller.HandleGraphExport)   // GET /api/graph/export?id=xxx|keyword=xxx&format=graphml|gexf|cypher|turtle
+		api.POST("/graph/merge", controller.HandleGraphMerge)    // POST /api/graph/merge
+
+		// 专题简报路由
+		api.GET("/report", controller.HandleReport) // GET /api/report?keyword=xxx&format=html|pdf
+
+		// 专题记录与问答路由
+		api.GET("/timelines/:id", controller.HandleGetTimelineRecord)                    // GET /api/timelines/:id
+		api.POST("/timelines/:id/update", controller.HandleUpdateTimelineRecord)         // POST /api/timelines/:id/update?source=deepsearch|ark&recency=week|month
+		api.POST("/timelines/:id/regenerate", controller.HandleRegenerateTimelineRecord) // POST /api/timelines/:id/regenerate 按约束重新生成
+		api.POST("/timelines/:id/translate", controller.HandleTranslateTimelineRecord)   // POST /api/timelines/:id/translate?lang=en
+		api.POST("/ask", controller.HandleAsk)                                           // POST /api/ask
+
+		// 事件检索路由
+		api.GET("/search/events", controller.HandleSearchEvents) // GET /api/search/events?q=xxx&location=xxx&person=xxx&from=2020&to=2024&limit=20&offset=0
+
+		// 跨专题实体路由
+		api.GET("/entities", controller.HandleListEntities)  // GET /api/entities?q=xxx&category=人物&limit=50
+		api.GET("/entities/:id", controller.HandleGetEntity) // GET /api/entities/:id
+
+		// 人工编辑路由
+		api.POST("/timelines/:id/events", controller.HandleCreateEvent)          // POST /api/timelines/:id/events
+		api.PATCH("/timelines/:id/events/:event", controller.HandleUpdateEvent)  // PATCH /api/timelines/:id/events/:event
+		api.DELETE("/timelines/:id/events/:event", controller.HandleDeleteEvent) // DELETE /api/timelines/:id/events/:event?author=xxx
+		api.POST("/timelines/:id/nodes", controller.HandleCreateNode)            // POST /api/timelines/:id/nodes
+		api.PATCH("/timelines/:id/nodes/:node", controller.HandleUpdateNode)     // PATCH /api/timelines/:id/nodes/:node
+		api.DELETE("/timelines/:id/nodes/:node", controller.HandleDeleteNode)    // DELETE /api/timelines/:id/nodes/:node?author=xxx
+		api.POST("/timelines/:id/links", controller.HandleCreateLink)            // POST /api/timelines/:id/links
+		api.PATCH("/timelines/:id/links/:link", controller.HandleUpdateLink)     // PATCH /api/timelines/:id/links/:link
+		api.DELETE("/timelines/:id/links/:link", controller.HandleDeleteLink)    // DELETE /api/timelines/:id/links/:link?author=xxx
+		api.GET("/timelines/:id/edits", controller.HandleListEdits)              // GET /api/timelines/:id/edits?kind=event&author=xxx
+
+		// 专题版本路由
+		api.GET("/timelines/:id/versions", controller.HandleListTimelineVersions)        // GET /api/timelines/:id/versions
+		api.GET("/timelines/:id/versions/:version", controller.HandleGetTimelineVersion) // GET /api/timelines/:id/versions/:version
+		api.GET("/timelines/:id/diff", controller.HandleTimelineDiff)                    // GET /api/timelines/:id/diff?from=1&to=2
+
+		// 关注列表路由
+		api.GET("/watchlist", controller.HandleListWatchlist)                 // GET /api/watchlist
+		api.POST("/watchlist", controller.HandleCreateWatchItem)              // POST /api/watchlist
+		api.GET("/watchlist/:id", controller.HandleGetWatchItem)              // GET /api/watchlist/:id
+		api.PUT("/watchlist/:id", controller.HandleUpdateWatchItem)           // PUT /api/watchlist/:id
+		api.DELETE("/watchlist/:id", controller.HandleDeleteWatchItem)        // DELETE /api/watchlist/:id
+		api.POST("/watchlist/:id/refresh", controller.HandleRefreshWatchItem) // POST /api/watchlist/:id/refresh
+
+		// Webhook 订阅路由
+		api.GET("/webhooks", controller.HandleListWebhooks)                                         // GET /api/webhooks
+		api.POST("/webhooks", controller.HandleCreateWebhook)                                       // POST /api/webhooks
+		api.GET("/webhooks/:id", controller.HandleGetWebhook)                                       // GET /api/webhooks/:id
+		api.PUT("/webhooks/:id", controller.HandleUpdateWebhook)                                    // PUT /api/webhooks/:id
+		api.DELETE("/webhooks/:id", controller.HandleDeleteWebhook)                                 // DELETE /api/webhooks/:id
+		api.POST("/webhooks/:id/test", controller.HandleTestWebhook)                                // POST /api/webhooks/:id/test
+		api.GET("/webhooks/:id/deliveries", controller.HandleListWebhookDeliveries)                 // GET /api/webhooks/:id/deliveries?status=failed&limit=50
+		api.POST("/webhooks/:id/deliveries/:delivery/redeliver", controller.HandleRedeliverWebhook) // POST /api/webhooks/:id/deliveries/:delivery/redeliver
+
+		// 提示词模板路由
+		api.GET("/prompts", controller.HandleListPrompts)           // GET /api/prompts
+		api.POST("/prompts/reload", controller.HandleReloadPrompts) // POST /api/prompts/reload
+
+		// 实验路由
+		api.GET("/experiments", controller.HandleListExperiments)         // GET /api/experiments
+		api.POST("/experiments", controller.HandleCreateExperiment)       // POST /api/experiments
+		api.GET("/experiments/:id", controller.HandleGetExperiment)       // GET /api/experiments/:id
+		api.PUT("/experiments/:id", controller.HandleUpdateExperiment)    // PUT /api/experiments/:id
+		api.DELETE("/experiments/:id", controller.HandleDeleteExperiment) // DELETE /api/experiments/:id
+
+		// 多轮对话路由
+		api.POST("/chat/sessions", controller.HandleCreateChatSession)               // POST /api/chat/sessions
+		api.GET("/chat/sessions/:id", controller.HandleGetChatSession)               // GET /api/chat/sessions/:id
+		api.DELETE("/chat/sessions/:id", controller.HandleDeleteChatSession)         // DELETE /api/chat/sessions/:id
+		api.POST("/chat/sessions/:id/messages", controller.HandleChatSessionMessage) // POST /api/chat/sessions/:id/messages
+
 		// 百度深度搜索路由
 		api.GET("/deepsearch/search", controller.HandleDeepSearch)        // GET /api/deepsearch/search?query=xxx
 		api.POST("/deepsearch/custom", controller.HandleDeepSearchCustom) // POST /api/deepsearch/custom
@@ -38,7 +121,8 @@ func SetupRouter() *gin.Engine {
 		api.GET("/baike/lemma", controller.HandleBaikeSearchByLemmaId) // GET /api/baike/lemma?lemma_id=xxx
 
 		// Ark Chat 路由
-		api.GET("/ark/chat", controller.HandleArkChat) // GET /api/ark/chat?message=xxx
+		api.GET("/ark/chat", controller.HandleArkChat)            // GET /api/ark/chat?message=xxx
+		api.POST("/ark/chat", controller.HandleArkChatMultimodal) // POST /api/ark/chat (JSON 或 multipart，支持图片)
 
 		// DeepSeek 路由
 		api.GET("/deepseek/chat", controller.HandleDeepSeekChat) // GET /api/deepseek/chat?message=xxx
@@ -7,18 +7,38 @@ import (
 	"lineNews/config"
 	"lineNews/http"
 	"lineNews/http/controller"
+	"lineNews/store"
 )
 
 func main() {
 	// 加载配置
 	cfg := config.LoadConfig()
 
-	// 初始化 Agent
+	// 设置数据存储目录
+	store.SetDataDir(cfg.DataDir)
+
 	ctx := context.Background()
+
+	// 加载提示词模板，并在模板目录变化时热加载
+	controller.InitPrompts(ctx, cfg)
+
+	// 初始化 Agent
 	if err := controller.InitAgent(ctx, cfg); err != nil {
 		logutil.LogError("初始化失败: %v", err)
 	}
 
+	// 初始化报告渲染器
+	controller.InitReport(cfg)
+
+	// 构建事件全文索引和实体注册表
+	controller.InitIndexes()
+
+	// 启动 Webhook 分发器
+	controller.InitWebhooks(ctx)
+
+	// 启动关注列表后台刷新
+	controller.InitWatchlist(ctx, cfg)
+
 	// 设置路由
 	r := http.SetupRouter()
 
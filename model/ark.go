@@ -1,6 +1,7 @@
 package model
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -9,6 +10,7 @@ import (
 	"lineNews/agent/logutil"
 	"net/http"
 	"os"
+	"strings"
 )
 
 // ==================== 常量定义 ====================
@@ -23,6 +25,7 @@ const (
 // ArkChatResponse 聊天响应
 type ArkChatResponse struct {
 	Content          string
+	Annotations      []Annotation // 联网搜索引用的网页
 	PromptTokens     int
 	CompletionTokens int
 	TotalTokens      int
@@ -62,9 +65,9 @@ type MessageModel struct {
 
 // ContentItemModel 消息内容项
 type ContentItemModel struct {
-	Type     string        `json:"type"`
-	Text     string        `json:"text,omitempty"`
-	ImageUrl ImageURLModel `json:"image_url,omitempty"`
+	Type     string         `json:"type"`
+	Text     string         `json:"text,omitempty"`
+	ImageUrl *ImageURLModel `json:"image_url,omitempty"`
 }
 
 // ImageURLModel 图像URL
@@ -181,6 +184,42 @@ type WebSearchUsage struct {
 	SearchEngine int `json:"search_engine"`
 }
 
+// ArkChatCompletionRequest chat/completions 接口请求结构
+type ArkChatCompletionRequest struct {
+	Model               string            `json:"model"`
+	Messages            []MessageModel    `json:"messages"`
+	MaxCompletionTokens int               `json:"max_completion_tokens,omitempty"`
+	Stream              bool              `json:"stream"`
+	StreamOptions       *ArkStreamOptions `json:"stream_options,omitempty"`
+}
+
+// ArkStreamOptions 流式选项
+type ArkStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// ArkStreamChunk 流式响应分片
+type ArkStreamChunk struct {
+	ID      string            `json:"id"`
+	Model   string            `json:"model"`
+	Choices []ArkStreamChoice `json:"choices"`
+	Usage   *UsageModel       `json:"usage,omitempty"`
+}
+
+// ArkStreamChoice 流式响应选择项
+type ArkStreamChoice struct {
+	Index        int            `json:"index"`
+	Delta        ArkStreamDelta `json:"delta"`
+	FinishReason string         `json:"finish_reason"`
+}
+
+// ArkStreamDelta 流式增量内容
+type ArkStreamDelta struct {
+	Role             string `json:"role,omitempty"`
+	Content          string `json:"content,omitempty"`
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+}
+
 // ==================== 核心操作 ====================
 
 // CreateArkChatModel 创建 Ark ChatModel - 这个函数现在只是返回一个标识，实际调用在发送消息时进行
@@ -224,7 +263,7 @@ func SendArkMessage(ctx context.Context, modelID string, userMessage string, sys
 	requestBody := ArkRequestWithTools{
 		Model:  modelID,
 		Stream: false,
-		Tools:  []Tool{{Type: "web_search"}},
+		Tools:  WebSearchTools,
 		Input: []ArkInput{
 			{
 				Role: "user",
@@ -250,6 +289,35 @@ func SendArkMessage(ctx context.Context, modelID string, userMessage string, sys
 		}}, requestBody.Input...)
 	}
 
+	return SendArkMessageWithHistory(ctx, modelID, requestBody.Input, requestBody.Tools)
+}
+
+// WebSearchTools 开启联网搜索的工具列表
+var WebSearchTools = []Tool{{Type: "web_search"}}
+
+// SendArkMessageWithHistory 发送多轮对话到Ark，input 按时间顺序包含 system、user、assistant 消息
+// tools 为模型可用的工具，传 WebSearchTools 开启联网搜索，为空时只根据输入内容回答
+func SendArkMessageWithHistory(ctx context.Context, modelID string, input []ArkInput, tools []Tool) (*ArkChatResponse, error) {
+	apiKey := os.Getenv("ARK_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("ARK_API_KEY 环境变量未设置")
+	}
+
+	if modelID == "" {
+		modelID = DefaultArkModel
+	}
+
+	if len(input) == 0 {
+		return nil, fmt.Errorf("消息列表为空")
+	}
+
+	requestBody := ArkRequestWithTools{
+		Model:  modelID,
+		Stream: false,
+		Tools:  tools,
+		Input:  input,
+	}
+
 	// 序列化请求体
 	reqBody, err := json.Marshal(requestBody)
 	if err != nil {
@@ -293,12 +361,14 @@ func SendArkMessage(ctx context.Context, modelID string, userMessage string, sys
 
 	// 提取响应内容 - 根据实际的API响应结构，查找type为message的output项
 	responseText := ""
+	var annotations []Annotation
 	for _, output := range apiResp.Output {
 		if output.Type == "message" && output.Role == "assistant" {
 			// 在content数组中查找text类型的内容
 			for _, content := range output.Content {
 				if content.Type == "output_text" && content.Text != "" {
 					responseText = content.Text
+					annotations = content.Annotations
 					break
 				}
 			}
@@ -329,6 +399,7 @@ func SendArkMessage(ctx context.Context, modelID string, userMessage string, sys
 	// 返回结果
 	result := &ArkChatResponse{
 		Content:          responseText,
+		Annotations:      annotations,
 		PromptTokens:     apiResp.Usage.PromptTokens,
 		CompletionTokens: apiResp.Usage.CompletionTokens,
 		TotalTokens:      apiResp.Usage.TotalTokens,
@@ -337,6 +408,94 @@ func SendArkMessage(ctx context.Context, modelID string, userMessage string, sys
 	return result, nil
 }
 
+// StreamArkChatCompletion 以流式方式调用 Ark chat/completions 接口，每收到一段增量内容调用一次 onDelta
+// 请求会开启 include_usage，返回拼接后的完整内容和最后一个分片携带的 Token 使用统计
+func StreamArkChatCompletion(ctx context.Context, request *ArkChatCompletionRequest, onDelta func(delta string) error) (*ArkChatResponse, error) {
+	apiKey := os.Getenv("ARK_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("ARK_API_KEY 环境变量未设置")
+	}
+
+	if request.Model == "" {
+		request.Model = ArkFlashModel
+	}
+	request.Stream = true
+	request.StreamOptions = &ArkStreamOptions{IncludeUsage: true}
+
+	// 序列化请求体
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %w", err)
+	}
+
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://ark.cn-beijing.volces.com/api/v3/chat/completions", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+
+	// 设置请求头
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+
+	// 发送请求
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 检查响应状态
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(respBody))
+	}
+
+	result := &ArkChatResponse{}
+	var content strings.Builder
+
+	// 逐行解析 SSE，数据行格式为 "data: {...}"，以 "data: [DONE]" 结束
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk ArkStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("解析流式响应失败: %w", err)
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return nil, err
+			}
+		}
+		if chunk.Usage != nil {
+			result.PromptTokens = chunk.Usage.PromptTokens
+			result.CompletionTokens = chunk.Usage.CompletionTokens
+			result.TotalTokens = chunk.Usage.TotalTokens
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取流式响应失败: %w", err)
+	}
+
+	result.Content = content.String()
+	return result, nil
+}
+
 // ==================== 工具函数 ====================
 
 // LogArkChatResponse 打印响应信息
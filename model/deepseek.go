@@ -2,8 +2,11 @@ package model
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"sync"
 
 	"lineNews/agent/logutil"
 
@@ -150,6 +153,73 @@ func SendMessageWithHistory(ctx context.Context, chatModel *deepseek.ChatModel,
 	return result, nil
 }
 
+// StreamMessageWithHistory 以流式方式发送消息（支持对话历史），每收到一段增量内容调用一次 onDelta
+// 返回拼接后的完整内容和 Token 使用统计；当前 DeepSeek 客户端不开启流式使用统计，Token 数通常为 0
+func StreamMessageWithHistory(ctx context.Context, chatModel *deepseek.ChatModel, messages []*schema.Message, onDelta func(delta string) error) (*DSChatResponse, error) {
+	if chatModel == nil {
+		return nil, fmt.Errorf("ChatModel 为空")
+	}
+
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("消息列表为空")
+	}
+
+	stream, err := chatModel.Stream(ctx, messages)
+	if err != nil {
+		return nil, fmt.Errorf("创建流式响应失败: %w", err)
+	}
+	defer stream.Close()
+
+	result := &DSChatResponse{}
+	var content []byte
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("接收流式响应失败: %w", err)
+		}
+
+		if chunk.Content != "" {
+			content = append(content, chunk.Content...)
+			if err := onDelta(chunk.Content); err != nil {
+				return nil, err
+			}
+		}
+		// 使用统计通常只出现在最后一个分片
+		if chunk.ResponseMeta != nil && chunk.ResponseMeta.Usage != nil {
+			result.PromptTokens = chunk.ResponseMeta.Usage.PromptTokens
+			result.CompletionTokens = chunk.ResponseMeta.Usage.CompletionTokens
+			result.TotalTokens = chunk.ResponseMeta.Usage.TotalTokens
+		}
+	}
+
+	result.Content = string(content)
+	return result, nil
+}
+
+var (
+	sharedDSMu    sync.Mutex
+	sharedDSModel *deepseek.ChatModel
+)
+
+// SharedDSChatModel 返回进程内共享的 DeepSeek ChatModel，避免每次请求重复创建
+// 只缓存创建成功的模型，创建失败时下次调用会重试
+func SharedDSChatModel() (*deepseek.ChatModel, error) {
+	sharedDSMu.Lock()
+	defer sharedDSMu.Unlock()
+	if sharedDSModel != nil {
+		return sharedDSModel, nil
+	}
+	chatModel, err := CreateDSChatModel(context.Background(), NewDSModelConfig())
+	if err != nil {
+		return nil, err
+	}
+	sharedDSModel = chatModel
+	return sharedDSModel, nil
+}
+
 // ==================== 工具函数 ====================
 
 // LogDSChatResponse 打印响应信息
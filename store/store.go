@@ -0,0 +1,211 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("记录不存在")
+
+// ErrInvalidID 记录 ID 为空或包含路径分隔符
+var ErrInvalidID = errors.New("无效的记录 ID")
+
+var (
+	dataDirMu sync.RWMutex
+	dataDir   = "data"
+)
+
+// SetDataDir 设置数据根目录，每个集合保存在其下的同名子目录中
+func SetDataDir(dir string) {
+	dataDirMu.Lock()
+	defer dataDirMu.Unlock()
+	if dir != "" {
+		dataDir = dir
+	}
+}
+
+// DataDir 返回数据根目录
+func DataDir() string {
+	dataDirMu.RLock()
+	defer dataDirMu.RUnlock()
+	return dataDir
+}
+
+// NewID 生成随机记录 ID
+func NewID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("生成随机 ID 失败: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// Collection 基于 JSON 文件的记录集合，每条记录保存为 <数据目录>/<集合名>/<id>.json
+type Collection[T any] struct {
+	name string
+	mu   sync.RWMutex
+}
+
+// NewCollection 创建记录集合，目录在首次写入时创建
+func NewCollection[T any](name string) *Collection[T] {
+	return &Collection[T]{name: name}
+}
+
+// Get 读取记录
+func (c *Collection[T]) Get(id string) (*T, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.read(id)
+}
+
+// Save 写入记录，已存在时覆盖
+func (c *Collection[T]) Save(id string, value *T) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.write(id, value)
+}
+
+// Update 在锁内读取、修改并写回记录，fn 返回错误时不写回
+func (c *Collection[T]) Update(id string, fn func(value *T) error) (*T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value, err := c.read(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(value); err != nil {
+		return nil, err
+	}
+	if err := c.write(id, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Delete 删除记录
+func (c *Collection[T]) Delete(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path, err := c.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("删除记录失败: %w", err)
+	}
+	return nil
+}
+
+// List 列出全部记录，按 ID 排序
+func (c *Collection[T]) List() ([]*T, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entries, err := os.ReadDir(c.dir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取集合目录失败: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			ids = append(ids, strings.TrimSuffix(entry.Name(), ".json"))
+		}
+	}
+	sort.Strings(ids)
+
+	values := make([]*T, 0, len(ids))
+	for _, id := range ids {
+		value, err := c.read(id)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// dir 返回集合目录
+func (c *Collection[T]) dir() string {
+	return filepath.Join(DataDir(), c.name)
+}
+
+// path 返回记录文件路径，并校验 ID 不包含路径分隔符
+func (c *Collection[T]) path(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+	return filepath.Join(c.dir(), id+".json"), nil
+}
+
+// read 读取并解析记录文件
+func (c *Collection[T]) read(id string) (*T, error) {
+	path, err := c.path(id)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("读取记录失败: %w", err)
+	}
+
+	value := new(T)
+	if err := json.Unmarshal(content, value); err != nil {
+		return nil, fmt.Errorf("解析记录失败: %s, %w", path, err)
+	}
+	return value, nil
+}
+
+// write 先写入临时文件再重命名，避免写入中断导致记录损坏
+func (c *Collection[T]) write(id string, value *T) error {
+	path, err := c.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir(), 0o755); err != nil {
+		return fmt.Errorf("创建集合目录失败: %w", err)
+	}
+
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化记录失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir(), id+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入记录失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入记录失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("保存记录失败: %w", err)
+	}
+	return nil
+}